@@ -0,0 +1,162 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultLatencyPercentile is the default percentile of the
+	// observed latencies the adaptive round timeout is based on
+	defaultLatencyPercentile = 95
+
+	// defaultLatencyWindow is the default number of recent
+	// sequences the adaptive round timeout is based on
+	defaultLatencyWindow = 100
+)
+
+// AdaptiveRoundTimeoutConfig is the configuration
+// of the adaptive round timeout policy
+type AdaptiveRoundTimeoutConfig struct {
+	// InitialTimeout is the round 0 timeout used
+	// until the first latency is observed
+	InitialTimeout time.Duration
+
+	// MinTimeout is the lower bound for the round 0 timeout
+	MinTimeout time.Duration
+
+	// MaxTimeout is the upper bound for the round 0 timeout.
+	// If 0, the round 0 timeout is unbounded
+	MaxTimeout time.Duration
+
+	// Percentile is the percentile (0, 100] of the
+	// observed phase latencies the round 0 timeout is based on
+	Percentile float64
+
+	// Margin is the safety margin added to the latency percentile
+	Margin time.Duration
+
+	// WindowSize is the number of recent sequences
+	// whose latencies are taken into account
+	WindowSize int
+}
+
+// AdaptiveRoundTimeout is the round timeout policy that learns
+// from the latencies of recent successful sequences. The round 0 timeout
+// is the configured percentile of the observed times to reach the PREPARE
+// quorum, plus the same percentile of the observed times from there to reach
+// the COMMIT quorum, plus a margin. It is bounded by the configured min and max
+// timeouts. The timeout of each following round is double the timeout
+// of the previous round.
+//
+// The phases are measured separately, so a slow PREPARE phase in
+// one sequence and a slow COMMIT phase in another both count
+type AdaptiveRoundTimeout struct {
+	sync.RWMutex
+
+	config AdaptiveRoundTimeoutConfig
+
+	// latencies is the ring buffer of the observed sequence latencies
+	latencies []SequenceLatency
+
+	// next is the position of the next observation in the ring buffer
+	next int
+
+	// baseTimeout is the current round 0 timeout
+	baseTimeout time.Duration
+}
+
+// NewAdaptiveRoundTimeout creates a new adaptive round timeout policy
+func NewAdaptiveRoundTimeout(config AdaptiveRoundTimeoutConfig) *AdaptiveRoundTimeout {
+	if config.InitialTimeout <= 0 {
+		config.InitialTimeout = round0Timeout
+	}
+
+	if config.Percentile <= 0 || config.Percentile > 100 {
+		config.Percentile = defaultLatencyPercentile
+	}
+
+	if config.WindowSize <= 0 {
+		config.WindowSize = defaultLatencyWindow
+	}
+
+	p := &AdaptiveRoundTimeout{
+		config:    config,
+		latencies: make([]SequenceLatency, 0, config.WindowSize),
+	}
+
+	p.baseTimeout = p.bound(config.InitialTimeout)
+
+	return p
+}
+
+// RoundTimeout returns the timeout for the specified round
+func (p *AdaptiveRoundTimeout) RoundTimeout(round uint64) time.Duration {
+	p.RLock()
+	defer p.RUnlock()
+
+	return NewExponentialRoundTimeout(p.baseTimeout, 0).RoundTimeout(round)
+}
+
+// ObserveLatency records the latency of a successful sequence,
+// and recalculates the round 0 timeout
+func (p *AdaptiveRoundTimeout) ObserveLatency(latency SequenceLatency) {
+	p.Lock()
+	defer p.Unlock()
+
+	if len(p.latencies) < p.config.WindowSize {
+		p.latencies = append(p.latencies, latency)
+	} else {
+		p.latencies[p.next] = latency
+	}
+
+	p.next = (p.next + 1) % p.config.WindowSize
+
+	var (
+		preparePhase = p.percentile(func(latency SequenceLatency) time.Duration {
+			return latency.Prepare
+		})
+		commitPhase = p.percentile(func(latency SequenceLatency) time.Duration {
+			return latency.Commit - latency.Prepare
+		})
+	)
+
+	p.baseTimeout = p.bound(
+		addTimeouts(addTimeouts(preparePhase, commitPhase), p.config.Margin),
+	)
+}
+
+// percentile returns the configured percentile of the
+// phase durations of the observed latencies (nearest-rank)
+func (p *AdaptiveRoundTimeout) percentile(phase func(SequenceLatency) time.Duration) time.Duration {
+	sorted := make([]time.Duration, len(p.latencies))
+	for index, latency := range p.latencies {
+		sorted[index] = phase(latency)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	rank := int(math.Ceil(p.config.Percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// bound makes sure the timeout is within the configured bounds
+func (p *AdaptiveRoundTimeout) bound(timeout time.Duration) time.Duration {
+	if timeout < p.config.MinTimeout {
+		return p.config.MinTimeout
+	}
+
+	if p.config.MaxTimeout > 0 && timeout > p.config.MaxTimeout {
+		return p.config.MaxTimeout
+	}
+
+	return timeout
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// observeCommitLatencies feeds the commit latencies to the policy,
+// with the PREPARE quorum reached halfway through
+func observeCommitLatencies(policy *AdaptiveRoundTimeout, latencies ...time.Duration) {
+	for _, latency := range latencies {
+		policy.ObserveLatency(SequenceLatency{
+			Prepare: latency / 2,
+			Commit:  latency,
+		})
+	}
+}
+
+func TestAdaptiveRoundTimeout_RoundTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("initial timeout is used before any observation", func(t *testing.T) {
+		t.Parallel()
+
+		policy := NewAdaptiveRoundTimeout(AdaptiveRoundTimeoutConfig{
+			InitialTimeout: 20 * time.Second,
+		})
+
+		assert.Equal(t, 20*time.Second, policy.RoundTimeout(0))
+		assert.Equal(t, 40*time.Second, policy.RoundTimeout(1))
+	})
+
+	t.Run("default initial timeout", func(t *testing.T) {
+		t.Parallel()
+
+		policy := NewAdaptiveRoundTimeout(AdaptiveRoundTimeoutConfig{})
+
+		assert.Equal(t, round0Timeout, policy.RoundTimeout(0))
+	})
+
+	t.Run("timeout is the latency percentile plus the margin", func(t *testing.T) {
+		t.Parallel()
+
+		policy := NewAdaptiveRoundTimeout(AdaptiveRoundTimeoutConfig{
+			InitialTimeout: time.Minute,
+			Percentile:     90,
+			Margin:         500 * time.Millisecond,
+		})
+
+		// Observe latencies of 1s - 10s
+		for latency := 10; latency >= 1; latency-- {
+			observeCommitLatencies(policy, time.Duration(latency)*time.Second)
+		}
+
+		assert.Equal(t, 9500*time.Millisecond, policy.RoundTimeout(0))
+		assert.Equal(t, 19*time.Second, policy.RoundTimeout(1))
+	})
+
+	t.Run("timeout is bounded", func(t *testing.T) {
+		t.Parallel()
+
+		policy := NewAdaptiveRoundTimeout(AdaptiveRoundTimeoutConfig{
+			MinTimeout: 2 * time.Second,
+			MaxTimeout: 5 * time.Second,
+		})
+
+		// Make sure the initial timeout is bounded
+		assert.Equal(t, 5*time.Second, policy.RoundTimeout(0))
+
+		// Make sure the lower bound is respected
+		observeCommitLatencies(policy, 100*time.Millisecond)
+		assert.Equal(t, 2*time.Second, policy.RoundTimeout(0))
+
+		// Make sure the upper bound is respected
+		observeCommitLatencies(policy, time.Minute, time.Minute)
+		assert.Equal(t, 5*time.Second, policy.RoundTimeout(0))
+
+		// Make sure only the round 0 timeout is bounded
+		assert.Equal(t, 20*time.Second, policy.RoundTimeout(2))
+	})
+
+	t.Run("prepare and commit phases both drive the timeout", func(t *testing.T) {
+		t.Parallel()
+
+		policy := NewAdaptiveRoundTimeout(AdaptiveRoundTimeoutConfig{
+			Percentile: 100,
+		})
+
+		// A sequence with a slow PREPARE phase (4s), and a fast COMMIT phase (1s)
+		policy.ObserveLatency(SequenceLatency{
+			Prepare: 4 * time.Second,
+			Commit:  5 * time.Second,
+		})
+		assert.Equal(t, 5*time.Second, policy.RoundTimeout(0))
+
+		// A sequence with a fast PREPARE phase (1s), and a slow COMMIT phase (3s).
+		// Make sure the slowest of each phase are added up,
+		// rather than taking the slowest sequence as a whole
+		policy.ObserveLatency(SequenceLatency{
+			Prepare: time.Second,
+			Commit:  4 * time.Second,
+		})
+		assert.Equal(t, 7*time.Second, policy.RoundTimeout(0))
+	})
+
+	t.Run("old latencies are forgotten", func(t *testing.T) {
+		t.Parallel()
+
+		policy := NewAdaptiveRoundTimeout(AdaptiveRoundTimeoutConfig{
+			Percentile: 100,
+			WindowSize: 3,
+		})
+
+		observeCommitLatencies(policy, 8*time.Second, 8*time.Second, 8*time.Second)
+		assert.Equal(t, 8*time.Second, policy.RoundTimeout(0))
+
+		observeCommitLatencies(policy, time.Second, 2*time.Second)
+		assert.Equal(t, 8*time.Second, policy.RoundTimeout(0))
+
+		observeCommitLatencies(policy, time.Second)
+		assert.Equal(t, 2*time.Second, policy.RoundTimeout(0))
+	})
+}
+
+// mockLatencyPolicy is the round timeout policy
+// that records the observed latencies
+type mockLatencyPolicy struct {
+	ConstantRoundTimeout
+
+	observed []SequenceLatency
+}
+
+func (p *mockLatencyPolicy) ObserveLatency(latency SequenceLatency) {
+	p.observed = append(p.observed, latency)
+}
+
+// TestIBFT_ObserveLatency makes sure the latency of the finalizing
+// round is reported to the round timeout policy
+func TestIBFT_ObserveLatency(t *testing.T) {
+	t.Parallel()
+
+	t.Run("latency is observed", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			roundStart = time.Now()
+			policy     = &mockLatencyPolicy{}
+		)
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{}, WithRoundTimeoutPolicy(policy))
+
+		i.state.setRoundStart(roundStart)
+		i.state.setPrepareLatency(roundStart.Add(time.Second))
+		i.state.setCommitLatency(roundStart.Add(3 * time.Second))
+
+		i.observeLatency()
+
+		assert.Equal(
+			t,
+			[]SequenceLatency{
+				{
+					Prepare: time.Second,
+					Commit:  3 * time.Second,
+				},
+			},
+			policy.observed,
+		)
+	})
+
+	t.Run("incomplete latency is not observed", func(t *testing.T) {
+		t.Parallel()
+
+		policy := &mockLatencyPolicy{}
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{}, WithRoundTimeoutPolicy(policy))
+
+		i.state.setRoundStart(time.Now())
+		i.state.setCommitLatency(time.Now().Add(time.Second))
+
+		i.observeLatency()
+
+		assert.Empty(t, policy.observed)
+	})
+}
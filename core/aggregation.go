@@ -0,0 +1,43 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/nubank/go-ibft/messages"
+)
+
+// ErrAggregateSeals is returned by RunSequence when consensus was reached,
+// but the committed seals could not be aggregated. The finalized proposal
+// is not handed to the backend for insertion
+var ErrAggregateSeals = errors.New("unable to aggregate committed seals")
+
+// SealAggregatingBackend is the optional Backend extension for aggregated
+// committed seals (BLS). If implemented along with ValidatorSetProvider,
+// the committed seals of the finalized proposal are aggregated into a single
+// signature, and the proposal is inserted with InsertAggregatedBlock
+// instead of InsertBlock
+type SealAggregatingBackend interface {
+	messages.SealAggregator
+
+	// InsertAggregatedBlock inserts a proposal with the aggregated committed seal
+	InsertAggregatedBlock(proposal []byte, seal *messages.AggregatedSeal) error
+}
+
+// aggregateSeals aggregates the committed seals for the specified height,
+// if the backend supports seal aggregation
+func (i *IBFT) aggregateSeals(
+	height uint64,
+	seals []*messages.CommittedSeal,
+) (*messages.AggregatedSeal, error) {
+	aggregator, ok := i.backend.(SealAggregatingBackend)
+	if !ok {
+		return nil, nil
+	}
+
+	set, ok := i.validatorSet(height)
+	if !ok {
+		return nil, nil
+	}
+
+	return messages.AggregateSeals(aggregator, set, seals)
+}
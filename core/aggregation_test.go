@@ -0,0 +1,164 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockAggregatingBackend is the mock backend that implements
+// the SealAggregatingBackend extension. Signatures are
+// aggregated by concatenating them
+type mockAggregatingBackend struct {
+	mockValidatorSetBackend
+
+	insertAggregatedBlockFn func([]byte, *messages.AggregatedSeal) error
+}
+
+func (m mockAggregatingBackend) AggregateSignatures(signatures [][]byte) ([]byte, error) {
+	return bytes.Join(signatures, nil), nil
+}
+
+func (m mockAggregatingBackend) VerifyAggregatedSignature(_ [][]byte, _, _ []byte) bool {
+	return true
+}
+
+func (m mockAggregatingBackend) InsertAggregatedBlock(
+	proposal []byte,
+	seal *messages.AggregatedSeal,
+) error {
+	return m.insertAggregatedBlockFn(proposal, seal)
+}
+
+func TestIBFT_AggregatedSeals(t *testing.T) {
+	t.Parallel()
+
+	var (
+		height   = uint64(5)
+		nodes    = generateNodeAddresses(4)
+		proposal = []byte("proposal")
+		seals    = []*messages.CommittedSeal{
+			{Signer: nodes[2], Signature: []byte("2")},
+			{Signer: nodes[0], Signature: []byte("0")},
+		}
+	)
+
+	t.Run("seals are aggregated", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			inserted *messages.AggregatedSeal
+
+			backend = mockAggregatingBackend{
+				mockValidatorSetBackend: newWeightedBackend(nodes),
+				insertAggregatedBlockFn: func(_ []byte, seal *messages.AggregatedSeal) error {
+					inserted = seal
+
+					return nil
+				},
+			}
+		)
+
+		backend.insertBlockFn = func(_ []byte, _ []*messages.CommittedSeal) {
+			t.Fatal("block inserted without the aggregated seal")
+		}
+
+		i := NewIBFT(mockLogger{}, backend, mockTransport{})
+
+		finalizer := newMockFinalizer(i, proposal, seals)
+		go finalizer.finalize(&proto.View{Height: height})
+
+		result, err := i.RunSequence(context.Background(), height)
+		assert.NoError(t, err)
+
+		expected := &messages.AggregatedSeal{
+			Signers:   messages.ValidatorBitmap{0x05},
+			Signature: []byte("02"),
+		}
+
+		assert.Equal(t, expected, inserted)
+
+		if assert.NotNil(t, result) {
+			assert.Equal(t, expected, result.AggregatedSeal)
+			assert.Equal(t, seals, result.CommittedSeals)
+
+			assert.Equal(
+				t,
+				[]byte(expected.Signers),
+				result.Certificate.GetAggregatedSeal().GetSigners(),
+			)
+			assert.Equal(t, expected.Signature, result.Certificate.GetAggregatedSeal().GetSignature())
+		}
+	})
+
+	t.Run("seals cannot be aggregated", func(t *testing.T) {
+		t.Parallel()
+
+		backend := mockAggregatingBackend{
+			mockValidatorSetBackend: newWeightedBackend(nodes),
+			insertAggregatedBlockFn: func(_ []byte, _ *messages.AggregatedSeal) error {
+				t.Fatal("block inserted with invalid seals")
+
+				return nil
+			},
+		}
+
+		i := NewIBFT(mockLogger{}, backend, mockTransport{})
+
+		finalizer := newMockFinalizer(i, proposal, []*messages.CommittedSeal{
+			{Signer: []byte("unknown"), Signature: []byte("signature")},
+		})
+		go finalizer.finalize(&proto.View{Height: height})
+
+		result, err := i.RunSequence(context.Background(), height)
+
+		// Make sure the block is not reported as failed to insert,
+		// as it was never handed to the backend
+		assert.ErrorIs(t, err, ErrAggregateSeals)
+		assert.NotErrorIs(t, err, ErrInsertBlock)
+		assert.ErrorIs(t, err, messages.ErrUnknownSealSigner)
+		assert.NotNil(t, result)
+	})
+
+	t.Run("no validator set", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			inserted []*messages.CommittedSeal
+
+			backend = mockAggregatingBackend{
+				mockValidatorSetBackend: newWeightedBackend(nodes),
+				insertAggregatedBlockFn: func(_ []byte, _ *messages.AggregatedSeal) error {
+					t.Fatal("block inserted with the aggregated seal")
+
+					return nil
+				},
+			}
+		)
+
+		backend.validatorSetFn = func(_ uint64) messages.ValidatorSet {
+			return nil
+		}
+		backend.insertBlockFn = func(_ []byte, committedSeals []*messages.CommittedSeal) {
+			inserted = committedSeals
+		}
+
+		i := NewIBFT(mockLogger{}, backend, mockTransport{})
+
+		finalizer := newMockFinalizer(i, proposal, seals)
+		go finalizer.finalize(&proto.View{Height: height})
+
+		result, err := i.RunSequence(context.Background(), height)
+		assert.NoError(t, err)
+
+		assert.Equal(t, seals, inserted)
+
+		if assert.NotNil(t, result) {
+			assert.Nil(t, result.AggregatedSeal)
+		}
+	})
+}
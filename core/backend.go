@@ -62,10 +62,19 @@ type Backend interface {
 	ID() []byte
 
 	// MaximumFaultyNodes returns the maximum number of faulty nodes based
-	// on the validator set.
+	// on the validator set. Not used if the backend is a ValidatorSetProvider.
 	MaximumFaultyNodes() uint64
 
 	// Quorum returns what is the quorum size for the
-	// specified block height.
+	// specified block height. Not used if the backend is a ValidatorSetProvider.
 	Quorum(blockHeight uint64) uint64
 }
+
+// CheckedInsertBackend is the optional Backend extension for block insertion
+// that can fail. If implemented, InsertBlockWithError is used instead of
+// InsertBlock, and the insertion error is returned by RunSequence
+type CheckedInsertBackend interface {
+	// InsertBlockWithError inserts a proposal with the specified
+	// committed seals, and returns the insertion error, if any
+	InsertBlockWithError(proposal []byte, committedSeals []*messages.CommittedSeal) error
+}
@@ -0,0 +1,63 @@
+package core
+
+import "time"
+
+// Clock defines the source of time the IBFT instance uses
+// for its timers and measurements
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// NewTimer creates a new timer that fires
+	// after the specified duration
+	NewTimer(d time.Duration) Timer
+
+	// After waits for the specified duration to elapse,
+	// and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+// Timer defines a single event timer
+type Timer interface {
+	// C returns the channel on which the time is sent
+	// when the timer fires
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It returns false
+	// if the timer has already fired or has been stopped
+	Stop() bool
+}
+
+// RealClock returns the Clock based on the system time,
+// which is the default Clock of the IBFT instance
+func RealClock() Clock {
+	return realClock{}
+}
+
+// realClock is the Clock based on the system time
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// realTimer is the Timer based on the system time
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
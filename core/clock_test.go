@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIBFT_RoundTimerClock makes sure the round timer
+// is driven by the configured clock
+func TestIBFT_RoundTimerClock(t *testing.T) {
+	t.Parallel()
+
+	var (
+		log       = mockLogger{}
+		transport = mockTransport{}
+		backend   = mockBackend{}
+		clock     = newMockClock()
+	)
+
+	i := NewIBFT(
+		log,
+		backend,
+		transport,
+		WithClock(clock),
+		WithRoundTimeoutPolicy(NewConstantRoundTimeout(10*time.Second)),
+	)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	i.wg.Add(1)
+
+	go i.startRoundTimer(ctx, 0)
+
+	clock.waitForTimers(1)
+
+	// Make sure the timer doesn't expire before the timeout
+	clock.Advance(9 * time.Second)
+
+	select {
+	case <-i.roundExpired:
+		t.Fatal("round timer expired early")
+	default:
+	}
+
+	// Make sure the timer expires once the timeout passes
+	clock.Advance(time.Second)
+
+	select {
+	case <-i.roundExpired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("round timer did not expire")
+	}
+
+	i.wg.Wait()
+	assert.Zero(t, clock.pendingTimers())
+}
+
+// TestIBFT_RoundTimerClockStop makes sure the round timer
+// is stopped when the round is done
+func TestIBFT_RoundTimerClockStop(t *testing.T) {
+	t.Parallel()
+
+	var (
+		log       = mockLogger{}
+		transport = mockTransport{}
+		backend   = mockBackend{}
+		clock     = newMockClock()
+	)
+
+	i := NewIBFT(log, backend, transport, WithClock(clock))
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	i.wg.Add(1)
+
+	go i.startRoundTimer(ctx, 0)
+
+	clock.waitForTimers(1)
+	cancelFn()
+	i.wg.Wait()
+
+	assert.Zero(t, clock.pendingTimers())
+}
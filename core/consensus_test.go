@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"math"
 	"testing"
-	"time"
 
 	"github.com/nubank/go-ibft/messages"
 	"github.com/nubank/go-ibft/messages/proto"
@@ -421,8 +420,9 @@ func TestConsensus_InvalidBlock(t *testing.T) {
 		transportCallbackMap,
 	)
 
-	// Set the base timeout to be lower than usual
-	cluster.setBaseTimeout(2 * time.Second)
+	// Drive the round timers using virtual time
+	clock := newMockClock()
+	cluster.setClock(clock)
 
 	// Set the multicast callback to relay the message
 	// to the entire cluster
@@ -433,6 +433,11 @@ func TestConsensus_InvalidBlock(t *testing.T) {
 	// Start the main run loops
 	cluster.runSequence(1)
 
+	// Expire the round 0 timers once all
+	// the nodes have started them
+	clock.waitForTimers(numNodes)
+	clock.Advance(round0Timeout)
+
 	// Wait until the main run loops finish
 	cluster.stop()
 
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDriverRunning is returned by Run when the IBFT
+// instance is already being driven by another Run call
+var ErrDriverRunning = errors.New("consensus driver is already running")
+
+// HeightSource provides the chain height the consensus
+// driver should build on
+type HeightSource interface {
+	// LatestHeight returns the height of the latest inserted block
+	LatestHeight() uint64
+
+	// HeightUpdates returns the channel on which the latest height
+	// is sent whenever the chain is advanced outside of consensus
+	// (ex. after syncing). Can be nil if the height is never
+	// advanced externally
+	HeightUpdates() <-chan uint64
+}
+
+// driver is the state of a single Run call
+type driver struct {
+	drainCh   chan struct{}
+	drainOnce sync.Once
+	doneCh    chan struct{}
+
+	// proposalTime is the earliest time the proposal
+	// of the in-flight sequence can be built
+	proposalTime time.Time
+}
+
+// drain signals the driver to stop once
+// the in-flight sequence is done
+func (d *driver) drain() {
+	d.drainOnce.Do(func() {
+		close(d.drainCh)
+	})
+}
+
+// sequenceOutcome is the result of a sequence run by the driver
+type sequenceOutcome struct {
+	result *SequenceResult
+	err    error
+}
+
+// Run continuously runs consensus sequences, one height after the other.
+// Each sequence is run for the height following the latest one, as reported
+// by the height source or finalized by the previous sequence. The proposer builds
+// its proposal at least the minimum block interval after the previous sequence
+// was finalized, while the other nodes start the sequence right away, so they
+// don't miss the messages of a proposer that is ahead of them. If the height source
+// reports that the chain advanced past the in-flight sequence, the sequence is
+// aborted and the driver moves on to the new height. If the finalized block can't
+// be aggregated or inserted, the same height is retried after the round 0 timeout.
+// Run returns nil once drained, the context error if the context is cancelled,
+// or the error wrapping ErrPersistState if the state store fails to persist the state
+func (i *IBFT) Run(ctx context.Context, source HeightSource) error {
+	d, err := i.startDriver()
+	if err != nil {
+		return err
+	}
+
+	defer i.stopDriver(d)
+
+	var (
+		// nextHeight is the lowest height the next sequence can be run for
+		nextHeight uint64
+
+		// lastFinalized is the time the latest sequence
+		// run by the driver was finalized
+		lastFinalized time.Time
+	)
+
+	for {
+		if !lastFinalized.IsZero() {
+			d.proposalTime = lastFinalized.Add(i.minBlockInterval)
+		}
+
+		height := source.LatestHeight() + 1
+		if height < nextHeight {
+			height = nextHeight
+		}
+
+		outcome := i.runDriverSequence(ctx, source, height)
+
+		switch {
+		case outcome.err == nil:
+			nextHeight = outcome.result.Height + 1
+			lastFinalized = i.clock.Now()
+		case errors.Is(outcome.err, ErrInsertBlock), errors.Is(outcome.err, ErrAggregateSeals):
+			// The sequence is going to be retried
+			// with the state restored from the store, if any
+			lastFinalized = i.clock.Now()
+
+			i.log.Error("sequence not finalized", "height", height, "err", outcome.err)
+
+			// Back off before the retry, so a persistent
+			// failure doesn't spin on the same height
+			select {
+			case <-i.clock.After(i.roundTimeout.RoundTimeout(0)):
+			case <-d.drainCh:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(outcome.err, ErrPersistState):
+			// The node can't commit to its votes, so it must not vote
+			return outcome.err
+		default:
+			// The sequence was aborted because of a height jump
+			i.log.Info("sequence aborted", "height", height)
+		}
+
+		select {
+		case <-d.drainCh:
+			return nil
+		default:
+		}
+	}
+}
+
+// Drain signals the running driver to stop once the in-flight
+// sequence is done, and waits for it to return. If the context
+// is cancelled before that, the context error is returned
+func (i *IBFT) Drain(ctx context.Context) error {
+	i.driverLock.Lock()
+	d := i.driver
+	i.driverLock.Unlock()
+
+	if d == nil {
+		return nil
+	}
+
+	d.drain()
+
+	select {
+	case <-d.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startDriver registers a new driver for the IBFT instance
+func (i *IBFT) startDriver() (*driver, error) {
+	i.driverLock.Lock()
+	defer i.driverLock.Unlock()
+
+	if i.driver != nil {
+		return nil, ErrDriverRunning
+	}
+
+	i.driver = &driver{
+		drainCh: make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+
+	return i.driver, nil
+}
+
+// stopDriver unregisters the driver, and notifies
+// any pending Drain calls
+func (i *IBFT) stopDriver(d *driver) {
+	i.driverLock.Lock()
+	defer i.driverLock.Unlock()
+
+	i.driver = nil
+
+	close(d.doneCh)
+}
+
+// waitBlockInterval waits until the minimum block interval since the
+// latest sequence finalized by the driver has passed. It is called by the
+// proposer only, before the proposal is built. It returns false
+// if the context is cancelled in the meantime
+func (i *IBFT) waitBlockInterval(ctx context.Context) bool {
+	i.driverLock.Lock()
+	d := i.driver
+	i.driverLock.Unlock()
+
+	if d == nil {
+		// The sequence is not run by the driver
+		return true
+	}
+
+	remaining := d.proposalTime.Sub(i.clock.Now())
+	if remaining <= 0 {
+		return true
+	}
+
+	select {
+	case <-i.clock.After(remaining):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// runDriverSequence runs the sequence for the specified height,
+// and aborts it if the height source reports that the chain
+// advanced to (or past) that height in the meantime
+func (i *IBFT) runDriverSequence(
+	ctx context.Context,
+	source HeightSource,
+	height uint64,
+) sequenceOutcome {
+	sequenceCtx, cancelSequence := context.WithCancel(ctx)
+	defer cancelSequence()
+
+	outcomeCh := make(chan sequenceOutcome, 1)
+
+	go func() {
+		result, err := i.RunSequence(sequenceCtx, height)
+
+		outcomeCh <- sequenceOutcome{
+			result: result,
+			err:    err,
+		}
+	}()
+
+	updates := source.HeightUpdates()
+
+	for {
+		select {
+		case outcome := <-outcomeCh:
+			return outcome
+		case latest, ok := <-updates:
+			if !ok {
+				// The height source no longer reports updates
+				updates = nil
+
+				continue
+			}
+
+			if latest < height {
+				continue
+			}
+
+			i.log.Info("chain advanced externally", "height", height, "latest", latest)
+			cancelSequence()
+
+			return <-outcomeCh
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockHeightSource is the height source
+// with a manually set latest height
+type mockHeightSource struct {
+	latest  uint64
+	updates chan uint64
+}
+
+func (s *mockHeightSource) LatestHeight() uint64 {
+	return atomic.LoadUint64(&s.latest)
+}
+
+func (s *mockHeightSource) HeightUpdates() <-chan uint64 {
+	return s.updates
+}
+
+// advance imitates the chain being advanced
+// outside of consensus (ex. by syncing)
+func (s *mockHeightSource) advance(height uint64) {
+	atomic.StoreUint64(&s.latest, height)
+	s.updates <- height
+}
+
+// newDriverNode creates a new IBFT instance that notifies
+// the started sequence heights on the returned channel.
+// The sequences are finalized only through the returned finalizer
+func newDriverNode(backend mockBackend, opts ...Option) (*IBFT, *mockFinalizer, chan uint64) {
+	started := notifyStarted(&backend)
+
+	i := NewIBFT(mockLogger{}, backend, mockTransport{}, opts...)
+
+	return i, newMockFinalizer(i, nil, nil), started
+}
+
+// notifyStarted makes the backend report the heights of the started
+// sequences, on the returned channel
+func notifyStarted(backend *mockBackend) chan uint64 {
+	started := make(chan uint64, 10)
+
+	backend.isProposerFn = func(_ []byte, height uint64, _ uint64) bool {
+		started <- height
+
+		return false
+	}
+
+	return started
+}
+
+// runDriver runs the driver, and returns
+// the channel the driver error is sent on
+func runDriver(ctx context.Context, i *IBFT, source HeightSource) chan error {
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- i.Run(ctx, source)
+	}()
+
+	return errCh
+}
+
+// drainDriver drains the in-flight sequence for the height,
+// and makes sure the driver is stopped
+func drainDriver(t *testing.T, finalizer *mockFinalizer, height uint64, errCh chan error) {
+	t.Helper()
+
+	i := finalizer.node
+
+	i.driverLock.Lock()
+	d := i.driver
+	i.driverLock.Unlock()
+
+	drainErr := make(chan error, 1)
+
+	go func() {
+		drainErr <- i.Drain(context.Background())
+	}()
+
+	// Wait for the drain request to be registered
+	<-d.drainCh
+
+	finalizer.finalize(&proto.View{Height: height})
+
+	assert.NoError(t, <-drainErr)
+	assert.NoError(t, <-errCh)
+}
+
+func TestIBFT_Run(t *testing.T) {
+	t.Parallel()
+
+	t.Run("heights are advanced", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			inserted uint64
+			source   = &mockHeightSource{latest: 4}
+		)
+
+		i, finalizer, started := newDriverNode(mockBackend{
+			insertBlockFn: func(_ []byte, _ []*messages.CommittedSeal) {
+				atomic.AddUint64(&inserted, 1)
+			},
+		})
+
+		errCh := runDriver(context.Background(), i, source)
+
+		// Make sure the sequences are run one height after the other
+		assert.Equal(t, uint64(5), <-started)
+		finalizer.finalize(&proto.View{Height: 5})
+
+		assert.Equal(t, uint64(6), <-started)
+		finalizer.finalize(&proto.View{Height: 6})
+
+		assert.Equal(t, uint64(7), <-started)
+		drainDriver(t, finalizer, 7, errCh)
+
+		// Make sure the in-flight sequence was finalized before stopping
+		assert.Equal(t, uint64(3), atomic.LoadUint64(&inserted))
+	})
+
+	t.Run("minimum block interval is respected", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			clock   = newMockClock()
+			source  = &mockHeightSource{}
+			started = make(chan uint64, 10)
+			built   = make(chan uint64, 10)
+		)
+
+		i := NewIBFT(mockLogger{}, mockBackend{
+			isProposerFn: func(_ []byte, height uint64, _ uint64) bool {
+				started <- height
+
+				// The node proposes the second height only
+				return height == 2
+			},
+			buildProposalFn: func(height uint64) []byte {
+				built <- height
+
+				return []byte("proposal")
+			},
+		}, mockTransport{}, WithClock(clock), WithMinBlockInterval(5*time.Second))
+
+		finalizer := newMockFinalizer(i, nil, nil)
+		errCh := runDriver(context.Background(), i, source)
+
+		assert.Equal(t, uint64(1), <-started)
+		finalizer.finalize(&proto.View{Height: 1})
+
+		// Make sure the next sequence is started right away,
+		// but the proposal is not built before the interval passes
+		assert.Equal(t, uint64(2), <-started)
+
+		clock.waitForTimer(5 * time.Second)
+		clock.Advance(4 * time.Second)
+
+		select {
+		case height := <-built:
+			t.Fatalf("proposal %d built early", height)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		clock.Advance(time.Second)
+
+		assert.Equal(t, uint64(2), <-built)
+		finalizer.finalize(&proto.View{Height: 2})
+
+		// Make sure the other nodes don't wait for the interval
+		assert.Equal(t, uint64(3), <-started)
+		drainDriver(t, finalizer, 3, errCh)
+	})
+
+	t.Run("height jump aborts the sequence", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			inserted uint64
+			source   = &mockHeightSource{
+				latest:  4,
+				updates: make(chan uint64),
+			}
+		)
+
+		i, finalizer, started := newDriverNode(mockBackend{
+			insertBlockFn: func(_ []byte, _ []*messages.CommittedSeal) {
+				atomic.AddUint64(&inserted, 1)
+			},
+		})
+
+		errCh := runDriver(context.Background(), i, source)
+
+		assert.Equal(t, uint64(5), <-started)
+
+		// Make sure a height update below the sequence is ignored
+		source.updates <- 3
+
+		// Make sure the driver moves on to the height after the jump
+		source.advance(7)
+
+		assert.Equal(t, uint64(8), <-started)
+		drainDriver(t, finalizer, 8, errCh)
+
+		// Make sure the aborted sequence was not inserted
+		assert.Equal(t, uint64(1), atomic.LoadUint64(&inserted))
+	})
+
+	t.Run("failed insertion is retried", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			attempts uint64
+			clock    = newMockClock()
+			source   = &mockHeightSource{}
+			backend  = mockBackend{}
+			started  = notifyStarted(&backend)
+			failed   = make(chan struct{}, 1)
+		)
+
+		i := NewIBFT(mockLogger{}, mockCheckedInsertBackend{
+			mockBackend: backend,
+			insertBlockWithErrorFn: func(_ []byte, _ []*messages.CommittedSeal) error {
+				if atomic.AddUint64(&attempts, 1) == 1 {
+					failed <- struct{}{}
+
+					return errors.New("invalid state root")
+				}
+
+				return nil
+			},
+		}, mockTransport{}, WithClock(clock))
+
+		finalizer := newMockFinalizer(i, nil, nil)
+		errCh := runDriver(context.Background(), i, source)
+
+		assert.Equal(t, uint64(1), <-started)
+		finalizer.finalize(&proto.View{Height: 1})
+
+		// Make sure the same height is not run again
+		// before the round 0 timeout passes
+		<-failed
+		clock.waitForTimer(round0Timeout)
+		clock.Advance(round0Timeout - time.Second)
+
+		select {
+		case height := <-started:
+			t.Fatalf("sequence %d retried early", height)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		clock.Advance(time.Second)
+
+		assert.Equal(t, uint64(1), <-started)
+		finalizer.finalize(&proto.View{Height: 1})
+
+		assert.Equal(t, uint64(2), <-started)
+		drainDriver(t, finalizer, 2, errCh)
+	})
+
+	t.Run("context is cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+		i, _, started := newDriverNode(mockBackend{})
+
+		errCh := runDriver(ctx, i, &mockHeightSource{})
+
+		<-started
+		cancelFn()
+
+		assert.ErrorIs(t, <-errCh, context.Canceled)
+	})
+
+	t.Run("driver is already running", func(t *testing.T) {
+		t.Parallel()
+
+		i, finalizer, started := newDriverNode(mockBackend{})
+
+		errCh := runDriver(context.Background(), i, &mockHeightSource{})
+
+		<-started
+
+		assert.ErrorIs(t, i.Run(context.Background(), &mockHeightSource{}), ErrDriverRunning)
+
+		drainDriver(t, finalizer, 1, errCh)
+	})
+
+	t.Run("drain without a driver", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+		assert.NoError(t, i.Drain(context.Background()))
+	})
+}
@@ -0,0 +1,23 @@
+package core
+
+import "fmt"
+
+// sentinelError is the error that matches the sentinel error
+// of the failed step (ex. ErrPersistState), while keeping
+// the underlying cause reachable through errors.Unwrap
+type sentinelError struct {
+	sentinel error
+	err      error
+}
+
+func (e *sentinelError) Error() string {
+	return fmt.Sprintf("%s: %s", e.sentinel.Error(), e.err.Error())
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.err
+}
+
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
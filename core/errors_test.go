@@ -0,0 +1,20 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentinelError(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("disk full")
+	err := &sentinelError{sentinel: ErrPersistState, err: cause}
+
+	assert.ErrorIs(t, err, ErrPersistState)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, ErrInsertBlock)
+	assert.Equal(t, "unable to persist state: disk full", err.Error())
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"math"
 	"sync"
 	"time"
 
@@ -30,6 +29,8 @@ type Messages interface {
 		isValid func(*proto.Message) bool,
 	) []*proto.Message
 	GetMostRoundChangeMessages(minRound, height uint64) []*proto.Message
+	GetHighestRoundChangeMessages(minRound, height uint64) []*proto.Message
+	GetHeightMessages(height uint64) []*proto.Message
 
 	// Messages subscription handlers //
 	Subscribe(details messages.SubscriptionDetails) *messages.Subscription
@@ -39,6 +40,16 @@ type Messages interface {
 var (
 	errTimeoutExpired = errors.New("round timeout expired")
 
+	// Errors returned by AddMessage for the rejected messages
+	ErrMalformed        = errors.New("malformed message")
+	ErrInvalidSender    = errors.New("invalid message sender")
+	ErrInvalidSignature = errors.New("invalid message signature")
+	ErrNotValidator     = errors.New("message sender is not a validator")
+	ErrStaleHeight      = errors.New("message height is lower than the current height")
+	ErrStaleRound       = errors.New("message round is lower than the current round")
+	ErrFutureHeight     = errors.New("message height is too far ahead of the current height")
+	ErrFutureRound      = errors.New("message round is too far ahead of the current round")
+
 	round0Timeout = 10 * time.Second
 )
 
@@ -61,6 +72,10 @@ type IBFT struct {
 	// Transport implementation
 	transport Transport
 
+	// store is the persistence layer for the consensus state.
+	// If not set, the state is kept only in memory
+	store StateStore
+
 	// roundDone is the channel used for signalizing
 	// consensus finalization upon a certain sequence
 	roundDone chan struct{}
@@ -79,11 +94,61 @@ type IBFT struct {
 	// one is present
 	roundCertificate chan uint64
 
+	// futureRoundChange is the channel used for signalizing
+	// when F+1 valid ROUND_CHANGE messages for rounds greater
+	// than the current one are present
+	futureRoundChange chan uint64
+
+	// persistFailed is the channel used for signalizing
+	// when the state could not be persisted
+	persistFailed chan error
+
 	//	User configured additional timeout for each round of consensus
 	additionalTimeout time.Duration
 
-	// baseRoundTimeout is the base round timeout for each round of consensus
-	baseRoundTimeout time.Duration
+	// roundTimeout is the policy that determines
+	// the timeout for each round of consensus
+	roundTimeout RoundTimeoutPolicy
+
+	// clock is the source of time for timers and measurements
+	clock Clock
+
+	// minBlockInterval is the minimum time between
+	// consecutive sequences finalized by the driver (Run)
+	minBlockInterval time.Duration
+
+	// driver is the state of the running driver (Run), if any
+	driver     *driver
+	driverLock sync.Mutex
+
+	// signer signs the outgoing messages, if set
+	signer Signer
+
+	// signatureVerifier verifies the incoming
+	// message signatures, if set
+	signatureVerifier SignatureVerifier
+
+	// equivocationHandler is notified of the conflicting
+	// messages detected by the message store
+	equivocationHandler messages.EquivocationHandler
+
+	// messageLimits bounds the messages kept by the node
+	messageLimits MessageLimits
+
+	// metrics records the consensus metrics
+	metrics Metrics
+
+	// observer is notified of the consensus progress
+	observer Observer
+
+	// syncTarget is the highest height the sync was triggered for
+	syncTarget uint64
+
+	// futureTallies are the running tallies of the messages
+	// for the future heights, above tallyHeight
+	futureTallies map[uint64]*futureTally
+	tallyHeight   uint64
+	syncLock      sync.Mutex
 
 	// wg is a simple barrier used for synchronizing
 	// state modification routines
@@ -95,16 +160,19 @@ func NewIBFT(
 	log Logger,
 	backend Backend,
 	transport Transport,
+	opts ...Option,
 ) *IBFT {
-	return &IBFT{
-		log:              log,
-		backend:          backend,
-		transport:        transport,
-		messages:         messages.NewMessages(),
-		roundDone:        make(chan struct{}),
-		roundExpired:     make(chan struct{}),
-		newProposal:      make(chan newProposalEvent),
-		roundCertificate: make(chan uint64),
+	i := &IBFT{
+		log:               log,
+		backend:           backend,
+		transport:         transport,
+		roundDone:         make(chan struct{}),
+		roundExpired:      make(chan struct{}),
+		newProposal:       make(chan newProposalEvent),
+		roundCertificate:  make(chan uint64),
+		futureRoundChange: make(chan uint64),
+		persistFailed:     make(chan error),
+		futureTallies:     make(map[uint64]*futureTally),
 		state: &state{
 			view: &proto.View{
 				Height: 0,
@@ -114,32 +182,42 @@ func NewIBFT(
 			roundStarted: false,
 			name:         newRound,
 		},
-		baseRoundTimeout: round0Timeout,
+		roundTimeout: NewExponentialRoundTimeout(round0Timeout, 0),
+		clock:        realClock{},
+		metrics:      nopMetrics{},
+		observer:     NopObserver{},
 	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	i.messages = i.newMessages()
+
+	return i
 }
 
-// startRoundTimer starts the exponential round timer, based on the
-// passed in round number
+// startRoundTimer starts the round timer, based on the
+// passed in round number and the round timeout policy
 func (i *IBFT) startRoundTimer(ctx context.Context, round uint64) {
 	defer i.wg.Done()
 
-	var (
-		duration     = int(i.baseRoundTimeout)
-		roundFactor  = int(math.Pow(float64(2), float64(round)))
-		roundTimeout = time.Duration(duration * roundFactor)
-	)
+	roundTimeout := i.roundTimeout.RoundTimeout(round)
 
 	//	Create a new timer instance
-	totalTimeout := roundTimeout + i.additionalTimeout
-	timer := time.NewTimer(totalTimeout)
+	totalTimeout := addTimeouts(roundTimeout, i.additionalTimeout)
+	i.state.setRoundDeadline(i.clock.Now().Add(totalTimeout))
+	timer := i.clock.NewTimer(totalTimeout)
 	i.log.Debug("round timer set", "round", round, "timeout", totalTimeout)
 
+	defer i.state.setRoundDeadline(time.Time{})
+
 	select {
 	case <-ctx.Done():
 		// Stop signal received, stop the timer
 		i.log.Debug("timer stop signal received", "round", round, "timeout", totalTimeout)
 		timer.Stop()
-	case <-timer.C:
+	case <-timer.C():
 		// Timer expired, alert the round change channel to move
 		// to the next round
 		i.signalRoundExpired(ctx)
@@ -176,6 +254,24 @@ func (i *IBFT) signalNewRCC(ctx context.Context, round uint64) {
 	}
 }
 
+// signalFutureRoundChange notifies the sequence routine (RunSequence) that
+// F+1 valid Round Change messages for higher rounds appeared
+func (i *IBFT) signalFutureRoundChange(ctx context.Context, round uint64) {
+	select {
+	case i.futureRoundChange <- round:
+	case <-ctx.Done():
+	}
+}
+
+// signalPersistFailed notifies the sequence routine (RunSequence)
+// that the state could not be persisted, so the sequence has to stop
+func (i *IBFT) signalPersistFailed(ctx context.Context, err error) {
+	select {
+	case i.persistFailed <- err:
+	case <-ctx.Done():
+	}
+}
+
 type newProposalEvent struct {
 	proposalMessage *proto.Message
 	round           uint64
@@ -247,7 +343,6 @@ func (i *IBFT) watchForRoundChangeCertificates(ctx context.Context) {
 		view   = i.state.getView()
 		height = view.Height
 		round  = view.Round
-		quorum = i.backend.Quorum(height)
 
 		sub = i.messages.Subscribe(messages.SubscriptionDetails{
 			MessageType: proto.MessageType_ROUND_CHANGE,
@@ -272,33 +367,58 @@ func (i *IBFT) watchForRoundChangeCertificates(ctx context.Context) {
 					Height: height,
 					Round:  round,
 				},
-				quorum,
 			)
-			if rcc == nil {
-				continue
+			if rcc != nil {
+				//	we received a valid RCC for a higher round
+				i.signalNewRCC(ctx, round)
+
+				return
 			}
 
-			//	we received a valid RCC for a higher round
-			i.signalNewRCC(ctx, round)
+			// F+1 nodes are on higher rounds, so at least one honest
+			// node is. Catch up with them instead of waiting for the timeout
+			if futureRound, ok := i.handleFutureRoundChangeMessages(view); ok {
+				i.signalFutureRoundChange(ctx, futureRound)
 
-			return
+				return
+			}
 		}
 	}
 }
 
-// RunSequence runs the IBFT sequence for the specified height
-func (i *IBFT) RunSequence(ctx context.Context, h uint64) {
+// RunSequence runs the IBFT sequence for the specified height.
+// It returns the result of the sequence once the proposal is finalized
+// and inserted. If the context is cancelled before that, the context
+// error is returned. If the backend reports that the finalized proposal
+// cannot be inserted, the result is returned alongside an error
+// wrapping ErrInsertBlock. If the committed seals cannot be aggregated,
+// the result is returned alongside an error wrapping ErrAggregateSeals
+func (i *IBFT) RunSequence(ctx context.Context, h uint64) (*SequenceResult, error) {
+	sequenceStart := i.clock.Now()
+
 	// Set the starting state data
 	i.state.clear(h)
 	i.messages.PruneByHeight(h)
 
+	// Resume from the persisted state, if any
+	i.restoreState(h)
+
 	i.log.Info("sequence started", "height", h)
 	defer i.log.Info("sequence done", "height", h)
 
+	i.metrics.SetGauge(MetricHeight, float64(h))
+	i.observer.SequenceStarted(h)
+
 	for {
-		view := i.state.getView()
+		var (
+			view       = i.state.getView()
+			roundStart = i.clock.Now()
+		)
 
 		i.log.Info("round started", "round", view.Round)
+		i.state.setRoundStart(roundStart)
+		i.metrics.SetGauge(MetricRound, float64(view.Round))
+		i.observer.RoundStarted(view)
 
 		currentRound := view.Round
 		ctxRound, cancelRound := context.WithCancel(ctx)
@@ -326,6 +446,7 @@ func (i *IBFT) RunSequence(ctx context.Context, h uint64) {
 		case ev := <-i.newProposal:
 			teardown()
 			i.log.Info("received future proposal", "round", ev.round)
+			i.observeRoundEnd(roundStart, roundFutureProposal)
 
 			i.moveToNewRound(ev.round)
 			i.acceptProposal(ev.proposalMessage)
@@ -333,32 +454,96 @@ func (i *IBFT) RunSequence(ctx context.Context, h uint64) {
 		case round := <-i.roundCertificate:
 			teardown()
 			i.log.Info("received future RCC", "round", round)
+			i.observeRoundEnd(roundStart, roundCertificate)
+			i.observer.RoundChangeCertificateReceived(&proto.View{
+				Height: h,
+				Round:  round,
+			})
 
 			i.moveToNewRound(round)
+		case round := <-i.futureRoundChange:
+			teardown()
+			i.log.Info("received F+1 future round changes", "round", round)
+			i.observeRoundEnd(roundStart, roundFutureChanges)
+
+			i.moveToNewRound(round)
+
+			if err := i.sendRoundChangeMessage(h, round); err != nil {
+				return i.sequenceDone(h, nil, err)
+			}
 		case <-i.roundExpired:
 			teardown()
 			i.log.Info("round timeout expired", "round", currentRound)
+			i.observeRoundEnd(roundStart, roundTimeout)
+			i.observer.RoundTimeout(view)
 
 			newRound := currentRound + 1
 			i.moveToNewRound(newRound)
 
-			i.sendRoundChangeMessage(h, newRound)
+			if err := i.sendRoundChangeMessage(h, newRound); err != nil {
+				return i.sequenceDone(h, nil, err)
+			}
+		case err := <-i.persistFailed:
+			teardown()
+			i.log.Error("sequence stopped", "err", err)
+			i.observeRoundEnd(roundStart, roundCancelled)
+
+			return i.sequenceDone(h, nil, err)
 		case <-i.roundDone:
 			// The consensus cycle for the block height is finished.
 			// Stop all running worker threads
 			teardown()
-			i.insertBlock()
+			i.observeLatency()
+			i.observeRoundEnd(roundStart, roundFinalized)
+
+			result := &SequenceResult{
+				Height:         h,
+				Round:          i.state.getRound(),
+				Proposal:       i.state.getProposal(),
+				CommittedSeals: i.state.getCommittedSeals(),
+				RoundChanges:   i.state.getRoundChanges(),
+				Duration:       i.clock.Now().Sub(sequenceStart),
+				StateDurations: i.state.getDurations(),
+				Signers:        i.signerBitmap(h, i.state.getCommittedSeals()),
+			}
 
-			return
+			aggregatedSeal, err := i.aggregateSeals(h, result.CommittedSeals)
+			if err != nil {
+				i.log.Error("unable to aggregate committed seals", "err", err)
+
+				return i.sequenceDone(h, result, &sentinelError{sentinel: ErrAggregateSeals, err: err})
+			}
+
+			result.AggregatedSeal = aggregatedSeal
+			result.Certificate = i.commitCertificate(aggregatedSeal)
+
+			if err := i.insertBlock(aggregatedSeal); err != nil {
+				i.log.Error("unable to insert block", "err", err)
+
+				return i.sequenceDone(h, result, &sentinelError{sentinel: ErrInsertBlock, err: err})
+			}
+
+			i.metrics.ObserveHistogram(MetricSequenceDuration, result.Duration.Seconds())
+
+			return i.sequenceDone(h, result, nil)
 		case <-ctx.Done():
 			teardown()
 			i.log.Debug("sequence cancelled")
+			i.observeRoundEnd(roundStart, roundCancelled)
 
-			return
+			return i.sequenceDone(h, nil, ctx.Err())
 		}
 	}
 }
 
+// sequenceDone notifies the observer of the sequence
+// end, and returns the result of the sequence
+func (i *IBFT) sequenceDone(height uint64, result *SequenceResult, err error) (*SequenceResult, error) {
+	i.observer.SequenceDone(height, result, err)
+
+	return result, err
+}
+
 // startRound runs the state machine loop for the current round
 func (i *IBFT) startRound(ctx context.Context) {
 	// Register this worker thread with the barrier
@@ -371,10 +556,15 @@ func (i *IBFT) startRound(ctx context.Context) {
 		view = i.state.getView()
 	)
 
-	// Check if any block needs to be proposed
-	if i.backend.IsProposer(id, view.Height, view.Round) {
+	// Check if any block needs to be proposed. A proposal that is already
+	// accepted (restored from the state store) must not be built again
+	if i.state.getProposalMessage() == nil && i.backend.IsProposer(id, view.Height, view.Round) {
 		i.log.Info("we are the proposer")
 
+		if !i.waitBlockInterval(ctx) {
+			return
+		}
+
 		proposalMessage := i.buildProposal(ctx, view)
 		if proposalMessage == nil {
 			i.log.Error("unable to build proposal")
@@ -382,10 +572,20 @@ func (i *IBFT) startRound(ctx context.Context) {
 			return
 		}
 
+		if err := i.signMessage(proposalMessage); err != nil {
+			i.log.Error("unable to sign proposal", "err", err)
+
+			return
+		}
+
 		i.acceptProposal(proposalMessage)
 		i.log.Debug("block proposal accepted")
 
-		i.sendPreprepareMessage(proposalMessage)
+		if err := i.sendPreprepareMessage(proposalMessage); err != nil {
+			i.signalPersistFailed(ctx, err)
+
+			return
+		}
 
 		i.log.Debug("pre-prepare message multicasted")
 	}
@@ -410,7 +610,7 @@ func (i *IBFT) waitForRCC(
 			messages.SubscriptionDetails{
 				MessageType:    proto.MessageType_ROUND_CHANGE,
 				View:           view,
-				MinNumMessages: int(quorum),
+				MinNumMessages: i.quorumSubscriptionSize(height, int(quorum)),
 			},
 		)
 	)
@@ -422,7 +622,7 @@ func (i *IBFT) waitForRCC(
 		case <-ctx.Done():
 			return nil
 		case <-sub.SubCh:
-			rcc := i.handleRoundChangeMessage(view, quorum)
+			rcc := i.handleRoundChangeMessage(view)
 			if rcc == nil {
 				continue
 			}
@@ -434,23 +634,14 @@ func (i *IBFT) waitForRCC(
 
 // handleRoundChangeMessage validates the round change message
 // and constructs a RCC if possible
-func (i *IBFT) handleRoundChangeMessage(view *proto.View, quorum uint64) *proto.RoundChangeCertificate {
+func (i *IBFT) handleRoundChangeMessage(view *proto.View) *proto.RoundChangeCertificate {
 	var (
 		height = view.Height
 		round  = view.Round
 	)
 
 	isValidFn := func(msg *proto.Message) bool {
-		proposal := messages.ExtractLastPreparedProposedBlock(msg)
-		certificate := messages.ExtractLatestPC(msg)
-
-		// Check if the prepared certificate is valid
-		if !i.validPC(certificate, round, height) {
-			return false
-		}
-
-		// Make sure the certificate matches the proposal
-		return i.proposalMatchesCertificate(proposal, certificate)
+		return i.isValidRoundChangeMessage(msg, round, height)
 	}
 
 	msgs := i.messages.GetValidMessages(
@@ -459,7 +650,7 @@ func (i *IBFT) handleRoundChangeMessage(view *proto.View, quorum uint64) *proto.
 		isValidFn,
 	)
 
-	if len(msgs) < int(quorum) {
+	if !i.hasQuorum(height, msgs) {
 		return nil
 	}
 
@@ -468,6 +659,51 @@ func (i *IBFT) handleRoundChangeMessage(view *proto.View, quorum uint64) *proto.
 	}
 }
 
+// handleFutureRoundChangeMessages checks if there are F+1 valid round change
+// messages (from unique senders) for rounds higher than the current one.
+// If so, it returns the lowest of those rounds
+func (i *IBFT) handleFutureRoundChangeMessages(view *proto.View) (uint64, bool) {
+	var (
+		height = view.Height
+
+		lowestRound uint64
+		validMsgs   = make([]*proto.Message, 0)
+	)
+
+	for _, msg := range i.messages.GetHighestRoundChangeMessages(view.Round+1, height) {
+		if !i.isValidRoundChangeMessage(msg, msg.View.Round, height) {
+			continue
+		}
+
+		if len(validMsgs) == 0 || msg.View.Round < lowestRound {
+			lowestRound = msg.View.Round
+		}
+
+		validMsgs = append(validMsgs, msg)
+	}
+
+	if !i.hasHonestSender(height, validMsgs) {
+		return 0, false
+	}
+
+	return lowestRound, true
+}
+
+// isValidRoundChangeMessage checks if the round change message
+// for the specified round is valid
+func (i *IBFT) isValidRoundChangeMessage(msg *proto.Message, round, height uint64) bool {
+	proposal := messages.ExtractLastPreparedProposedBlock(msg)
+	certificate := messages.ExtractLatestPC(msg)
+
+	// Check if the prepared certificate is valid
+	if !i.validPC(certificate, round, height) {
+		return false
+	}
+
+	// Make sure the certificate matches the proposal
+	return i.proposalMatchesCertificate(proposal, certificate)
+}
+
 // proposalMatchesCertificate checks a prepared certificate
 // against a proposal
 func (i *IBFT) proposalMatchesCertificate(
@@ -509,23 +745,44 @@ func (i *IBFT) proposalMatchesCertificate(
 
 //	runStates is the main loop which performs state transitions
 func (i *IBFT) runStates(ctx context.Context) {
-	var timeout error
+	var err error
 
 	for {
-		switch i.state.getStateName() {
+		var (
+			currentState = i.state.getStateName()
+			stateStart   = i.clock.Now()
+		)
+
+		switch currentState {
 		case newRound:
-			timeout = i.runNewRound(ctx)
+			err = i.runNewRound(ctx)
 		case prepare:
-			timeout = i.runPrepare(ctx)
+			err = i.runPrepare(ctx)
 		case commit:
-			timeout = i.runCommit(ctx)
+			err = i.runCommit(ctx)
 		case fin:
 			i.runFin(ctx)
 
 			return
 		}
 
-		if timeout != nil {
+		stateDuration := i.clock.Now().Sub(stateStart)
+
+		i.state.addDuration(currentState, stateDuration)
+		i.metrics.ObserveHistogram(
+			MetricStateDuration,
+			stateDuration.Seconds(),
+			"state", metricLabel(currentState.String()),
+		)
+
+		if errors.Is(err, ErrPersistState) {
+			// The node can't commit to the next state
+			i.signalPersistFailed(ctx, err)
+
+			return
+		}
+
+		if err != nil {
 			// Timeout received
 			return
 		}
@@ -572,7 +829,9 @@ func (i *IBFT) runNewRound(ctx context.Context) error {
 			i.acceptProposal(proposalMessage)
 
 			// Multicast the PREPARE message
-			i.sendPrepareMessage(view)
+			if err := i.sendPrepareMessage(view); err != nil {
+				return err
+			}
 
 			i.log.Debug("prepare message multicasted")
 
@@ -656,7 +915,7 @@ func (i *IBFT) validateProposal(msg *proto.Message, view *proto.View) bool {
 	}
 
 	// Make sure there are Quorum RCC
-	if len(certificate.RoundChangeMessages) < int(i.backend.Quorum(height)) {
+	if !i.hasQuorum(height, certificate.RoundChangeMessages) {
 		return false
 	}
 
@@ -671,6 +930,22 @@ func (i *IBFT) validateProposal(msg *proto.Message, view *proto.View) bool {
 		if rc.Type != proto.MessageType_ROUND_CHANGE {
 			return false
 		}
+
+		// Make sure the message is for the proposal view, so round changes
+		// from lower rounds can't be reused to justify a different proposal
+		if rc.GetView().GetHeight() != height || rc.GetView().GetRound() != round {
+			return false
+		}
+
+		// Make sure the sender is valid, and the message is signed by it
+		if !i.isValidSender(rc) {
+			return false
+		}
+	}
+
+	// Make sure the senders are unique
+	if !messages.HasUniqueSenders(certificate.RoundChangeMessages) {
+		return false
 	}
 
 	// Extract possible rounds and their corresponding
@@ -690,7 +965,7 @@ func (i *IBFT) validateProposal(msg *proto.Message, view *proto.View) bool {
 			hash := messages.ExtractProposalHash(certificate.ProposalMessage)
 
 			roundsAndPreparedBlockHashes = append(roundsAndPreparedBlockHashes, roundHashTuple{
-				round: rcMessage.View.Round,
+				round: certificate.ProposalMessage.GetView().GetRound(),
 				hash:  hash,
 			})
 		}
@@ -700,14 +975,14 @@ func (i *IBFT) validateProposal(msg *proto.Message, view *proto.View) bool {
 		return true
 	}
 
-	// Find the max round
+	// Find the max prepared round
 	var (
 		maxRound     uint64 = 0
 		expectedHash []byte = nil
 	)
 
 	for _, tuple := range roundsAndPreparedBlockHashes {
-		if tuple.round > maxRound {
+		if expectedHash == nil || tuple.round > maxRound {
 			maxRound = tuple.round
 			expectedHash = tuple.hash
 		}
@@ -758,7 +1033,7 @@ func (i *IBFT) runPrepare(ctx context.Context) error {
 			messages.SubscriptionDetails{
 				MessageType:    proto.MessageType_PREPARE,
 				View:           view,
-				MinNumMessages: int(quorum) - 1,
+				MinNumMessages: i.quorumSubscriptionSize(view.Height, int(quorum)-1),
 			},
 		)
 	)
@@ -773,7 +1048,12 @@ func (i *IBFT) runPrepare(ctx context.Context) error {
 			// Stop signal received, exit
 			return errTimeoutExpired
 		case <-sub.SubCh:
-			if !i.handlePrepare(view, quorum) {
+			prepared, err := i.handlePrepare(view)
+			if err != nil {
+				return err
+			}
+
+			if !prepared {
 				//	quorum of valid prepare messages not received, retry
 				continue
 			}
@@ -784,8 +1064,9 @@ func (i *IBFT) runPrepare(ctx context.Context) error {
 }
 
 //	handlePrepare parses available prepare messages and performs
-//	a transition to COMMIT state, if quorum was reached
-func (i *IBFT) handlePrepare(view *proto.View, quorum uint64) bool {
+//	a transition to COMMIT state, if quorum was reached.
+//	The error is returned if the COMMIT message could not be sent
+func (i *IBFT) handlePrepare(view *proto.View) (bool, error) {
 	isValidPrepare := func(message *proto.Message) bool {
 		// Verify that the proposal hash is valid
 		return i.backend.IsValidProposalHash(
@@ -800,25 +1081,33 @@ func (i *IBFT) handlePrepare(view *proto.View, quorum uint64) bool {
 		isValidPrepare,
 	)
 
-	if len(prepareMessages) < int(quorum)-1 {
+	// The proposer's PREPREPARE counts towards the quorum
+	if !i.hasQuorum(
+		view.Height,
+		append([]*proto.Message{i.state.getProposalMessage()}, prepareMessages...),
+	) {
 		//	quorum not reached, keep polling
-		return false
+		return false, nil
 	}
 
+	i.state.setPrepareLatency(i.clock.Now())
+
+	certificate := &proto.PreparedCertificate{
+		ProposalMessage: i.state.getProposalMessage(),
+		PrepareMessages: prepareMessages,
+	}
+
+	i.state.finalizePrepare(certificate, i.state.getProposal())
+	i.observer.Prepared(view, certificate)
+
 	// Multicast the COMMIT message
-	i.sendCommitMessage(view)
+	if err := i.sendCommitMessage(view); err != nil {
+		return false, err
+	}
 
 	i.log.Debug("commit message multicasted")
 
-	i.state.finalizePrepare(
-		&proto.PreparedCertificate{
-			ProposalMessage: i.state.getProposalMessage(),
-			PrepareMessages: prepareMessages,
-		},
-		i.state.getProposal(),
-	)
-
-	return true
+	return true, nil
 }
 
 // runCommit runs the Commit IBFT state
@@ -838,7 +1127,7 @@ func (i *IBFT) runCommit(ctx context.Context) error {
 			messages.SubscriptionDetails{
 				MessageType:    proto.MessageType_COMMIT,
 				View:           view,
-				MinNumMessages: int(quorum),
+				MinNumMessages: i.quorumSubscriptionSize(view.Height, int(quorum)),
 			},
 		)
 	)
@@ -853,7 +1142,7 @@ func (i *IBFT) runCommit(ctx context.Context) error {
 			// Stop signal received, exit
 			return errTimeoutExpired
 		case <-sub.SubCh:
-			if !i.handleCommit(view, quorum) {
+			if !i.handleCommit(view) {
 				//	quorum not reached, retry
 				continue
 			}
@@ -865,7 +1154,7 @@ func (i *IBFT) runCommit(ctx context.Context) error {
 
 //	handleCommit parses available commit messages and performs
 //	a transition to FIN state, if quorum was reached
-func (i *IBFT) handleCommit(view *proto.View, quorum uint64) bool {
+func (i *IBFT) handleCommit(view *proto.View) bool {
 	isValidCommit := func(message *proto.Message) bool {
 		var (
 			proposalHash  = messages.ExtractCommitHash(message)
@@ -881,15 +1170,18 @@ func (i *IBFT) handleCommit(view *proto.View, quorum uint64) bool {
 	}
 
 	commitMessages := i.messages.GetValidMessages(view, proto.MessageType_COMMIT, isValidCommit)
-	if len(commitMessages) < int(quorum) {
+	if !i.hasQuorum(view.Height, commitMessages) {
 		//	quorum not reached, keep polling
 		return false
 	}
 
+	i.state.setCommitLatency(i.clock.Now())
+
 	// Set the committed seals
-	i.state.setCommittedSeals(
-		messages.ExtractCommittedSeals(commitMessages),
-	)
+	committedSeals := messages.ExtractCommittedSeals(commitMessages)
+
+	i.state.setCommittedSeals(committedSeals)
+	i.observer.Committed(view, committedSeals)
 
 	//	Move to the fin state
 	i.state.changeState(fin)
@@ -905,24 +1197,66 @@ func (i *IBFT) runFin(ctx context.Context) {
 	i.signalRoundDone(ctx)
 }
 
-// insertBlock inserts the block
-func (i *IBFT) insertBlock() {
+// observeLatency reports the latency of the finalizing round
+// to the round timeout policy, if it adapts to it
+func (i *IBFT) observeLatency() {
+	observer, ok := i.roundTimeout.(LatencyObserver)
+	if !ok {
+		return
+	}
+
+	latency := i.state.getLatency()
+	if latency.Prepare <= 0 || latency.Commit <= 0 {
+		// The quorums were not observed in the finalizing
+		// round, e.g. the node resumed from a restored state
+		return
+	}
+
+	observer.ObserveLatency(latency)
+}
+
+// insertBlock inserts the block, with the aggregated
+// committed seal if the seals were aggregated
+func (i *IBFT) insertBlock(aggregatedSeal *messages.AggregatedSeal) error {
 	i.log.Debug("enter: insert block")
 	defer i.log.Debug("exit: insert block")
 
 	// Insert the block to the node's underlying
 	// blockchain layer
-	i.backend.InsertBlock(
-		i.state.getProposal(),
-		i.state.getCommittedSeals(),
-	)
+	if err := i.insertProposal(aggregatedSeal); err != nil {
+		return err
+	}
 
 	// Remove stale messages
 	i.messages.PruneByHeight(i.state.getHeight())
+
+	return nil
+}
+
+// insertProposal inserts the finalized proposal using the backend
+// extensions it implements, and returns the insertion error, if reported
+func (i *IBFT) insertProposal(aggregatedSeal *messages.AggregatedSeal) error {
+	var (
+		proposal       = i.state.getProposal()
+		committedSeals = i.state.getCommittedSeals()
+	)
+
+	if aggregator, ok := i.backend.(SealAggregatingBackend); ok && aggregatedSeal != nil {
+		return aggregator.InsertAggregatedBlock(proposal, aggregatedSeal)
+	}
+
+	if inserter, ok := i.backend.(CheckedInsertBackend); ok {
+		return inserter.InsertBlockWithError(proposal, committedSeals)
+	}
+
+	i.backend.InsertBlock(proposal, committedSeals)
+
+	return nil
 }
 
 // moveToNewRound moves the state to the new round
 func (i *IBFT) moveToNewRound(round uint64) {
+	i.state.incRoundChanges()
 	i.state.setView(&proto.View{
 		Height: i.state.getHeight(),
 		Round:  round,
@@ -1002,41 +1336,91 @@ func (i *IBFT) acceptProposal(proposalMessage *proto.Message) {
 	//	accept newly proposed block and move to PREPARE state
 	i.state.setProposalMessage(proposalMessage)
 	i.state.changeState(prepare)
+
+	i.observer.ProposalAccepted(proposalMessage.View, messages.ExtractProposal(proposalMessage))
 }
 
-// AddMessage adds a new message to the IBFT message system
-func (i *IBFT) AddMessage(message *proto.Message) {
-	// Make sure the message is present
-	if message == nil {
-		return
+// AddMessage adds a new message to the IBFT message system.
+// If the message is rejected, the reason is returned
+// (ErrMalformed, ErrInvalidSender, ErrStaleHeight...)
+func (i *IBFT) AddMessage(message *proto.Message) error {
+	// Check if the message should even be considered
+	err := i.validateMessage(message)
+	i.observeMessage(message, err)
+
+	if err != nil {
+		return err
 	}
 
-	// Check if the message should even be considered
-	if i.isAcceptableMessage(message) {
-		i.messages.AddMessage(message)
+	i.messages.AddMessage(message)
+
+	// Check if the node fell behind the network
+	if message.View.Height > i.state.getHeight() {
+		i.checkFutureHeight(message)
 	}
+
+	return nil
 }
 
-// isAcceptableMessage checks if the message can even be accepted
-func (i *IBFT) isAcceptableMessage(message *proto.Message) bool {
+// validateMessage checks if the message can even be accepted,
+// and returns the reason if it cannot
+func (i *IBFT) validateMessage(message *proto.Message) error {
+	// Invalid messages are discarded
+	if message == nil || message.View == nil || !hasValidPayload(message) {
+		return ErrMalformed
+	}
+
 	//	Make sure the message sender is ok
 	if !i.backend.IsValidSender(message) {
-		return false
+		return ErrInvalidSender
 	}
 
-	// Invalid messages are discarded
-	if message.View == nil {
-		return false
+	// Make sure the message is signed by the sender
+	if !i.isValidSignature(message) {
+		return ErrInvalidSignature
 	}
 
+	// Make sure the sender is in the validator set, if known
+	if set, ok := i.validatorSet(message.View.Height); ok {
+		if _, isValidator := set.Index(message.From); !isValidator {
+			return ErrNotValidator
+		}
+	}
+
+	currentView := i.state.getView()
+
 	// Make sure the message is in accordance with
 	// the current state height, or greater
-	if i.state.getHeight() > message.View.Height {
-		return false
+	if currentView.Height > message.View.Height {
+		return ErrStaleHeight
+	}
+
+	// Make sure the message round is >= the current state round.
+	// Messages for future heights are accepted for any round, as
+	// they are needed to detect that the node fell behind
+	if message.View.Height == currentView.Height && message.View.Round < currentView.Round {
+		return ErrStaleRound
 	}
 
-	// Make sure the message round is >= the current state round
-	return message.View.Round >= i.state.getRound()
+	// Make sure the message is not too far ahead of the current view
+	return i.checkFutureLimits(message.View)
+}
+
+// hasValidPayload checks if the message type is known,
+// and the message carries the payload of that type
+func hasValidPayload(message *proto.Message) bool {
+	switch message.Type {
+	case proto.MessageType_PREPREPARE:
+		return message.GetPreprepareData() != nil
+	case proto.MessageType_PREPARE:
+		return message.GetPrepareData() != nil
+	case proto.MessageType_COMMIT:
+		return message.GetCommitData() != nil
+	case proto.MessageType_ROUND_CHANGE:
+		return message.GetRoundChangeData() != nil
+	default:
+		return false
+	}
 }
 
 //	ExtendRoundTimeout extends each round's timer by the specified amount.
@@ -1066,7 +1450,7 @@ func (i *IBFT) validPC(
 	)
 
 	// Make sure there are at least Quorum (PP + P) messages
-	if len(allMessages) < int(i.backend.Quorum(i.state.getHeight())) {
+	if !i.hasQuorum(i.state.getHeight(), allMessages) {
 		return false
 	}
 
@@ -1102,6 +1486,11 @@ func (i *IBFT) validPC(
 		return false
 	}
 
+	// Make sure all the messages are from the proposal round
+	if !messages.AllHaveSameRound(allMessages, certificate.ProposalMessage.GetView().GetRound()) {
+		return false
+	}
+
 	// Make sure the proposal message is sent by the proposer
 	// for the round
 	proposal := certificate.ProposalMessage
@@ -1109,10 +1498,15 @@ func (i *IBFT) validPC(
 		return false
 	}
 
+	// Make sure the proposer is a valid sender, and signed the message
+	if !i.isValidSender(proposal) {
+		return false
+	}
+
 	// Make sure the Prepare messages are validators, apart from the proposer
 	for _, message := range certificate.PrepareMessages {
 		// Make sure the sender is part of the validator set
-		if !i.backend.IsValidSender(message) {
+		if !i.isValidSender(message) {
 			return false
 		}
 	}
@@ -1120,14 +1514,101 @@ func (i *IBFT) validPC(
 	return true
 }
 
-// sendPreprepareMessage sends out the preprepare message
-func (i *IBFT) sendPreprepareMessage(message *proto.Message) {
+// restoreState restores the persisted consensus state
+// for the specified height, if present
+func (i *IBFT) restoreState(height uint64) {
+	if i.store == nil {
+		return
+	}
+
+	snapshot, err := i.store.Load()
+	if err != nil {
+		if !errors.Is(err, ErrSnapshotNotFound) {
+			i.log.Error("unable to load state", "err", err)
+		}
+
+		return
+	}
+
+	if snapshot.View == nil || snapshot.View.Height != height {
+		// The snapshot is for a different height
+		return
+	}
+
+	i.state.restore(snapshot)
+
+	i.log.Info("state restored", "round", snapshot.View.Round)
+
+	i.resendPersistedMessage()
+}
+
+// resendPersistedMessage sends out the message the restored state was
+// persisted for, as the node may have stopped before sending it.
+// The messages are the same as the original ones, since they are
+// built from the persisted view, proposal and prepared certificate
+func (i *IBFT) resendPersistedMessage() {
+	var (
+		view            = i.state.getView()
+		proposalMessage = i.state.getProposalMessage()
+	)
+
+	switch {
+	case i.state.getStateName() == commit:
+		i.multicast(i.backend.BuildCommitMessage(i.state.getProposalHash(), view))
+	case proposalMessage != nil && bytes.Equal(proposalMessage.From, i.backend.ID()):
+		// The proposal is already signed
+		i.transport.Multicast(proposalMessage)
+	case proposalMessage != nil:
+		i.multicast(i.backend.BuildPrepareMessage(i.state.getProposalHash(), view))
+	case view.Round > 0:
+		i.multicast(
+			i.backend.BuildRoundChangeMessage(
+				i.state.getLatestPreparedProposedBlock(),
+				i.state.getLatestPC(),
+				view,
+			),
+		)
+	}
+}
+
+// persistState writes the current state to the state store, if set.
+// Messages that commit the node to a certain state
+// must only be multicasted after the state has been persisted.
+// The returned error wraps ErrPersistState
+func (i *IBFT) persistState() error {
+	if i.store == nil {
+		return nil
+	}
+
+	if err := i.store.Save(i.state.snapshot()); err != nil {
+		i.log.Error("unable to persist state", "err", err)
+
+		return &sentinelError{sentinel: ErrPersistState, err: err}
+	}
+
+	return nil
+}
+
+// sendPreprepareMessage sends out the preprepare message,
+// once the state is persisted
+func (i *IBFT) sendPreprepareMessage(message *proto.Message) error {
+	if err := i.persistState(); err != nil {
+		return err
+	}
+
 	i.transport.Multicast(message)
+
+	return nil
 }
 
-// sendRoundChangeMessage sends out the round change message
-func (i *IBFT) sendRoundChangeMessage(height, newRound uint64) {
-	i.transport.Multicast(
+// sendRoundChangeMessage sends out the round change
+// message, once the state is persisted
+func (i *IBFT) sendRoundChangeMessage(height, newRound uint64) error {
+	if err := i.persistState(); err != nil {
+		return err
+	}
+
+	i.multicast(
 		i.backend.BuildRoundChangeMessage(
 			i.state.getLatestPreparedProposedBlock(),
 			i.state.getLatestPC(),
@@ -1137,24 +1618,51 @@ func (i *IBFT) sendRoundChangeMessage(height, newRound uint64) {
 			},
 		),
 	)
+
+	return nil
 }
 
-// sendPrepareMessage sends out the prepare message
-func (i *IBFT) sendPrepareMessage(view *proto.View) {
-	i.transport.Multicast(
+// sendPrepareMessage sends out the prepare message,
+// once the state is persisted
+func (i *IBFT) sendPrepareMessage(view *proto.View) error {
+	if err := i.persistState(); err != nil {
+		return err
+	}
+
+	i.multicast(
 		i.backend.BuildPrepareMessage(
 			i.state.getProposalHash(),
 			view,
 		),
 	)
+
+	return nil
 }
 
-// sendCommitMessage sends out the commit message
-func (i *IBFT) sendCommitMessage(view *proto.View) {
-	i.transport.Multicast(
+// sendCommitMessage sends out the commit message,
+// once the state is persisted
+func (i *IBFT) sendCommitMessage(view *proto.View) error {
+	if err := i.persistState(); err != nil {
+		return err
+	}
+
+	i.multicast(
 		i.backend.BuildCommitMessage(
 			i.state.getProposalHash(),
 			view,
 		),
 	)
+
+	return nil
+}
+
+// multicast signs and sends out the message
+func (i *IBFT) multicast(message *proto.Message) {
+	if err := i.signMessage(message); err != nil {
+		i.log.Error("unable to send message", "type", message.Type, "err", err)
+
+		return
+	}
+
+	i.transport.Multicast(message)
 }
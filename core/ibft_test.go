@@ -3,6 +3,7 @@ package core
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/nubank/go-ibft/messages"
 	"github.com/nubank/go-ibft/messages/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func proposalMatches(proposal []byte, message *proto.Message) bool {
@@ -150,11 +152,12 @@ func filterMessages(messages []*proto.Message, isValid func(message *proto.Messa
 }
 
 func generateFilledRCMessages(
-	quorum uint64,
+	quorum,
+	round uint64,
 	proposal,
 	proposalHash []byte) []*proto.Message {
 	// Generate random RC messages
-	roundChangeMessages := generateMessages(quorum, proto.MessageType_ROUND_CHANGE)
+	roundChangeMessages := generateMessagesWithUniqueSender(quorum, proto.MessageType_ROUND_CHANGE)
 	prepareMessages := generateMessages(quorum-1, proto.MessageType_PREPARE)
 
 	// Fill up the prepare message hashes
@@ -200,7 +203,7 @@ func generateFilledRCMessages(
 		}
 		message.View = &proto.View{
 			Height: 0,
-			Round:  1,
+			Round:  round,
 		}
 	}
 
@@ -709,7 +712,7 @@ func TestRunNewRound_Validator_NonZero(t *testing.T) {
 					Proposal:     proposal,
 					ProposalHash: proposalHash,
 					Certificate: &proto.RoundChangeCertificate{
-						RoundChangeMessages: generateFilledRCMessages(quorum, proposal, proposalHash),
+						RoundChangeMessages: generateFilledRCMessages(quorum, 1, proposal, proposalHash),
 					},
 				},
 			},
@@ -1041,7 +1044,7 @@ func TestRunCommit(t *testing.T) {
 
 			i.wg.Add(1)
 			i.startRound(ctx)
-			i.insertBlock()
+			assert.NoError(t, i.insertBlock(nil))
 
 			i.wg.Wait()
 
@@ -1075,21 +1078,21 @@ func TestIBFT_IsAcceptableMessage(t *testing.T) {
 		view          *proto.View
 		currentView   *proto.View
 		invalidSender bool
-		acceptable    bool
+		expectedErr   error
 	}{
 		{
 			"invalid sender",
-			nil,
+			baseView,
 			baseView,
 			true,
-			false,
+			ErrInvalidSender,
 		},
 		{
 			"malformed message",
 			nil,
 			baseView,
 			false,
-			false,
+			ErrMalformed,
 		},
 		{
 			"higher height number",
@@ -1099,7 +1102,7 @@ func TestIBFT_IsAcceptableMessage(t *testing.T) {
 			},
 			baseView,
 			false,
-			true,
+			nil,
 		},
 		{
 			"higher round number",
@@ -1109,7 +1112,7 @@ func TestIBFT_IsAcceptableMessage(t *testing.T) {
 			},
 			baseView,
 			false,
-			true,
+			nil,
 		},
 		{
 			"lower height number",
@@ -1119,7 +1122,30 @@ func TestIBFT_IsAcceptableMessage(t *testing.T) {
 				Round:  baseView.Round,
 			},
 			false,
+			ErrStaleHeight,
+		},
+		{
+			"lower round number",
+			baseView,
+			&proto.View{
+				Height: baseView.Height,
+				Round:  baseView.Round + 1,
+			},
+			false,
+			ErrStaleRound,
+		},
+		{
+			"lower round number for a higher height",
+			&proto.View{
+				Height: baseView.Height + 1,
+				Round:  baseView.Round,
+			},
+			&proto.View{
+				Height: baseView.Height,
+				Round:  baseView.Round + 1,
+			},
 			false,
+			nil,
 		},
 	}
 
@@ -1142,13 +1168,93 @@ func TestIBFT_IsAcceptableMessage(t *testing.T) {
 
 			message := &proto.Message{
 				View: testCase.view,
+				Type: proto.MessageType_PREPARE,
+				Payload: &proto.Message_PrepareData{
+					PrepareData: &proto.PrepareMessage{},
+				},
 			}
 
-			assert.Equal(t, testCase.acceptable, i.isAcceptableMessage(message))
+			assert.Equal(t, testCase.expectedErr, i.validateMessage(message))
 		})
 	}
 }
 
+// TestIBFT_AddMessage_Rejected makes sure the rejected
+// messages are not added, and the reason is reported
+func TestIBFT_AddMessage_Rejected(t *testing.T) {
+	t.Parallel()
+
+	var (
+		added = false
+
+		log       = mockLogger{}
+		transport = mockTransport{}
+		backend   = mockBackend{
+			isValidSenderFn: func(message *proto.Message) bool {
+				return string(message.From) == "validator"
+			},
+		}
+		messages = mockMessages{
+			addMessageFn: func(_ *proto.Message) {
+				added = true
+			},
+		}
+	)
+
+	i := NewIBFT(log, backend, transport)
+	i.messages = messages
+
+	prepareData := &proto.Message_PrepareData{
+		PrepareData: &proto.PrepareMessage{},
+	}
+
+	assert.ErrorIs(t, i.AddMessage(nil), ErrMalformed)
+	assert.ErrorIs(t, i.AddMessage(&proto.Message{
+		View:    &proto.View{},
+		From:    []byte("unknown"),
+		Type:    proto.MessageType_PREPARE,
+		Payload: prepareData,
+	}), ErrInvalidSender)
+
+	// Make sure messages of unknown types are malformed
+	assert.ErrorIs(t, i.AddMessage(&proto.Message{
+		View:    &proto.View{},
+		From:    []byte("validator"),
+		Type:    proto.MessageType(42),
+		Payload: prepareData,
+	}), ErrMalformed)
+
+	// Make sure messages with a payload not matching the type are malformed
+	assert.ErrorIs(t, i.AddMessage(&proto.Message{
+		View:    &proto.View{},
+		From:    []byte("validator"),
+		Type:    proto.MessageType_COMMIT,
+		Payload: prepareData,
+	}), ErrMalformed)
+
+	assert.ErrorIs(t, i.AddMessage(&proto.Message{
+		View: &proto.View{},
+		From: []byte("validator"),
+		Type: proto.MessageType_PREPREPARE,
+	}), ErrMalformed)
+
+	assert.ErrorIs(t, i.AddMessage(&proto.Message{
+		View:    &proto.View{},
+		From:    []byte("validator"),
+		Type:    proto.MessageType_ROUND_CHANGE,
+		Payload: &proto.Message_RoundChangeData{},
+	}), ErrMalformed)
+	assert.False(t, added)
+
+	assert.NoError(t, i.AddMessage(&proto.Message{
+		View:    &proto.View{},
+		From:    []byte("validator"),
+		Type:    proto.MessageType_PREPARE,
+		Payload: prepareData,
+	}))
+	assert.True(t, added)
+}
+
 // TestIBFT_StartRoundTimer makes sure that the
 // round timer behaves correctly
 func TestIBFT_StartRoundTimer(t *testing.T) {
@@ -1194,8 +1300,7 @@ func TestIBFT_StartRoundTimer(t *testing.T) {
 			backend   = mockBackend{}
 		)
 
-		i := NewIBFT(log, backend, transport)
-		i.baseRoundTimeout = 0 * time.Second
+		i := NewIBFT(log, backend, transport, WithRoundTimeoutPolicy(NewConstantRoundTimeout(0)))
 
 		ctx, cancelFn := context.WithCancel(context.Background())
 
@@ -1266,9 +1371,10 @@ func TestIBFT_FutureProposal(t *testing.T) {
 	proposalHash := []byte("proposal hash")
 	quorum := uint64(4)
 
-	generateEmptyRCMessages := func(count uint64) []*proto.Message {
+	generateEmptyRCMessages := func(count, round uint64) []*proto.Message {
 		// Generate random RC messages
-		roundChangeMessages := generateMessages(count, proto.MessageType_ROUND_CHANGE)
+		roundChangeMessages := generateMessagesWithUniqueSender(count, proto.MessageType_ROUND_CHANGE)
+		setRoundForMessages(roundChangeMessages, round)
 
 		// Fill up their certificates
 		for _, message := range roundChangeMessages {
@@ -1295,7 +1401,7 @@ func TestIBFT_FutureProposal(t *testing.T) {
 				Height: 0,
 				Round:  1,
 			},
-			generateEmptyRCMessages(quorum),
+			generateEmptyRCMessages(quorum, 1),
 			1,
 		},
 		{
@@ -1304,7 +1410,7 @@ func TestIBFT_FutureProposal(t *testing.T) {
 				Height: 0,
 				Round:  2,
 			},
-			generateFilledRCMessages(quorum, proposal, proposalHash),
+			generateFilledRCMessages(quorum, 2, proposal, proposalHash),
 			2,
 		},
 	}
@@ -1773,6 +1879,143 @@ func TestIBFT_ValidPC(t *testing.T) {
 		assert.False(t, i.validPC(certificate, rLimit, 0))
 	})
 
+	t.Run("proposal is from an invalid sender", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			quorum       = uint64(4)
+			rLimit       = uint64(1)
+			sender       = []byte("unique node")
+			proposalHash = []byte("proposal hash")
+
+			log       = mockLogger{}
+			transport = mockTransport{}
+			backend   = mockBackend{
+				quorumFn: func(_ uint64) uint64 {
+					return quorum
+				},
+				isProposerFn: func(proposer []byte, _ uint64, _ uint64) bool {
+					return bytes.Equal(proposer, sender)
+				},
+				isValidSenderFn: func(message *proto.Message) bool {
+					// The proposal message will be invalid
+					return !bytes.Equal(message.From, sender)
+				},
+			}
+		)
+
+		i := NewIBFT(log, backend, transport)
+
+		proposal := generateMessagesWithSender(1, proto.MessageType_PREPREPARE, sender)[0]
+
+		certificate := &proto.PreparedCertificate{
+			ProposalMessage: proposal,
+			PrepareMessages: generateMessagesWithUniqueSender(quorum-1, proto.MessageType_PREPARE),
+		}
+
+		// Make sure they all have the same proposal hash
+		allMessages := append([]*proto.Message{certificate.ProposalMessage}, certificate.PrepareMessages...)
+		appendProposalHash(
+			allMessages,
+			proposalHash,
+		)
+
+		setRoundForMessages(allMessages, rLimit-1)
+
+		assert.False(t, i.validPC(certificate, rLimit, 0))
+	})
+
+	t.Run("prepare is from a different round", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			quorum       = uint64(4)
+			rLimit       = uint64(2)
+			sender       = []byte("unique node")
+			proposalHash = []byte("proposal hash")
+
+			log       = mockLogger{}
+			transport = mockTransport{}
+			backend   = mockBackend{
+				quorumFn: func(_ uint64) uint64 {
+					return quorum
+				},
+				isProposerFn: func(proposer []byte, _ uint64, _ uint64) bool {
+					return bytes.Equal(proposer, sender)
+				},
+			}
+		)
+
+		i := NewIBFT(log, backend, transport)
+
+		proposal := generateMessagesWithSender(1, proto.MessageType_PREPREPARE, sender)[0]
+
+		certificate := &proto.PreparedCertificate{
+			ProposalMessage: proposal,
+			PrepareMessages: generateMessagesWithUniqueSender(quorum-1, proto.MessageType_PREPARE),
+		}
+
+		// Make sure they all have the same proposal hash
+		allMessages := append([]*proto.Message{certificate.ProposalMessage}, certificate.PrepareMessages...)
+		appendProposalHash(
+			allMessages,
+			proposalHash,
+		)
+
+		setRoundForMessages(allMessages, rLimit-1)
+
+		// Make sure one of the prepares is from another round below the limit
+		certificate.PrepareMessages[0].View.Round = rLimit - 2
+
+		assert.False(t, i.validPC(certificate, rLimit, 0))
+	})
+
+	t.Run("prepare has no view", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			quorum       = uint64(4)
+			rLimit       = uint64(2)
+			sender       = []byte("unique node")
+			proposalHash = []byte("proposal hash")
+
+			log       = mockLogger{}
+			transport = mockTransport{}
+			backend   = mockBackend{
+				quorumFn: func(_ uint64) uint64 {
+					return quorum
+				},
+				isProposerFn: func(proposer []byte, _ uint64, _ uint64) bool {
+					return bytes.Equal(proposer, sender)
+				},
+			}
+		)
+
+		i := NewIBFT(log, backend, transport)
+
+		proposal := generateMessagesWithSender(1, proto.MessageType_PREPREPARE, sender)[0]
+
+		certificate := &proto.PreparedCertificate{
+			ProposalMessage: proposal,
+			PrepareMessages: generateMessagesWithUniqueSender(quorum-1, proto.MessageType_PREPARE),
+		}
+
+		allMessages := append([]*proto.Message{certificate.ProposalMessage}, certificate.PrepareMessages...)
+		appendProposalHash(
+			allMessages,
+			proposalHash,
+		)
+
+		setRoundForMessages(allMessages, rLimit-1)
+
+		// Make sure a network supplied message without a view is rejected
+		certificate.PrepareMessages[0].View = nil
+
+		assert.NotPanics(t, func() {
+			assert.False(t, i.validPC(certificate, rLimit, 0))
+		})
+	})
+
 	t.Run("completely valid PC", func(t *testing.T) {
 		t.Parallel()
 
@@ -2074,6 +2317,154 @@ func TestIBFT_ValidateProposal(t *testing.T) {
 
 		assert.False(t, i.validateProposal(proposal, baseView))
 	})
+
+	t.Run("round change certificate is not valid", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			quorum       = uint64(4)
+			proposer     = []byte("proposer")
+			proposal     = []byte("proposal")
+			proposalHash = []byte("proposal hash")
+			baseView     = &proto.View{
+				Height: 0,
+				Round:  2,
+			}
+		)
+
+		// prepared builds the round change messages for the base view,
+		// with a valid PC for the proposal hash prepared in the round
+		prepared := func(round uint64, hash []byte) []*proto.Message {
+			roundChangeMessages := generateFilledRCMessages(quorum, baseView.Round, proposal, hash)
+
+			for _, message := range roundChangeMessages {
+				certificate := messages.ExtractLatestPC(message)
+				setRoundForMessages(
+					append([]*proto.Message{certificate.ProposalMessage}, certificate.PrepareMessages...),
+					round,
+				)
+			}
+
+			return roundChangeMessages
+		}
+
+		testTable := []struct {
+			name                string
+			roundChangeMessages func() []*proto.Message
+			valid               bool
+		}{
+			{
+				"valid certificate",
+				func() []*proto.Message {
+					return prepared(1, proposalHash)
+				},
+				true,
+			},
+			{
+				"round change is from a lower round",
+				func() []*proto.Message {
+					roundChangeMessages := prepared(1, proposalHash)
+					roundChangeMessages[0].View.Round = baseView.Round - 1
+
+					return roundChangeMessages
+				},
+				false,
+			},
+			{
+				"round change is from a different height",
+				func() []*proto.Message {
+					roundChangeMessages := prepared(1, proposalHash)
+					roundChangeMessages[0].View.Height = baseView.Height + 1
+
+					return roundChangeMessages
+				},
+				false,
+			},
+			{
+				"round change sender is not a validator",
+				func() []*proto.Message {
+					roundChangeMessages := prepared(1, proposalHash)
+					roundChangeMessages[0].From = []byte("not a validator")
+
+					return roundChangeMessages
+				},
+				false,
+			},
+			{
+				"round change senders are not unique",
+				func() []*proto.Message {
+					roundChangeMessages := prepared(1, proposalHash)
+					roundChangeMessages[0].From = roundChangeMessages[1].From
+
+					return roundChangeMessages
+				},
+				false,
+			},
+			{
+				"proposal is not the highest prepared proposal",
+				func() []*proto.Message {
+					roundChangeMessages := prepared(0, proposalHash)
+					roundChangeMessages[quorum-1] = prepared(1, []byte("other hash"))[quorum-1]
+
+					return roundChangeMessages
+				},
+				false,
+			},
+			{
+				"proposal is the highest prepared proposal",
+				func() []*proto.Message {
+					roundChangeMessages := prepared(0, []byte("other hash"))
+					roundChangeMessages[quorum-1] = prepared(1, proposalHash)[quorum-1]
+
+					return roundChangeMessages
+				},
+				true,
+			},
+		}
+
+		for _, testCase := range testTable {
+			testCase := testCase
+
+			t.Run(testCase.name, func(t *testing.T) {
+				t.Parallel()
+
+				var (
+					log     = mockLogger{}
+					backend = mockBackend{
+						quorumFn: func(_ uint64) uint64 {
+							return quorum
+						},
+						isProposerFn: func(id []byte, _ uint64, _ uint64) bool {
+							return bytes.Equal(id, proposer) || bytes.Equal(id, []byte("unique node"))
+						},
+						isValidSenderFn: func(message *proto.Message) bool {
+							return !bytes.Equal(message.From, []byte("not a validator"))
+						},
+					}
+					transport = mockTransport{}
+				)
+
+				i := NewIBFT(log, backend, transport)
+
+				message := &proto.Message{
+					View: baseView,
+					From: proposer,
+					Type: proto.MessageType_PREPREPARE,
+					Payload: &proto.Message_PreprepareData{
+						PreprepareData: &proto.PrePrepareMessage{
+							Proposal:     proposal,
+							ProposalHash: proposalHash,
+							Certificate: &proto.RoundChangeCertificate{
+								RoundChangeMessages: testCase.roundChangeMessages(),
+							},
+						},
+					},
+				}
+
+				assert.Equal(t, testCase.valid, i.validateProposal(message, baseView))
+			})
+		}
+	})
 }
 
 // TestIBFT_WatchForFutureRCC verifies that future RCC
@@ -2086,8 +2477,7 @@ func TestIBFT_WatchForFutureRCC(t *testing.T) {
 	rccRound := uint64(10)
 	proposalHash := []byte("proposal hash")
 
-	roundChangeMessages := generateFilledRCMessages(quorum, proposal, proposalHash)
-	setRoundForMessages(roundChangeMessages, rccRound)
+	roundChangeMessages := generateFilledRCMessages(quorum, rccRound, proposal, proposalHash)
 
 	var (
 		receivedRound = uint64(0)
@@ -2306,3 +2696,946 @@ func TestIBFT_ExtendRoundTimer(t *testing.T) {
 	// Make sure the round timeout was extended
 	assert.Equal(t, additionalTimeout, i.additionalTimeout)
 }
+
+// TestIBFT_PersistState makes sure the state is persisted
+// before messages are multicasted
+func TestIBFT_PersistState(t *testing.T) {
+	t.Parallel()
+
+	view := &proto.View{
+		Height: 1,
+		Round:  2,
+	}
+
+	proposalMessage := &proto.Message{
+		View: view,
+		Type: proto.MessageType_PREPREPARE,
+		Payload: &proto.Message_PreprepareData{
+			PreprepareData: &proto.PrePrepareMessage{
+				Proposal:     []byte("proposal"),
+				ProposalHash: []byte("proposal hash"),
+			},
+		},
+	}
+
+	testTable := []struct {
+		name string
+		send func(i *IBFT) error
+	}{
+		{
+			"preprepare message",
+			func(i *IBFT) error {
+				return i.sendPreprepareMessage(proposalMessage)
+			},
+		},
+		{
+			"prepare message",
+			func(i *IBFT) error {
+				return i.sendPrepareMessage(view)
+			},
+		},
+		{
+			"commit message",
+			func(i *IBFT) error {
+				return i.sendCommitMessage(view)
+			},
+		},
+		{
+			"round change message",
+			func(i *IBFT) error {
+				return i.sendRoundChangeMessage(view.Height, view.Round)
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				events    []string
+				persisted *StateSnapshot
+
+				log     = mockLogger{}
+				backend = mockBackend{
+					buildPrepareMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+						return &proto.Message{View: view, Type: proto.MessageType_PREPARE}
+					},
+					buildCommitMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+						return &proto.Message{View: view, Type: proto.MessageType_COMMIT}
+					},
+				}
+				transport = mockTransport{
+					multicastFn: func(_ *proto.Message) {
+						events = append(events, "multicast")
+					},
+				}
+				store = mockStateStore{
+					saveFn: func(snapshot *StateSnapshot) error {
+						events = append(events, "save")
+						persisted = snapshot
+
+						return nil
+					},
+				}
+			)
+
+			i := NewIBFT(log, backend, transport, WithStateStore(store))
+			i.state.setView(view)
+			i.state.setProposalMessage(proposalMessage)
+
+			require.NoError(t, testCase.send(i))
+
+			// Make sure the state was persisted before the multicast
+			assert.Equal(t, []string{"save", "multicast"}, events)
+
+			// Make sure the correct state was persisted
+			assert.Equal(t, view.Height, persisted.View.Height)
+			assert.Equal(t, view.Round, persisted.View.Round)
+			assert.Equal(t, proposalMessage, persisted.ProposalMessage)
+		})
+	}
+
+	t.Run("message is not multicasted if the state is not persisted", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			multicasted = false
+
+			log       = mockLogger{}
+			backend   = mockBackend{}
+			transport = mockTransport{
+				multicastFn: func(_ *proto.Message) {
+					multicasted = true
+				},
+			}
+			store = mockStateStore{
+				saveFn: func(_ *StateSnapshot) error {
+					return errors.New("disk failure")
+				},
+			}
+		)
+
+		i := NewIBFT(log, backend, transport, WithStateStore(store))
+		i.state.setView(view)
+
+		err := i.sendRoundChangeMessage(view.Height, view.Round)
+
+		// Make sure nothing was multicasted, and the failure is reported
+		assert.False(t, multicasted)
+		assert.ErrorIs(t, err, ErrPersistState)
+	})
+}
+
+// TestIBFT_PersistStateFailure makes sure the sequence is stopped
+// if the state can't be persisted, rather than moving on
+// without sending out the vote
+func TestIBFT_PersistStateFailure(t *testing.T) {
+	t.Parallel()
+
+	var (
+		multicasted = false
+
+		log     = mockLogger{}
+		backend = mockBackend{
+			isProposerFn: func(_ []byte, _ uint64, _ uint64) bool {
+				return true
+			},
+			buildProposalFn: func(_ uint64) []byte {
+				return []byte("proposal")
+			},
+			buildPrePrepareMessageFn: func(
+				proposal []byte,
+				_ *proto.RoundChangeCertificate,
+				view *proto.View,
+			) *proto.Message {
+				return &proto.Message{
+					View: view,
+					Type: proto.MessageType_PREPREPARE,
+					Payload: &proto.Message_PreprepareData{
+						PreprepareData: &proto.PrePrepareMessage{
+							Proposal: proposal,
+						},
+					},
+				}
+			},
+		}
+		transport = mockTransport{
+			multicastFn: func(_ *proto.Message) {
+				multicasted = true
+			},
+		}
+		store = mockStateStore{
+			saveFn: func(_ *StateSnapshot) error {
+				return errors.New("disk failure")
+			},
+		}
+	)
+
+	i := NewIBFT(log, backend, transport, WithStateStore(store))
+
+	result, err := i.RunSequence(context.Background(), 1)
+
+	// Make sure the sequence is stopped, without sending out the proposal
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrPersistState)
+	assert.False(t, multicasted)
+}
+
+// TestIBFT_RestoreState makes sure the persisted
+// state is restored when a sequence is started
+func TestIBFT_RestoreState(t *testing.T) {
+	t.Parallel()
+
+	var (
+		height uint64 = 5
+		round  uint64 = 3
+
+		proposal        = []byte("proposal")
+		proposalMessage = &proto.Message{
+			View: &proto.View{
+				Height: height,
+				Round:  round,
+			},
+			Type: proto.MessageType_PREPREPARE,
+			Payload: &proto.Message_PreprepareData{
+				PreprepareData: &proto.PrePrepareMessage{
+					Proposal: proposal,
+				},
+			},
+		}
+		latestPC = &proto.PreparedCertificate{
+			ProposalMessage: &proto.Message{
+				View: &proto.View{
+					Height: height,
+					Round:  round - 1,
+				},
+				Type: proto.MessageType_PREPREPARE,
+			},
+		}
+	)
+
+	t.Run("state is restored for the same height", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			log       = mockLogger{}
+			backend   = mockBackend{}
+			transport = mockTransport{}
+			store     = mockStateStore{
+				loadFn: func() (*StateSnapshot, error) {
+					return &StateSnapshot{
+						View: &proto.View{
+							Height: height,
+							Round:  round,
+						},
+						LatestPC:                    latestPC,
+						LatestPreparedProposedBlock: proposal,
+						ProposalMessage:             proposalMessage,
+					}, nil
+				},
+			}
+		)
+
+		i := NewIBFT(log, backend, transport, WithStateStore(store))
+
+		i.state.clear(height)
+		i.restoreState(height)
+
+		// Make sure the view is restored
+		assert.Equal(t, height, i.state.getHeight())
+		assert.Equal(t, round, i.state.getRound())
+
+		// Make sure the lock is restored
+		assert.Equal(t, latestPC, i.state.getLatestPC())
+		assert.Equal(t, proposal, i.state.getLatestPreparedProposedBlock())
+
+		// Make sure the accepted proposal is restored
+		assert.Equal(t, proposalMessage, i.state.getProposalMessage())
+		assert.Equal(t, prepare, i.state.getStateName())
+		assert.True(t, i.state.roundStarted)
+	})
+
+	t.Run("prepared state is restored in commit", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			log       = mockLogger{}
+			backend   = mockBackend{}
+			transport = mockTransport{}
+			store     = mockStateStore{
+				loadFn: func() (*StateSnapshot, error) {
+					return &StateSnapshot{
+						View: &proto.View{
+							Height: height,
+							Round:  round,
+						},
+						LatestPC: &proto.PreparedCertificate{
+							ProposalMessage: proposalMessage,
+						},
+						LatestPreparedProposedBlock: proposal,
+						ProposalMessage:             proposalMessage,
+					}, nil
+				},
+			}
+		)
+
+		i := NewIBFT(log, backend, transport, WithStateStore(store))
+
+		i.state.clear(height)
+		i.restoreState(height)
+
+		// Make sure the COMMIT is not waited for again in the prepare state
+		assert.Equal(t, commit, i.state.getStateName())
+		assert.True(t, i.state.roundStarted)
+	})
+
+	t.Run("state is not restored for a different height", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			log       = mockLogger{}
+			backend   = mockBackend{}
+			transport = mockTransport{}
+			store     = mockStateStore{
+				loadFn: func() (*StateSnapshot, error) {
+					return &StateSnapshot{
+						View: &proto.View{
+							Height: height - 1,
+							Round:  round,
+						},
+						LatestPC: latestPC,
+					}, nil
+				},
+			}
+		)
+
+		i := NewIBFT(log, backend, transport, WithStateStore(store))
+
+		i.state.clear(height)
+		i.restoreState(height)
+
+		// Make sure the state is untouched
+		assert.Equal(t, uint64(0), i.state.getRound())
+		assert.Nil(t, i.state.getLatestPC())
+		assert.Equal(t, newRound, i.state.getStateName())
+	})
+
+	t.Run("restored proposal is not built again", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+
+		var (
+			proposalBuilt = false
+
+			log     = mockLogger{}
+			backend = mockBackend{
+				isProposerFn: func(_ []byte, _ uint64, _ uint64) bool {
+					return true
+				},
+				buildProposalFn: func(_ uint64) []byte {
+					proposalBuilt = true
+
+					return nil
+				},
+			}
+			transport = mockTransport{}
+			store     = mockStateStore{
+				loadFn: func() (*StateSnapshot, error) {
+					return &StateSnapshot{
+						View: &proto.View{
+							Height: height,
+							Round:  0,
+						},
+						ProposalMessage: proposalMessage,
+					}, nil
+				},
+			}
+		)
+
+		i := NewIBFT(log, backend, transport, WithStateStore(store))
+		i.messages = mockMessages{
+			subscribeFn: func(_ messages.SubscriptionDetails) *messages.Subscription {
+				cancelFn()
+
+				return &messages.Subscription{
+					SubCh: make(chan uint64),
+				}
+			},
+		}
+
+		i.state.clear(height)
+		i.restoreState(height)
+
+		i.wg.Add(1)
+		i.startRound(ctx)
+
+		// Make sure the proposal was not rebuilt
+		assert.False(t, proposalBuilt)
+		assert.Equal(t, proposalMessage, i.state.getProposalMessage())
+	})
+}
+
+// TestIBFT_RestoreState_CrashBeforeSend makes sure a node that stopped
+// after persisting its state, but before sending the message the state
+// was persisted for, sends the same message once restarted
+func TestIBFT_RestoreState_CrashBeforeSend(t *testing.T) {
+	t.Parallel()
+
+	var (
+		height uint64 = 5
+		round  uint64 = 2
+
+		id           = []byte("node")
+		proposer     = []byte("proposer")
+		proposal     = []byte("proposal")
+		proposalHash = []byte("proposal hash")
+		view         = &proto.View{
+			Height: height,
+			Round:  round,
+		}
+	)
+
+	proposalFrom := func(from []byte) *proto.Message {
+		return buildBasicPreprepareMessage(proposal, proposalHash, nil, from, view)
+	}
+
+	testTable := []struct {
+		name          string
+		expectedType  proto.MessageType
+		expectedState stateType
+		persist       func(i *IBFT) error
+	}{
+		{
+			"proposer crashed before sending the PREPREPARE",
+			proto.MessageType_PREPREPARE,
+			prepare,
+			func(i *IBFT) error {
+				i.acceptProposal(proposalFrom(id))
+				return i.sendPreprepareMessage(i.state.getProposalMessage())
+			},
+		},
+		{
+			"validator crashed before sending the PREPARE",
+			proto.MessageType_PREPARE,
+			prepare,
+			func(i *IBFT) error {
+				i.acceptProposal(proposalFrom(proposer))
+				return i.sendPrepareMessage(view)
+			},
+		},
+		{
+			"validator crashed before sending the COMMIT",
+			proto.MessageType_COMMIT,
+			commit,
+			func(i *IBFT) error {
+				i.acceptProposal(proposalFrom(proposer))
+				i.state.finalizePrepare(
+					&proto.PreparedCertificate{
+						ProposalMessage: proposalFrom(proposer),
+						PrepareMessages: []*proto.Message{
+							buildBasicPrepareMessage(proposalHash, id, view),
+						},
+					},
+					proposal,
+				)
+				return i.sendCommitMessage(view)
+			},
+		},
+		{
+			"validator crashed before sending the ROUND_CHANGE",
+			proto.MessageType_ROUND_CHANGE,
+			newRound,
+			func(i *IBFT) error {
+				i.moveToNewRound(round)
+				return i.sendRoundChangeMessage(height, round)
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				saved *StateSnapshot
+				lost  []*proto.Message
+				sent  []*proto.Message
+
+				backend = mockBackend{
+					idFn: func() []byte {
+						return id
+					},
+					buildPrepareMessageFn: func(hash []byte, view *proto.View) *proto.Message {
+						return buildBasicPrepareMessage(hash, id, view)
+					},
+					buildCommitMessageFn: func(hash []byte, view *proto.View) *proto.Message {
+						return buildBasicCommitMessage(hash, []byte("seal"), id, view)
+					},
+					buildRoundChangeMessageFn: func(
+						proposal []byte,
+						certificate *proto.PreparedCertificate,
+						view *proto.View,
+					) *proto.Message {
+						return buildBasicRoundChangeMessage(proposal, certificate, view, id)
+					},
+				}
+				store = mockStateStore{
+					saveFn: func(snapshot *StateSnapshot) error {
+						saved = snapshot
+
+						return nil
+					},
+					loadFn: func() (*StateSnapshot, error) {
+						return saved, nil
+					},
+				}
+			)
+
+			// The crashed node persists the state, but its messages are lost
+			crashed := NewIBFT(mockLogger{}, backend, mockTransport{
+				multicastFn: func(message *proto.Message) {
+					lost = append(lost, message)
+				},
+			}, WithStateStore(store))
+
+			crashed.state.clear(height)
+			crashed.state.setView(view)
+			require.NoError(t, testCase.persist(crashed))
+
+			require.NotNil(t, saved)
+			require.Len(t, lost, 1)
+
+			// The restarted node resumes from the persisted state
+			restarted := NewIBFT(mockLogger{}, backend, mockTransport{
+				multicastFn: func(message *proto.Message) {
+					sent = append(sent, message)
+				},
+			}, WithStateStore(store))
+
+			restarted.state.clear(height)
+			restarted.restoreState(height)
+
+			assert.Equal(t, testCase.expectedState, restarted.state.getStateName())
+
+			// Make sure the lost message is sent again
+			if assert.Len(t, sent, 1) {
+				assert.Equal(t, testCase.expectedType, sent[0].Type)
+				assert.Equal(t, lost[0], sent[0])
+			}
+		})
+	}
+}
+
+// TestIBFT_WatchForFutureRoundChanges makes sure F+1 valid round change
+// messages for higher rounds trigger a round hop
+func TestIBFT_WatchForFutureRoundChanges(t *testing.T) {
+	t.Parallel()
+
+	var (
+		quorum          = uint64(4)
+		maxFaulty       = uint64(1)
+		lowestRound     = uint64(3)
+		highestRound    = uint64(7)
+		proposal        = []byte("proposal")
+		proposalHash    = []byte("proposal hash")
+		duplicateSender = []byte("duplicate sender")
+	)
+
+	// generateRoundChanges generates round change messages from unique senders,
+	// with the specified rounds
+	generateRoundChanges := func(rounds ...uint64) []*proto.Message {
+		msgs := generateMessagesWithUniqueSender(uint64(len(rounds)), proto.MessageType_ROUND_CHANGE)
+
+		for index, msg := range msgs {
+			msg.View.Round = rounds[index]
+		}
+
+		return msgs
+	}
+
+	testTable := []struct {
+		name           string
+		messages       []*proto.Message
+		shouldJump     bool
+		expectedRound  uint64
+		prepareInvalid bool
+	}{
+		{
+			"F+1 round changes for higher rounds",
+			generateRoundChanges(highestRound, lowestRound),
+			true,
+			lowestRound,
+			false,
+		},
+		{
+			"not enough round changes for higher rounds",
+			generateRoundChanges(highestRound),
+			false,
+			0,
+			false,
+		},
+		{
+			"round changes with invalid certificates are ignored",
+			generateRoundChanges(highestRound, lowestRound),
+			false,
+			0,
+			true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			if testCase.prepareInvalid {
+				// Attach a certificate with prepare messages
+				// from a duplicate sender to one of the messages
+				certificate := &proto.PreparedCertificate{
+					ProposalMessage: &proto.Message{
+						View: &proto.View{Height: 0, Round: 0},
+						From: []byte("proposer"),
+						Type: proto.MessageType_PREPREPARE,
+						Payload: &proto.Message_PreprepareData{
+							PreprepareData: &proto.PrePrepareMessage{
+								ProposalHash: proposalHash,
+							},
+						},
+					},
+					PrepareMessages: generateMessagesWithSender(quorum-1, proto.MessageType_PREPARE, duplicateSender),
+				}
+
+				appendProposalHash(certificate.PrepareMessages, proposalHash)
+
+				rcData, _ := testCase.messages[0].Payload.(*proto.Message_RoundChangeData)
+				rcData.RoundChangeData.LatestPreparedCertificate = certificate
+				rcData.RoundChangeData.LastPreparedProposedBlock = proposal
+			}
+
+			var (
+				receivedRound = uint64(0)
+				jumped        = false
+				notifyCh      = make(chan uint64, 1)
+
+				log       = mockLogger{}
+				transport = mockTransport{}
+				backend   = mockBackend{
+					quorumFn: func(_ uint64) uint64 {
+						return quorum
+					},
+					maximumFaultyNodesFn: func() uint64 {
+						return maxFaulty
+					},
+				}
+				messages = mockMessages{
+					subscribeFn: func(_ messages.SubscriptionDetails) *messages.Subscription {
+						return &messages.Subscription{
+							ID:    messages.SubscriptionID(1),
+							SubCh: notifyCh,
+						}
+					},
+					getHighestRoundChangeMessagesFn: func(minRound, _ uint64) []*proto.Message {
+						return filterMessages(testCase.messages, func(message *proto.Message) bool {
+							return message.View.Round >= minRound
+						})
+					},
+				}
+			)
+
+			i := NewIBFT(log, backend, transport)
+			i.messages = messages
+
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+
+			doneCh := make(chan struct{})
+
+			go func() {
+				defer func() {
+					cancelFn()
+					close(doneCh)
+				}()
+
+				select {
+				case r := <-i.futureRoundChange:
+					receivedRound = r
+					jumped = true
+				case <-time.After(time.Second):
+				}
+			}()
+
+			// Have the notification waiting
+			notifyCh <- highestRound
+
+			i.wg.Add(1)
+			i.watchForRoundChangeCertificates(ctx)
+			i.wg.Wait()
+
+			<-doneCh
+
+			// Make sure the round hop is correct
+			assert.Equal(t, testCase.shouldJump, jumped)
+			assert.Equal(t, testCase.expectedRound, receivedRound)
+		})
+	}
+}
+
+// TestIBFT_RunSequence_FutureRoundChange verifies that the state changes
+// correctly when receiving F+1 round changes for a higher round
+func TestIBFT_RunSequence_FutureRoundChange(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	var (
+		round  = uint64(4)
+		height = uint64(1)
+
+		multicastedRoundChange *proto.Message
+
+		log       = mockLogger{}
+		backend   = mockBackend{}
+		transport = mockTransport{
+			multicastFn: func(message *proto.Message) {
+				if message.Type == proto.MessageType_ROUND_CHANGE {
+					multicastedRoundChange = message
+				}
+			},
+		}
+	)
+
+	i := NewIBFT(log, backend, transport)
+	i.futureRoundChange = make(chan uint64, 1)
+
+	// Make sure the round event is waiting
+	i.futureRoundChange <- round
+
+	// Spawn a go-routine that's going to turn off the sequence after 1s
+	go func() {
+		defer cancelFn()
+
+		<-time.After(1 * time.Second)
+	}()
+
+	i.RunSequence(ctx, height)
+
+	// Make sure the correct round was moved to
+	assert.Equal(t, round, i.state.view.Round)
+	assert.Equal(t, height, i.state.view.Height)
+
+	// Make sure the round change message for the new round was multicasted
+	if assert.NotNil(t, multicastedRoundChange) {
+		assert.Equal(t, round, multicastedRoundChange.View.Round)
+		assert.Equal(t, height, multicastedRoundChange.View.Height)
+	}
+}
+
+// TestIBFT_RunSequence_Result makes sure the sequence
+// outcome is reported correctly
+func TestIBFT_RunSequence_Result(t *testing.T) {
+	t.Parallel()
+
+	var (
+		height   = uint64(3)
+		round    = uint64(2)
+		proposal = []byte("proposal")
+		seals    = []*messages.CommittedSeal{
+			{
+				Signer:    []byte("signer"),
+				Signature: []byte("signature"),
+			},
+		}
+		view = &proto.View{
+			Height: height,
+			Round:  round,
+		}
+	)
+
+	t.Run("proposal is finalized", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			log       = mockLogger{}
+			backend   = mockBackend{}
+			transport = mockTransport{}
+		)
+
+		i := NewIBFT(log, backend, transport)
+		i.roundCertificate = make(chan uint64, 1)
+		i.roundCertificate <- round
+
+		// Make sure the sequence is finalized
+		// once the node moves to the round
+		finalizer := newMockFinalizer(i, proposal, seals)
+		go finalizer.finalize(view)
+
+		result, err := i.RunSequence(context.Background(), height)
+
+		assert.NoError(t, err)
+
+		// Make sure the result is correct
+		if assert.NotNil(t, result) {
+			assert.Equal(t, height, result.Height)
+			assert.Equal(t, round, result.Round)
+			assert.Equal(t, proposal, result.Proposal)
+			assert.Equal(t, seals, result.CommittedSeals)
+			assert.Equal(t, uint64(1), result.RoundChanges)
+			assert.True(t, result.Duration > 0)
+
+			// Make sure the commit certificate is correct
+			assert.Equal(t, height, result.Certificate.GetView().GetHeight())
+			assert.Equal(t, round, result.Certificate.GetView().GetRound())
+			assert.Len(t, result.Certificate.GetCommittedSeals().GetSeals(), len(seals))
+		}
+	})
+
+	t.Run("proposal insertion fails", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			insertErr = errors.New("invalid state root")
+
+			log     = mockLogger{}
+			backend = mockCheckedInsertBackend{
+				insertBlockWithErrorFn: func(_ []byte, _ []*messages.CommittedSeal) error {
+					return insertErr
+				},
+			}
+			transport = mockTransport{}
+		)
+
+		i := NewIBFT(log, backend, transport)
+		i.roundCertificate = make(chan uint64, 1)
+		i.roundCertificate <- round
+
+		// Make sure the sequence is finalized
+		// once the node moves to the round
+		finalizer := newMockFinalizer(i, proposal, seals)
+		go finalizer.finalize(view)
+
+		result, err := i.RunSequence(context.Background(), height)
+
+		// Make sure the error is a block insertion error
+		assert.ErrorIs(t, err, ErrInsertBlock)
+		assert.ErrorIs(t, err, insertErr)
+		assert.NotErrorIs(t, err, context.Canceled)
+
+		// Make sure the finalization result is still present
+		if assert.NotNil(t, result) {
+			assert.Equal(t, proposal, result.Proposal)
+		}
+	})
+
+	t.Run("sequence is cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			log       = mockLogger{}
+			backend   = mockBackend{}
+			transport = mockTransport{}
+		)
+
+		i := NewIBFT(log, backend, transport)
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+		cancelFn()
+
+		result, err := i.RunSequence(ctx, height)
+
+		// Make sure the cancellation is reported
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, ErrInsertBlock)
+	})
+}
+
+// TestIBFT_StateDurations makes sure the time spent
+// in each state is tracked
+func TestIBFT_StateDurations(t *testing.T) {
+	t.Parallel()
+
+	var (
+		log        = mockLogger{}
+		backend    = mockBackend{}
+		transport  = mockTransport{}
+		clock      = newMockClock()
+		subscribed = make(chan struct{})
+	)
+
+	i := NewIBFT(log, backend, transport, WithClock(clock))
+	i.state.name = prepare
+	i.state.roundStarted = true
+	i.messages = mockMessages{
+		subscribeFn: func(_ messages.SubscriptionDetails) *messages.Subscription {
+			close(subscribed)
+
+			return &messages.Subscription{
+				SubCh: make(chan uint64),
+			}
+		},
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	go func() {
+		// Spend virtual time in the prepare state
+		<-subscribed
+		clock.Advance(3 * time.Second)
+		cancelFn()
+	}()
+
+	i.runStates(ctx)
+
+	// Make sure the prepare state duration was tracked
+	durations := i.state.getDurations()
+
+	assert.Equal(t, 3*time.Second, durations.Prepare)
+	assert.Zero(t, durations.NewRound)
+	assert.Zero(t, durations.Commit)
+}
+
+// TestIBFT_EquivocationHandler makes sure the conflicting
+// messages are reported to the configured handler
+func TestIBFT_EquivocationHandler(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view     = &proto.View{Height: 1, Round: 0}
+		sender   = []byte("node 1")
+		first    = buildBasicPrepareMessage([]byte("hash 1"), sender, view)
+		second   = buildBasicPrepareMessage([]byte("hash 2"), sender, view)
+		detected = make([]messages.Equivocation, 0)
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{},
+		mockTransport{},
+		WithEquivocationHandler(func(equivocation messages.Equivocation) {
+			detected = append(detected, equivocation)
+		}),
+	)
+
+	i.AddMessage(first)
+	i.AddMessage(second)
+
+	assert.Equal(
+		t,
+		[]messages.Equivocation{
+			{
+				First:  first,
+				Second: second,
+			},
+		},
+		detected,
+	)
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// MessageLimits bounds the messages kept by the node,
+// so a single peer cannot fill its memory. Zero values disable the limits
+type MessageLimits struct {
+	// FutureHeights is how many heights ahead of the current
+	// height the messages are accepted. Falling behind by more
+	// than it is not detected from the messages (see Syncer)
+	FutureHeights uint64
+
+	// FutureRounds is how many rounds ahead of the current round
+	// (or round 0, for future heights) the messages are accepted
+	FutureRounds uint64
+
+	// SenderQuota is the maximum number of messages of each
+	// type kept for a single sender, across all views
+	SenderQuota int
+
+	// MemoryLimit is the maximum summed (encoded) size
+	// of the kept messages, in bytes
+	MemoryLimit int
+}
+
+// checkFutureLimits checks if the message is within
+// the future view limits, relative to the current view
+func (i *IBFT) checkFutureLimits(view *proto.View) error {
+	var (
+		currentView = i.state.getView()
+		baseRound   = uint64(0)
+	)
+
+	if limit := i.messageLimits.FutureHeights; limit > 0 && view.Height > currentView.Height+limit {
+		return ErrFutureHeight
+	}
+
+	if view.Height == currentView.Height {
+		baseRound = currentView.Round
+	}
+
+	if limit := i.messageLimits.FutureRounds; limit > 0 && view.Round > baseRound+limit {
+		return ErrFutureRound
+	}
+
+	return nil
+}
+
+// newMessages creates the message store, bounded by the message limits
+func (i *IBFT) newMessages() *messages.Messages {
+	return messages.NewMessages(
+		messages.WithEquivocationHandler(i.equivocationHandler),
+		messages.WithSenderQuota(i.messageLimits.SenderQuota),
+		messages.WithMemoryLimit(i.messageLimits.MemoryLimit),
+		messages.WithDropHandler(i.onMessageDropped),
+	)
+}
+
+// onMessageDropped logs and records the message dropped from the message store
+func (i *IBFT) onMessageDropped(message *proto.Message, reason messages.DropReason) {
+	i.observeDroppedMessage(reason)
+	i.log.Debug(
+		"message dropped",
+		"reason", reason.String(),
+		"type", message.Type,
+		"height", message.View.Height,
+		"round", message.View.Round,
+	)
+}
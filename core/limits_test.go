@@ -0,0 +1,117 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIBFT_FutureMessageLimits(t *testing.T) {
+	t.Parallel()
+
+	currentView := &proto.View{
+		Height: 10,
+		Round:  2,
+	}
+
+	testTable := []struct {
+		name        string
+		view        *proto.View
+		expectedErr error
+	}{
+		{
+			"current view",
+			currentView,
+			nil,
+		},
+		{
+			"furthest accepted round",
+			&proto.View{Height: 10, Round: 5},
+			nil,
+		},
+		{
+			"round too far ahead",
+			&proto.View{Height: 10, Round: 6},
+			ErrFutureRound,
+		},
+		{
+			"furthest accepted height",
+			&proto.View{Height: 15, Round: 3},
+			nil,
+		},
+		{
+			"height too far ahead",
+			&proto.View{Height: 16, Round: 2},
+			ErrFutureHeight,
+		},
+		{
+			"round too far ahead for a future height",
+			&proto.View{Height: 11, Round: 4},
+			ErrFutureRound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			i := NewIBFT(
+				mockLogger{},
+				mockBackend{},
+				mockTransport{},
+				WithMessageLimits(MessageLimits{
+					FutureHeights: 5,
+					FutureRounds:  3,
+				}),
+			)
+			i.state.setView(currentView)
+
+			assert.Equal(t, testCase.expectedErr, i.AddMessage(&proto.Message{
+				View: testCase.view,
+				Type: proto.MessageType_PREPARE,
+				Payload: &proto.Message_PrepareData{
+					PrepareData: &proto.PrepareMessage{},
+				},
+			}))
+		})
+	}
+}
+
+func TestIBFT_MessageLimits_Drops(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dropLogs = 0
+
+		log = mockLogger{
+			debugFn: func(msg string, _ ...interface{}) {
+				if msg == "message dropped" {
+					dropLogs++
+				}
+			},
+		}
+	)
+
+	i := NewIBFT(
+		log,
+		mockBackend{},
+		mockTransport{},
+		WithMessageLimits(MessageLimits{
+			SenderQuota: 1,
+		}),
+	)
+
+	for round := uint64(0); round < 3; round++ {
+		assert.NoError(t, i.AddMessage(buildBasicPrepareMessage(
+			[]byte("hash"),
+			[]byte("node"),
+			&proto.View{Height: 0, Round: round},
+		)))
+	}
+
+	// Make sure the drops are logged
+	assert.Equal(t, 2, dropLogs)
+}
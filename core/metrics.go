@@ -0,0 +1,143 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// Metrics records the consensus metrics. The labels
+// are passed as alternating label names and values
+type Metrics interface {
+	// IncCounter increments the counter by one
+	IncCounter(name string, labels ...string)
+
+	// SetGauge sets the gauge to the value
+	SetGauge(name string, value float64, labels ...string)
+
+	// ObserveHistogram adds the observed value to the histogram
+	ObserveHistogram(name string, value float64, labels ...string)
+}
+
+// The metrics recorded by the consensus
+const (
+	// MetricHeight is the gauge of the current sequence height
+	MetricHeight = "ibft_height"
+
+	// MetricRound is the gauge of the current round
+	MetricRound = "ibft_round"
+
+	// MetricSequenceDuration is the histogram of the finalized
+	// sequence durations, in seconds
+	MetricSequenceDuration = "ibft_sequence_duration_seconds"
+
+	// MetricRoundDuration is the histogram of the round durations, in
+	// seconds, labeled by the round outcome ("finalized", "cancelled",
+	// or the round change reason)
+	MetricRoundDuration = "ibft_round_duration_seconds"
+
+	// MetricStateDuration is the histogram of the time spent in
+	// each state of a round, in seconds, labeled by the state
+	MetricStateDuration = "ibft_state_duration_seconds"
+
+	// MetricRoundChanges is the counter of the round changes, labeled by
+	// the reason ("timeout", "future_proposal", "round_change_certificate",
+	// "future_round_changes")
+	MetricRoundChanges = "ibft_round_changes_total"
+
+	// MetricMessages is the counter of the messages passed to AddMessage,
+	// labeled by the message type, and the result ("accepted",
+	// or the rejection reason)
+	MetricMessages = "ibft_messages_total"
+
+	// MetricDroppedMessages is the counter of the accepted messages that
+	// were dropped by the message store, labeled by the reason
+	MetricDroppedMessages = "ibft_dropped_messages_total"
+)
+
+// Round outcomes and round change reasons
+const (
+	roundFinalized      = "finalized"
+	roundCancelled      = "cancelled"
+	roundTimeout        = "timeout"
+	roundFutureProposal = "future_proposal"
+	roundCertificate    = "round_change_certificate"
+	roundFutureChanges  = "future_round_changes"
+)
+
+// nopMetrics is the Metrics that records nothing
+type nopMetrics struct{}
+
+func (nopMetrics) IncCounter(_ string, _ ...string) {}
+
+func (nopMetrics) SetGauge(_ string, _ float64, _ ...string) {}
+
+func (nopMetrics) ObserveHistogram(_ string, _ float64, _ ...string) {}
+
+// observeRoundEnd records the end of the round that
+// started at the specified time, with the round outcome
+func (i *IBFT) observeRoundEnd(roundStart time.Time, outcome string) {
+	i.metrics.ObserveHistogram(
+		MetricRoundDuration,
+		i.clock.Now().Sub(roundStart).Seconds(),
+		"outcome", outcome,
+	)
+
+	if outcome != roundFinalized && outcome != roundCancelled {
+		i.metrics.IncCounter(MetricRoundChanges, "reason", outcome)
+	}
+}
+
+// observeMessage records the message passed to
+// AddMessage, with the error it was rejected with.
+// Types outside of the known set are recorded as unknown,
+// so peers can't grow the number of label values
+func (i *IBFT) observeMessage(message *proto.Message, err error) {
+	messageType := "unknown"
+	if message != nil {
+		if _, known := proto.MessageType_name[int32(message.Type)]; known {
+			messageType = message.Type.String()
+		}
+	}
+
+	i.metrics.IncCounter(MetricMessages, "type", messageType, "result", messageResult(err))
+}
+
+// messageResult returns the metric label of the AddMessage result
+func messageResult(err error) string {
+	switch {
+	case err == nil:
+		return "accepted"
+	case errors.Is(err, ErrMalformed):
+		return "malformed"
+	case errors.Is(err, ErrInvalidSender):
+		return "invalid_sender"
+	case errors.Is(err, ErrInvalidSignature):
+		return "invalid_signature"
+	case errors.Is(err, ErrNotValidator):
+		return "not_validator"
+	case errors.Is(err, ErrStaleHeight):
+		return "stale_height"
+	case errors.Is(err, ErrStaleRound):
+		return "stale_round"
+	case errors.Is(err, ErrFutureHeight):
+		return "future_height"
+	case errors.Is(err, ErrFutureRound):
+		return "future_round"
+	default:
+		return "rejected"
+	}
+}
+
+// metricLabel returns the metric label value of the name
+func metricLabel(name string) string {
+	return strings.ReplaceAll(name, " ", "_")
+}
+
+// observeDroppedMessage records the message dropped by the message store
+func (i *IBFT) observeDroppedMessage(reason messages.DropReason) {
+	i.metrics.IncCounter(MetricDroppedMessages, "reason", metricLabel(reason.String()))
+}
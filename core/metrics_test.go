@@ -0,0 +1,194 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockMetrics records the metrics, keyed by
+// the metric name and the joined labels
+type mockMetrics struct {
+	sync.Mutex
+
+	counters   map[string]int
+	gauges     map[string]float64
+	histograms map[string][]float64
+}
+
+func newMockMetrics() *mockMetrics {
+	return &mockMetrics{
+		counters:   make(map[string]int),
+		gauges:     make(map[string]float64),
+		histograms: make(map[string][]float64),
+	}
+}
+
+// metricKey returns the key of the metric with the labels
+func metricKey(name string, labels ...string) string {
+	return strings.Join(append([]string{name}, labels...), ",")
+}
+
+func (m *mockMetrics) IncCounter(name string, labels ...string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.counters[metricKey(name, labels...)]++
+}
+
+func (m *mockMetrics) SetGauge(name string, value float64, labels ...string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.gauges[metricKey(name, labels...)] = value
+}
+
+func (m *mockMetrics) ObserveHistogram(name string, value float64, labels ...string) {
+	m.Lock()
+	defer m.Unlock()
+
+	key := metricKey(name, labels...)
+	m.histograms[key] = append(m.histograms[key], value)
+}
+
+func (m *mockMetrics) counter(name string, labels ...string) int {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.counters[metricKey(name, labels...)]
+}
+
+func (m *mockMetrics) gauge(name string, labels ...string) float64 {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.gauges[metricKey(name, labels...)]
+}
+
+func (m *mockMetrics) histogram(name string, labels ...string) []float64 {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.histograms[metricKey(name, labels...)]
+}
+
+func TestIBFT_Metrics_AddMessage(t *testing.T) {
+	t.Parallel()
+
+	var (
+		metrics = newMockMetrics()
+		backend = mockBackend{
+			isValidSenderFn: func(message *proto.Message) bool {
+				return string(message.From) != "invalid"
+			},
+		}
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		backend,
+		mockTransport{},
+		WithMetrics(metrics),
+		WithMessageLimits(MessageLimits{
+			SenderQuota: 1,
+		}),
+	)
+	i.state.setView(&proto.View{Height: 1, Round: 0})
+
+	for round := uint64(0); round < 3; round++ {
+		assert.NoError(t, i.AddMessage(buildBasicPrepareMessage(
+			[]byte("hash"),
+			[]byte("node"),
+			&proto.View{Height: 1, Round: round},
+		)))
+	}
+
+	assert.Error(t, i.AddMessage(buildBasicCommitMessage(
+		[]byte("hash"),
+		[]byte("seal"),
+		[]byte("invalid"),
+		&proto.View{Height: 1, Round: 0},
+	)))
+	assert.Error(t, i.AddMessage(buildBasicPrepareMessage(
+		[]byte("hash"),
+		[]byte("node"),
+		&proto.View{Height: 0, Round: 0},
+	)))
+	assert.Error(t, i.AddMessage(nil))
+
+	// Make sure the types outside of the known set share a single label
+	for _, messageType := range []proto.MessageType{42, 43} {
+		assert.Error(t, i.AddMessage(&proto.Message{
+			View: &proto.View{Height: 1, Round: 0},
+			From: []byte("node"),
+			Type: messageType,
+		}))
+	}
+
+	// Make sure the message ingress is recorded per type and result
+	assert.Equal(t, 3, metrics.counter(MetricMessages, "type", "PREPARE", "result", "accepted"))
+	assert.Equal(t, 1, metrics.counter(MetricMessages, "type", "COMMIT", "result", "invalid_sender"))
+	assert.Equal(t, 1, metrics.counter(MetricMessages, "type", "PREPARE", "result", "stale_height"))
+	assert.Equal(t, 3, metrics.counter(MetricMessages, "type", "unknown", "result", "malformed"))
+
+	// Make sure the messages over the sender quota are recorded as dropped
+	assert.Equal(t, 2, metrics.counter(MetricDroppedMessages, "reason", "sender_quota"))
+}
+
+func TestIBFT_Metrics_RoundChange(t *testing.T) {
+	t.Parallel()
+
+	var (
+		metrics = newMockMetrics()
+		clock   = newMockClock()
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{},
+		mockTransport{},
+		WithMetrics(metrics),
+		WithClock(clock),
+	)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		i.RunSequence(ctx, 5)
+	}()
+
+	// Let round 0 expire, and cancel the sequence in round 1
+	clock.waitForTimer(round0Timeout)
+	clock.Advance(round0Timeout)
+	clock.waitForTimer(2 * round0Timeout)
+
+	cancelFn()
+	<-done
+
+	assert.Equal(t, float64(5), metrics.gauge(MetricHeight))
+	assert.Equal(t, float64(1), metrics.gauge(MetricRound))
+
+	// Make sure the round change is recorded with its reason and duration
+	assert.Equal(t, 1, metrics.counter(MetricRoundChanges, "reason", roundTimeout))
+	assert.Equal(
+		t,
+		[]float64{round0Timeout.Seconds()},
+		metrics.histogram(MetricRoundDuration, "outcome", roundTimeout),
+	)
+
+	// Make sure the cancelled round is not recorded as a round change
+	assert.Len(t, metrics.histogram(MetricRoundDuration, "outcome", roundCancelled), 1)
+	assert.Zero(t, metrics.counter(MetricRoundChanges, "reason", roundCancelled))
+
+	// Make sure the time spent in the states is recorded
+	assert.NotEmpty(t, metrics.histogram(MetricStateDuration, "state", "new_round"))
+}
@@ -68,6 +68,21 @@ func (m mockBackend) InsertBlock(proposal []byte, committedSeals []*messages.Com
 	}
 }
 
+// mockCheckedInsertBackend is the mock backend that
+// implements the CheckedInsertBackend extension
+type mockCheckedInsertBackend struct {
+	mockBackend
+
+	insertBlockWithErrorFn func([]byte, []*messages.CommittedSeal) error
+}
+
+func (m mockCheckedInsertBackend) InsertBlockWithError(
+	proposal []byte,
+	committedSeals []*messages.CommittedSeal,
+) error {
+	return m.insertBlockWithErrorFn(proposal, committedSeals)
+}
+
 func (m mockBackend) Quorum(blockNumber uint64) uint64 {
 	if m.quorumFn != nil {
 		return m.quorumFn(blockNumber)
@@ -230,7 +245,9 @@ type mockMessages struct {
 		messageType proto.MessageType,
 		isValid func(message *proto.Message) bool,
 	) []*proto.Message
-	getMostRoundChangeMessagesFn func(uint64, uint64) []*proto.Message
+	getMostRoundChangeMessagesFn    func(uint64, uint64) []*proto.Message
+	getHighestRoundChangeMessagesFn func(uint64, uint64) []*proto.Message
+	getHeightMessagesFn             func(uint64) []*proto.Message
 
 	subscribeFn   func(details messages.SubscriptionDetails) *messages.Subscription
 	unsubscribeFn func(id messages.SubscriptionID)
@@ -282,6 +299,104 @@ func (m mockMessages) GetMostRoundChangeMessages(round, height uint64) []*proto.
 	return nil
 }
 
+func (m mockMessages) GetHighestRoundChangeMessages(round, height uint64) []*proto.Message {
+	if m.getHighestRoundChangeMessagesFn != nil {
+		return m.getHighestRoundChangeMessagesFn(round, height)
+	}
+
+	return nil
+}
+
+func (m mockMessages) GetHeightMessages(height uint64) []*proto.Message {
+	if m.getHeightMessagesFn != nil {
+		return m.getHeightMessagesFn(height)
+	}
+
+	return nil
+}
+
+// mockStateStore is the mock state store structure that is configurable
+type mockStateStore struct {
+	saveFn func(*StateSnapshot) error
+	loadFn func() (*StateSnapshot, error)
+}
+
+func (s mockStateStore) Save(snapshot *StateSnapshot) error {
+	if s.saveFn != nil {
+		return s.saveFn(snapshot)
+	}
+
+	return nil
+}
+
+func (s mockStateStore) Load() (*StateSnapshot, error) {
+	if s.loadFn != nil {
+		return s.loadFn()
+	}
+
+	return nil, ErrSnapshotNotFound
+}
+
+// mockFinalizer is the observer that finalizes the sequences of the node
+// on request. The proposal and the committed seals the sequences are
+// finalized with are set as each round starts, on the sequence goroutine,
+// before the workers of the round are started
+type mockFinalizer struct {
+	NopObserver
+
+	node     *IBFT
+	proposal []byte
+	seals    []*messages.CommittedSeal
+
+	// started are the views of the started rounds
+	started chan *proto.View
+}
+
+// newMockFinalizer creates a new finalizer of the node sequences, and
+// sets it as the node observer. The proposal is not set if nil, so
+// the node builds its own proposal when it is the proposer
+func newMockFinalizer(node *IBFT, proposal []byte, seals []*messages.CommittedSeal) *mockFinalizer {
+	f := &mockFinalizer{
+		node:     node,
+		proposal: proposal,
+		seals:    seals,
+		started:  make(chan *proto.View, 10),
+	}
+
+	node.observer = f
+
+	return f
+}
+
+func (f *mockFinalizer) RoundStarted(view *proto.View) {
+	if f.proposal != nil {
+		f.node.state.setProposalMessage(&proto.Message{
+			Type: proto.MessageType_PREPREPARE,
+			Payload: &proto.Message_PreprepareData{
+				PreprepareData: &proto.PrePrepareMessage{
+					Proposal: f.proposal,
+				},
+			},
+		})
+	}
+
+	f.node.state.setCommittedSeals(f.seals)
+
+	f.started <- view
+}
+
+// finalize waits for the node to start the round
+// of the view, and finalizes the sequence in it
+func (f *mockFinalizer) finalize(view *proto.View) {
+	for started := range f.started {
+		if started.Height == view.Height && started.Round == view.Round {
+			f.node.roundDone <- struct{}{}
+
+			return
+		}
+	}
+}
+
 type backendConfigCallback func(*mockBackend)
 type loggerConfigCallback func(*mockLogger)
 type transportConfigCallback func(*mockTransport)
@@ -392,9 +507,149 @@ func (m *mockCluster) areAllNodesOnRound(round uint64) bool {
 	return true
 }
 
-// setBaseTimeout sets the base timeout for rounds
-func (m *mockCluster) setBaseTimeout(timeout time.Duration) {
+// setClock sets the source of time for all nodes in the cluster
+func (m *mockCluster) setClock(clock Clock) {
 	for _, node := range m.nodes {
-		node.baseRoundTimeout = timeout
+		node.clock = clock
 	}
 }
+
+// mockClock is the manually advanced clock
+type mockClock struct {
+	sync.Mutex
+
+	now    time.Time
+	timers []*mockTimer
+}
+
+func newMockClock() *mockClock {
+	return &mockClock{
+		now: time.Unix(0, 0),
+	}
+}
+
+func (c *mockClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.now
+}
+
+func (c *mockClock) NewTimer(d time.Duration) Timer {
+	c.Lock()
+	defer c.Unlock()
+
+	timer := &mockTimer{
+		clock:    c,
+		duration: d,
+		deadline: c.now.Add(d),
+		ch:       make(chan time.Time, 1),
+	}
+
+	if d <= 0 {
+		timer.ch <- c.now
+
+		return timer
+	}
+
+	c.timers = append(c.timers, timer)
+
+	return timer
+}
+
+func (c *mockClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Advance moves the clock forward, and fires
+// all the timers whose deadline has passed
+func (c *mockClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.now = c.now.Add(d)
+
+	pending := c.timers[:0]
+
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			pending = append(pending, timer)
+
+			continue
+		}
+
+		timer.ch <- c.now
+	}
+
+	c.timers = pending
+}
+
+// pendingTimers returns the number of timers
+// that have neither fired nor been stopped
+func (c *mockClock) pendingTimers() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return len(c.timers)
+}
+
+// waitForTimers blocks until the specified
+// number of timers is pending
+func (c *mockClock) waitForTimers(count int) {
+	for c.pendingTimers() < count {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitForTimer blocks until a timer
+// with the specified duration is pending
+func (c *mockClock) waitForTimer(d time.Duration) {
+	hasTimer := func() bool {
+		c.Lock()
+		defer c.Unlock()
+
+		for _, timer := range c.timers {
+			if timer.duration == d {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for !hasTimer() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// stop removes the timer from the pending timers
+func (c *mockClock) stop(timer *mockTimer) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	for index, pending := range c.timers {
+		if pending == timer {
+			c.timers = append(c.timers[:index], c.timers[index+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// mockTimer is the timer of the manually advanced clock
+type mockTimer struct {
+	clock    *mockClock
+	duration time.Duration
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *mockTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *mockTimer) Stop() bool {
+	return t.clock.stop(t)
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// Observer is notified of the consensus progress. The callbacks are
+// invoked synchronously from the consensus routines, so they should
+// return quickly and must not call back into the IBFT instance
+type Observer interface {
+	// SequenceStarted is called when the sequence for the height starts
+	SequenceStarted(height uint64)
+
+	// RoundStarted is called when a round of the sequence starts
+	RoundStarted(view *proto.View)
+
+	// ProposalAccepted is called when the proposal for the view is
+	// accepted, either built by this node or received from the proposer
+	ProposalAccepted(view *proto.View, proposal []byte)
+
+	// Prepared is called when a quorum of PREPARE messages is received
+	Prepared(view *proto.View, certificate *proto.PreparedCertificate)
+
+	// Committed is called when a quorum of COMMIT messages is received
+	Committed(view *proto.View, committedSeals []*messages.CommittedSeal)
+
+	// RoundTimeout is called when the round timer for the view expires
+	RoundTimeout(view *proto.View)
+
+	// RoundChangeCertificateReceived is called when a valid
+	// round change certificate for a higher round is received
+	RoundChangeCertificateReceived(view *proto.View)
+
+	// SequenceDone is called when the sequence for the height ends.
+	// The result is nil if the sequence was cancelled before the proposal
+	// was finalized, and the error is the one returned by RunSequence
+	SequenceDone(height uint64, result *SequenceResult, err error)
+}
+
+// NopObserver is the Observer that ignores all notifications.
+// It can be embedded to implement only some of the callbacks
+type NopObserver struct{}
+
+func (NopObserver) SequenceStarted(_ uint64) {}
+
+func (NopObserver) RoundStarted(_ *proto.View) {}
+
+func (NopObserver) ProposalAccepted(_ *proto.View, _ []byte) {}
+
+func (NopObserver) Prepared(_ *proto.View, _ *proto.PreparedCertificate) {}
+
+func (NopObserver) Committed(_ *proto.View, _ []*messages.CommittedSeal) {}
+
+func (NopObserver) RoundTimeout(_ *proto.View) {}
+
+func (NopObserver) RoundChangeCertificateReceived(_ *proto.View) {}
+
+func (NopObserver) SequenceDone(_ uint64, _ *SequenceResult, _ error) {}
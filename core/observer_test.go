@@ -0,0 +1,238 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockObserver records the notifications as readable events
+type mockObserver struct {
+	sync.Mutex
+
+	events []string
+	result *SequenceResult
+	err    error
+}
+
+func (o *mockObserver) record(format string, args ...interface{}) {
+	o.Lock()
+	defer o.Unlock()
+
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+func (o *mockObserver) getEvents() []string {
+	o.Lock()
+	defer o.Unlock()
+
+	return append([]string(nil), o.events...)
+}
+
+func (o *mockObserver) SequenceStarted(height uint64) {
+	o.record("sequence started %d", height)
+}
+
+func (o *mockObserver) RoundStarted(view *proto.View) {
+	o.record("round started %d", view.Round)
+}
+
+func (o *mockObserver) ProposalAccepted(view *proto.View, proposal []byte) {
+	o.record("proposal accepted %d %s", view.Round, proposal)
+}
+
+func (o *mockObserver) Prepared(view *proto.View, certificate *proto.PreparedCertificate) {
+	o.record("prepared %d %s", view.Round, messages.ExtractProposal(certificate.ProposalMessage))
+}
+
+func (o *mockObserver) Committed(view *proto.View, committedSeals []*messages.CommittedSeal) {
+	o.record("committed %d %d", view.Round, len(committedSeals))
+}
+
+func (o *mockObserver) RoundTimeout(view *proto.View) {
+	o.record("round timeout %d", view.Round)
+}
+
+func (o *mockObserver) RoundChangeCertificateReceived(view *proto.View) {
+	o.record("rcc received %d", view.Round)
+}
+
+func (o *mockObserver) SequenceDone(height uint64, result *SequenceResult, err error) {
+	o.record("sequence done %d", height)
+
+	o.Lock()
+	defer o.Unlock()
+
+	o.result = result
+	o.err = err
+}
+
+func TestIBFT_Observer_FinalizedSequence(t *testing.T) {
+	t.Parallel()
+
+	var (
+		i *IBFT
+
+		id           = []byte("node")
+		proposal     = []byte("proposal")
+		proposalHash = []byte("proposal hash")
+		observer     = &mockObserver{}
+
+		backend = mockBackend{
+			idFn: func() []byte {
+				return id
+			},
+			quorumFn: func(_ uint64) uint64 {
+				return 1
+			},
+			isProposerFn: func(_ []byte, _, _ uint64) bool {
+				return true
+			},
+			buildProposalFn: func(_ uint64) []byte {
+				return proposal
+			},
+			buildPrePrepareMessageFn: func(
+				proposal []byte,
+				certificate *proto.RoundChangeCertificate,
+				view *proto.View,
+			) *proto.Message {
+				return buildBasicPreprepareMessage(proposal, proposalHash, certificate, id, view)
+			},
+			buildPrepareMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+				return buildBasicPrepareMessage(proposalHash, id, view)
+			},
+			buildCommitMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+				return buildBasicCommitMessage(proposalHash, []byte("seal"), id, view)
+			},
+		}
+
+		// The node receives its own messages
+		transport = mockTransport{
+			multicastFn: func(message *proto.Message) {
+				_ = i.AddMessage(message)
+			},
+		}
+	)
+
+	i = NewIBFT(mockLogger{}, backend, transport, WithObserver(observer))
+
+	result, err := i.RunSequence(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]string{
+			"sequence started 1",
+			"round started 0",
+			"proposal accepted 0 proposal",
+			"prepared 0 proposal",
+			"committed 0 1",
+			"sequence done 1",
+		},
+		observer.getEvents(),
+	)
+
+	// Make sure the observer receives the sequence result
+	assert.Equal(t, result, observer.result)
+	assert.NoError(t, observer.err)
+}
+
+func TestIBFT_Observer_RoundTimeout(t *testing.T) {
+	t.Parallel()
+
+	var (
+		observer = &mockObserver{}
+		clock    = newMockClock()
+	)
+
+	i := NewIBFT(
+		mockLogger{},
+		mockBackend{},
+		mockTransport{},
+		WithObserver(observer),
+		WithClock(clock),
+	)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_, _ = i.RunSequence(ctx, 1)
+	}()
+
+	// Let round 0 expire, and cancel the sequence in round 1
+	clock.waitForTimer(round0Timeout)
+	clock.Advance(round0Timeout)
+	clock.waitForTimer(2 * round0Timeout)
+
+	cancelFn()
+	<-done
+
+	assert.Equal(
+		t,
+		[]string{
+			"sequence started 1",
+			"round started 0",
+			"round timeout 0",
+			"round started 1",
+			"sequence done 1",
+		},
+		observer.getEvents(),
+	)
+
+	// Make sure the cancelled sequence has no result
+	assert.Nil(t, observer.result)
+	assert.ErrorIs(t, observer.err, context.Canceled)
+}
+
+func TestIBFT_Observer_RoundChangeCertificate(t *testing.T) {
+	t.Parallel()
+
+	observer := &mockObserver{}
+
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{}, WithObserver(observer))
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_, _ = i.RunSequence(ctx, 1)
+	}()
+
+	// Imitate a valid RCC for round 2 being received
+	i.roundCertificate <- 2
+
+	for i.state.getRound() != 2 {
+		runtime.Gosched()
+	}
+
+	cancelFn()
+	<-done
+
+	assert.Equal(
+		t,
+		[]string{
+			"sequence started 1",
+			"round started 0",
+			"rcc received 2",
+			"round started 2",
+			"sequence done 1",
+		},
+		observer.getEvents(),
+	)
+}
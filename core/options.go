@@ -0,0 +1,97 @@
+package core
+
+import (
+	"time"
+
+	"github.com/nubank/go-ibft/messages"
+)
+
+// Option is the IBFT instance configuration option
+type Option func(*IBFT)
+
+// WithStateStore sets the store used for persisting
+// the consensus state before multicasting messages,
+// and for restoring it when a sequence is (re)started
+func WithStateStore(store StateStore) Option {
+	return func(i *IBFT) {
+		i.store = store
+	}
+}
+
+// WithRoundTimeoutPolicy sets the policy that determines
+// the timeout for each round of consensus. By default,
+// the round timeout grows exponentially with each round
+func WithRoundTimeoutPolicy(policy RoundTimeoutPolicy) Option {
+	return func(i *IBFT) {
+		i.roundTimeout = policy
+	}
+}
+
+// WithClock sets the source of time used for round timers
+// and measurements. By default, the system time is used
+func WithClock(clock Clock) Option {
+	return func(i *IBFT) {
+		i.clock = clock
+	}
+}
+
+// WithMinBlockInterval sets the minimum time between the finalization of
+// a sequence run by the driver (Run) and the proposal of the next one.
+// Only the proposer waits; the other nodes start the next sequence right
+// away. The wait counts toward the round timeout, so the interval should
+// be shorter than it. By default, the proposal is built right away
+func WithMinBlockInterval(interval time.Duration) Option {
+	return func(i *IBFT) {
+		i.minBlockInterval = interval
+	}
+}
+
+// WithEquivocationHandler sets the handler that is notified when a sender
+// sends conflicting messages for the same view. The first message is kept
+// for consensus, and both messages are passed to the handler
+func WithEquivocationHandler(handler messages.EquivocationHandler) Option {
+	return func(i *IBFT) {
+		i.equivocationHandler = handler
+	}
+}
+
+// WithSigner sets the signer of the outgoing messages. The messages
+// built by the backend are signed over their payload without the signature
+func WithSigner(signer Signer) Option {
+	return func(i *IBFT) {
+		i.signer = signer
+	}
+}
+
+// WithSignatureVerifier sets the verifier of the incoming message
+// signatures. Messages not signed by their sender are rejected,
+// in addition to the backend sender checks (IsValidSender)
+func WithSignatureVerifier(verifier SignatureVerifier) Option {
+	return func(i *IBFT) {
+		i.signatureVerifier = verifier
+	}
+}
+
+// WithMessageLimits sets the limits of the messages kept by the node.
+// Messages too far ahead of the current view are rejected by AddMessage,
+// and the messages over the sender quota or the memory limit are dropped
+func WithMessageLimits(limits MessageLimits) Option {
+	return func(i *IBFT) {
+		i.messageLimits = limits
+	}
+}
+
+// WithMetrics sets the recorder of the consensus metrics
+// (see the Metric* constants). By default, no metrics are recorded
+func WithMetrics(metrics Metrics) Option {
+	return func(i *IBFT) {
+		i.metrics = metrics
+	}
+}
+
+// WithObserver sets the observer notified of the consensus progress
+func WithObserver(observer Observer) Option {
+	return func(i *IBFT) {
+		i.observer = observer
+	}
+}
@@ -0,0 +1,258 @@
+// Package prometheus records the consensus metrics (see core.Metrics),
+// and exposes them in the Prometheus text exposition format
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the default histogram bucket upper bounds, in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type metricType string
+
+const (
+	counterType   metricType = "counter"
+	gaugeType     metricType = "gauge"
+	histogramType metricType = "histogram"
+)
+
+// Option is the configuration option of the Registry
+type Option func(*Registry)
+
+// WithBuckets sets the histogram bucket upper bounds
+func WithBuckets(buckets []float64) Option {
+	return func(r *Registry) {
+		r.buckets = append([]float64(nil), buckets...)
+		sort.Float64s(r.buckets)
+	}
+}
+
+// series is a single metric series, identified by its labels
+type series struct {
+	labels string
+
+	// value is the counter or the gauge value
+	value float64
+
+	// counts are the (non-cumulative) histogram bucket counts,
+	// with the last one counting the values above the largest bound
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// family is the metric with all of its series
+type family struct {
+	metricType metricType
+	series     map[string]*series
+}
+
+// Registry is the Metrics that keeps the metrics in memory,
+// and writes them in the Prometheus text exposition format
+type Registry struct {
+	buckets []float64
+
+	families map[string]*family
+	lock     sync.Mutex
+}
+
+// NewRegistry creates a new empty registry
+func NewRegistry(opts ...Option) *Registry {
+	r := &Registry{
+		buckets:  DefaultBuckets,
+		families: make(map[string]*family),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// IncCounter increments the counter by one
+func (r *Registry) IncCounter(name string, labels ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.getSeries(name, counterType, labels).value++
+}
+
+// SetGauge sets the gauge to the value
+func (r *Registry) SetGauge(name string, value float64, labels ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.getSeries(name, gaugeType, labels).value = value
+}
+
+// ObserveHistogram adds the observed value to the histogram
+func (r *Registry) ObserveHistogram(name string, value float64, labels ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s := r.getSeries(name, histogramType, labels)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(r.buckets)+1)
+	}
+
+	s.counts[sort.SearchFloat64s(r.buckets, value)]++
+	s.sum += value
+	s.count++
+}
+
+// getSeries returns the series of the metric with the labels,
+// creating it if needed. It should be called with the lock held
+func (r *Registry) getSeries(name string, metricType metricType, labels []string) *series {
+	f, exists := r.families[name]
+	if !exists {
+		f = &family{
+			metricType: metricType,
+			series:     make(map[string]*series),
+		}
+
+		r.families[name] = f
+	}
+
+	key := formatLabels(labels)
+
+	s, exists := f.series[key]
+	if !exists {
+		s = &series{labels: key}
+
+		f.series[key] = s
+	}
+
+	return s
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var b strings.Builder
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.writeFamily(&b, name, r.families[name])
+	}
+
+	n, err := io.WriteString(w, b.String())
+
+	return int64(n), err
+}
+
+// writeFamily writes the metric with all of its series
+func (r *Registry) writeFamily(b *strings.Builder, name string, f *family) {
+	fmt.Fprintf(b, "# TYPE %s %s\n", name, f.metricType)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+
+		if f.metricType != histogramType {
+			fmt.Fprintf(b, "%s%s %s\n", name, wrapLabels(s.labels), formatValue(s.value))
+
+			continue
+		}
+
+		var cumulative uint64
+
+		for index, bound := range r.buckets {
+			cumulative += s.counts[index]
+
+			fmt.Fprintf(
+				b,
+				"%s_bucket%s %d\n",
+				name,
+				wrapLabels(joinLabels(s.labels, "le", formatValue(bound))),
+				cumulative,
+			)
+		}
+
+		fmt.Fprintf(b, "%s_bucket%s %d\n", name, wrapLabels(joinLabels(s.labels, "le", "+Inf")), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", name, wrapLabels(s.labels), formatValue(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", name, wrapLabels(s.labels), s.count)
+	}
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+
+	_, _ = r.WriteTo(w)
+}
+
+// formatLabels formats the alternating label names and values,
+// sorted by the label name. A trailing name without a value is ignored
+func formatLabels(labels []string) string {
+	pairs := make([]string, 0, len(labels)/2)
+	for index := 0; index+1 < len(labels); index += 2 {
+		pairs = append(pairs, formatLabel(labels[index], labels[index+1]))
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// formatLabel formats the label name and the escaped value
+func formatLabel(name, value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+
+	return name + `="` + value + `"`
+}
+
+// joinLabels appends the label to the formatted labels
+func joinLabels(labels, name, value string) string {
+	if labels == "" {
+		return formatLabel(name, value)
+	}
+
+	return labels + "," + formatLabel(name, value)
+}
+
+// wrapLabels wraps the formatted labels in braces, if there are any
+func wrapLabels(labels string) string {
+	if labels == "" {
+		return ""
+	}
+
+	return "{" + labels + "}"
+}
+
+// formatValue formats the sample value
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,70 @@
+package prometheus
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nubank/go-ibft/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the registry records the consensus metrics
+var _ core.Metrics = (*Registry)(nil)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry(WithBuckets([]float64{1, 0.5}))
+
+	registry.IncCounter(core.MetricMessages, "type", "PREPARE", "result", "accepted")
+	registry.IncCounter(core.MetricMessages, "result", "accepted", "type", "PREPARE")
+	registry.IncCounter(core.MetricMessages, "type", "COMMIT", "result", "stale_round")
+	registry.IncCounter(core.MetricDroppedMessages, "reason", "quote \" and \\ backslash\n")
+	registry.SetGauge(core.MetricHeight, 10)
+	registry.SetGauge(core.MetricHeight, 11)
+	registry.ObserveHistogram(core.MetricRoundDuration, 0.25, "outcome", "finalized")
+	registry.ObserveHistogram(core.MetricRoundDuration, 1, "outcome", "finalized")
+	registry.ObserveHistogram(core.MetricRoundDuration, 3, "outcome", "finalized")
+
+	var buf bytes.Buffer
+
+	n, err := registry.WriteTo(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Equal(
+		t,
+		`# TYPE ibft_dropped_messages_total counter
+ibft_dropped_messages_total{reason="quote \" and \\ backslash\n"} 1
+# TYPE ibft_height gauge
+ibft_height 11
+# TYPE ibft_messages_total counter
+ibft_messages_total{result="accepted",type="PREPARE"} 2
+ibft_messages_total{result="stale_round",type="COMMIT"} 1
+# TYPE ibft_round_duration_seconds histogram
+ibft_round_duration_seconds_bucket{outcome="finalized",le="0.5"} 1
+ibft_round_duration_seconds_bucket{outcome="finalized",le="1"} 2
+ibft_round_duration_seconds_bucket{outcome="finalized",le="+Inf"} 3
+ibft_round_duration_seconds_sum{outcome="finalized"} 4.25
+ibft_round_duration_seconds_count{outcome="finalized"} 3
+`,
+		buf.String(),
+	)
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.IncCounter(core.MetricRoundChanges, "reason", "timeout")
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), `ibft_round_changes_total{reason="timeout"} 1`)
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// ValidatorSetProvider is the optional Backend extension for validators
+// with unequal voting power. If implemented, the consensus thresholds are
+// computed from the summed voting power of the message senders, instead
+// of the message count (Quorum and MaximumFaultyNodes)
+type ValidatorSetProvider interface {
+	// ValidatorSet returns the validator set for the specified height
+	ValidatorSet(height uint64) messages.ValidatorSet
+}
+
+// validatorSet returns the validator set for the specified
+// height, if the backend provides one
+func (i *IBFT) validatorSet(height uint64) (messages.ValidatorSet, bool) {
+	provider, ok := i.backend.(ValidatorSetProvider)
+	if !ok {
+		return nil, false
+	}
+
+	set := provider.ValidatorSet(height)
+
+	return set, set != nil
+}
+
+// hasQuorum checks if the messages for the specified height form a quorum
+func (i *IBFT) hasQuorum(height uint64, msgs []*proto.Message) bool {
+	set, ok := i.validatorSet(height)
+	if !ok {
+		return len(msgs) >= int(i.backend.Quorum(height))
+	}
+
+	return messages.SendersVotingPower(set, msgs) >= messages.QuorumVotingPower(set)
+}
+
+// hasHonestSender checks if the messages for the specified height are
+// sent by more than the maximum faulty nodes (at least one honest node)
+func (i *IBFT) hasHonestSender(height uint64, msgs []*proto.Message) bool {
+	set, ok := i.validatorSet(height)
+	if !ok {
+		return uint64(len(msgs)) >= i.backend.MaximumFaultyNodes()+1
+	}
+
+	return messages.SendersVotingPower(set, msgs) > messages.MaximumFaultyVotingPower(set)
+}
+
+// quorumSubscriptionSize returns the minimum number of messages
+// a subscription waiting for the specified quorum size should be notified on.
+// With weighted voting power, the number of messages forming a quorum
+// is not known upfront, so the subscription is notified on each message
+func (i *IBFT) quorumSubscriptionSize(height uint64, quorumSize int) int {
+	if _, ok := i.validatorSet(height); ok {
+		return 1
+	}
+
+	return quorumSize
+}
+
+// signerBitmap returns the bitmap of the committed seal signers
+// for the specified height, if the backend provides the validator set
+func (i *IBFT) signerBitmap(height uint64, seals []*messages.CommittedSeal) messages.ValidatorBitmap {
+	set, ok := i.validatorSet(height)
+	if !ok {
+		return nil
+	}
+
+	return messages.SignerBitmap(set, messages.SealSigners(seals))
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockValidatorSetBackend is the mock backend
+// that implements the ValidatorSetProvider extension
+type mockValidatorSetBackend struct {
+	mockBackend
+
+	validatorSetFn func(uint64) messages.ValidatorSet
+}
+
+func (m mockValidatorSetBackend) ValidatorSet(height uint64) messages.ValidatorSet {
+	return m.validatorSetFn(height)
+}
+
+// newWeightedBackend creates a new backend with the validator
+// voting powers of 5, 1, 1, 1 (quorum voting power of 6)
+func newWeightedBackend(nodes [][]byte) mockValidatorSetBackend {
+	set := messages.NewValidatorSet([]messages.Validator{
+		{ID: nodes[0], VotingPower: 5},
+		{ID: nodes[1], VotingPower: 1},
+		{ID: nodes[2], VotingPower: 1},
+		{ID: nodes[3], VotingPower: 1},
+	})
+
+	return mockValidatorSetBackend{
+		mockBackend: mockBackend{
+			quorumFn: func(_ uint64) uint64 {
+				return 3
+			},
+			maximumFaultyNodesFn: func() uint64 {
+				return 1
+			},
+		},
+		validatorSetFn: func(_ uint64) messages.ValidatorSet {
+			return set
+		},
+	}
+}
+
+// buildSenderMessages builds a message for each of the senders
+func buildSenderMessages(senders ...[]byte) []*proto.Message {
+	msgs := make([]*proto.Message, len(senders))
+	for index, sender := range senders {
+		msgs[index] = buildBasicCommitMessage(nil, nil, sender, &proto.View{})
+	}
+
+	return msgs
+}
+
+func TestIBFT_Quorum(t *testing.T) {
+	t.Parallel()
+
+	nodes := generateNodeAddresses(4)
+
+	t.Run("count based quorum", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{
+			quorumFn: func(_ uint64) uint64 {
+				return 3
+			},
+			maximumFaultyNodesFn: func() uint64 {
+				return 1
+			},
+		}, mockTransport{})
+
+		assert.False(t, i.hasQuorum(0, buildSenderMessages(nodes[0], nodes[1])))
+		assert.True(t, i.hasQuorum(0, buildSenderMessages(nodes[1], nodes[2], nodes[3])))
+
+		assert.False(t, i.hasHonestSender(0, buildSenderMessages(nodes[0])))
+		assert.True(t, i.hasHonestSender(0, buildSenderMessages(nodes[0], nodes[1])))
+
+		assert.Equal(t, 3, i.quorumSubscriptionSize(0, 3))
+	})
+
+	t.Run("voting power based quorum", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, newWeightedBackend(nodes), mockTransport{})
+
+		// Make sure the voting power is summed, instead of the senders counted
+		assert.False(t, i.hasQuorum(0, buildSenderMessages(nodes[0])))
+		assert.False(t, i.hasQuorum(0, buildSenderMessages(nodes[1], nodes[2], nodes[3])))
+		assert.True(t, i.hasQuorum(0, buildSenderMessages(nodes[0], nodes[1])))
+
+		// Make sure duplicate senders are not counted twice
+		assert.False(t, i.hasQuorum(0, buildSenderMessages(nodes[0], nodes[0])))
+
+		assert.False(t, i.hasHonestSender(0, buildSenderMessages(nodes[1], nodes[2])))
+		assert.True(t, i.hasHonestSender(0, buildSenderMessages(nodes[1], nodes[2], nodes[3])))
+		assert.True(t, i.hasHonestSender(0, buildSenderMessages(nodes[0])))
+
+		// Make sure the subscriptions are notified on each message
+		assert.Equal(t, 1, i.quorumSubscriptionSize(0, 3))
+	})
+
+	t.Run("missing validator set falls back to count", func(t *testing.T) {
+		t.Parallel()
+
+		backend := newWeightedBackend(nodes)
+		backend.validatorSetFn = func(_ uint64) messages.ValidatorSet {
+			return nil
+		}
+
+		i := NewIBFT(mockLogger{}, backend, mockTransport{})
+
+		assert.False(t, i.hasQuorum(0, buildSenderMessages(nodes[0], nodes[1])))
+		assert.Equal(t, 3, i.quorumSubscriptionSize(0, 3))
+	})
+}
+
+// TestIBFT_WeightedCommit makes sure the COMMIT quorum
+// is reached based on the voting power of the senders
+func TestIBFT_WeightedCommit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes    = generateNodeAddresses(4)
+		proposal = []byte("proposal")
+		view     = &proto.View{
+			Height: 1,
+			Round:  0,
+		}
+	)
+
+	i := NewIBFT(mockLogger{}, newWeightedBackend(nodes), mockTransport{})
+	i.state.setView(view)
+	i.state.setProposalMessage(buildBasicPreprepareMessage(proposal, []byte("hash"), nil, nodes[0], view))
+
+	// Make sure 3 of 4 validators without the heaviest one don't form a quorum
+	for _, node := range nodes[1:] {
+		i.AddMessage(buildBasicCommitMessage([]byte("hash"), []byte("seal"), node, view))
+	}
+
+	assert.False(t, i.handleCommit(view))
+
+	// Make sure the heaviest validator completes the quorum
+	i.AddMessage(buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[0], view))
+
+	assert.True(t, i.handleCommit(view))
+	assert.Equal(t, fin, i.state.getStateName())
+	assert.Len(t, i.state.getCommittedSeals(), 4)
+}
+
+// TestIBFT_ValidatorSetMembership makes sure messages from
+// nodes outside the validator set are not accepted
+func TestIBFT_ValidatorSetMembership(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes = generateNodeAddresses(5)
+		added = make([]*proto.Message, 0)
+	)
+
+	i := NewIBFT(mockLogger{}, newWeightedBackend(nodes), mockTransport{})
+	i.messages = mockMessages{
+		addMessageFn: func(message *proto.Message) {
+			added = append(added, message)
+		},
+	}
+
+	assert.NoError(t, i.AddMessage(buildBasicPrepareMessage([]byte("hash"), nodes[1], &proto.View{})))
+	assert.ErrorIs(
+		t,
+		i.AddMessage(buildBasicPrepareMessage([]byte("hash"), nodes[4], &proto.View{})),
+		ErrNotValidator,
+	)
+
+	if assert.Len(t, added, 1) {
+		assert.Equal(t, nodes[1], added[0].From)
+	}
+}
+
+func TestIBFT_SignerBitmap(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes = generateNodeAddresses(4)
+		seals = []*messages.CommittedSeal{
+			{Signer: nodes[0]},
+			{Signer: nodes[2]},
+		}
+	)
+
+	t.Run("bitmap of the signers", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, newWeightedBackend(nodes), mockTransport{})
+
+		assert.Equal(t, messages.ValidatorBitmap{0x05}, i.signerBitmap(0, seals))
+	})
+
+	t.Run("no validator set", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+
+		assert.Nil(t, i.signerBitmap(0, seals))
+	})
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// ErrInsertBlock is returned by RunSequence when consensus was reached,
+// but the finalized proposal could not be inserted
+var ErrInsertBlock = errors.New("unable to insert block")
+
+// StateDurations contains the total time spent in
+// each consensus state, across all rounds of a sequence
+type StateDurations struct {
+	// NewRound is the time spent waiting for (or building) a proposal
+	NewRound time.Duration
+
+	// Prepare is the time spent waiting for a PREPARE quorum
+	Prepare time.Duration
+
+	// Commit is the time spent waiting for a COMMIT quorum
+	Commit time.Duration
+}
+
+// SequenceResult is the outcome of a finalized consensus sequence
+type SequenceResult struct {
+	// Height is the height of the sequence
+	Height uint64
+
+	// Round is the round in which the proposal was finalized
+	Round uint64
+
+	// Proposal is the finalized proposal
+	Proposal []byte
+
+	// CommittedSeals are the seals of the finalized proposal
+	CommittedSeals []*messages.CommittedSeal
+
+	// RoundChanges is the number of round changes
+	// that happened before the proposal was finalized
+	RoundChanges uint64
+
+	// Duration is the total duration of the sequence
+	Duration time.Duration
+
+	// StateDurations are the durations of the consensus states
+	StateDurations StateDurations
+
+	// Signers is the bitmap of the validators whose committed seals
+	// were collected. Set only if the backend is a ValidatorSetProvider
+	Signers messages.ValidatorBitmap
+
+	// AggregatedSeal is the aggregation of the committed seals.
+	// Set only if the backend is a SealAggregatingBackend
+	AggregatedSeal *messages.AggregatedSeal
+
+	// Certificate is the portable proof of the finalization, with
+	// the aggregated seal if the seals were aggregated
+	Certificate *proto.CommitCertificate
+}
+
+// commitCertificate creates the commit certificate of the finalized proposal
+func (i *IBFT) commitCertificate(aggregatedSeal *messages.AggregatedSeal) *proto.CommitCertificate {
+	var (
+		view         = i.state.getView()
+		proposalHash = i.state.getProposalHash()
+	)
+
+	if aggregatedSeal != nil {
+		return messages.NewAggregatedCommitCertificate(view, proposalHash, aggregatedSeal)
+	}
+
+	return messages.NewCommitCertificate(view, proposalHash, i.state.getCommittedSeals())
+}
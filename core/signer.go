@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// Signer signs the outgoing messages
+type Signer interface {
+	// Sign returns the signature of the payload
+	Sign(payload []byte) ([]byte, error)
+}
+
+// SignatureVerifier verifies the signatures of the incoming messages
+type SignatureVerifier interface {
+	// Verify checks if the signature of the payload is from the signer
+	Verify(signer, payload, signature []byte) bool
+}
+
+// signMessage signs the message over its canonical
+// payload (without the signature), if the signer is set
+func (i *IBFT) signMessage(message *proto.Message) error {
+	if i.signer == nil || message == nil {
+		return nil
+	}
+
+	payload, err := message.PayloadNoSig()
+	if err != nil {
+		return fmt.Errorf("unable to encode message: %w", err)
+	}
+
+	signature, err := i.signer.Sign(payload)
+	if err != nil {
+		return fmt.Errorf("unable to sign message: %w", err)
+	}
+
+	message.Signature = signature
+
+	return nil
+}
+
+// isValidSignature checks if the message is signed by its
+// sender, if the signature verifier is set
+func (i *IBFT) isValidSignature(message *proto.Message) bool {
+	if i.signatureVerifier == nil {
+		return true
+	}
+
+	payload, err := message.PayloadNoSig()
+	if err != nil {
+		return false
+	}
+
+	return i.signatureVerifier.Verify(message.From, payload, message.Signature)
+}
+
+// isValidSender checks if the message sender is valid, and
+// the message is signed by it, if the signature verifier is set
+func (i *IBFT) isValidSender(message *proto.Message) bool {
+	return i.backend.IsValidSender(message) && i.isValidSignature(message)
+}
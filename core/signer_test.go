@@ -0,0 +1,245 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/nubank/go-ibft/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSigner creates a new ed25519 signer
+func newTestSigner(t *testing.T) *signing.Ed25519Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := signing.NewEd25519Signer(key)
+	require.NoError(t, err)
+
+	return signer
+}
+
+// acceptTestProposal sets the accepted proposal of the node
+func acceptTestProposal(i *IBFT) {
+	i.state.setProposalMessage(&proto.Message{
+		Type: proto.MessageType_PREPREPARE,
+		Payload: &proto.Message_PreprepareData{
+			PreprepareData: &proto.PrePrepareMessage{
+				Proposal:     []byte("proposal"),
+				ProposalHash: []byte("proposal hash"),
+			},
+		},
+	})
+}
+
+// mockSigner is the Signer with a custom sign callback
+type mockSigner struct {
+	signFn func([]byte) ([]byte, error)
+}
+
+func (m mockSigner) Sign(payload []byte) ([]byte, error) {
+	return m.signFn(payload)
+}
+
+// TestIBFT_SignOutgoingMessages makes sure the outgoing
+// messages are signed by the configured signer
+func TestIBFT_SignOutgoingMessages(t *testing.T) {
+	t.Parallel()
+
+	var (
+		signer = newTestSigner(t)
+		view   = &proto.View{
+			Height: 1,
+			Round:  0,
+		}
+
+		multicasted []*proto.Message
+
+		log       = mockLogger{}
+		transport = mockTransport{
+			multicastFn: func(message *proto.Message) {
+				multicasted = append(multicasted, message)
+			},
+		}
+		backend = mockBackend{
+			buildPrepareMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+				return &proto.Message{
+					View: view,
+					From: signer.ID(),
+					Type: proto.MessageType_PREPARE,
+				}
+			},
+			buildCommitMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+				return &proto.Message{
+					View: view,
+					From: signer.ID(),
+					Type: proto.MessageType_COMMIT,
+				}
+			},
+			buildRoundChangeMessageFn: func(
+				_ []byte,
+				_ *proto.PreparedCertificate,
+				view *proto.View,
+			) *proto.Message {
+				return &proto.Message{
+					View: view,
+					From: signer.ID(),
+					Type: proto.MessageType_ROUND_CHANGE,
+				}
+			},
+		}
+	)
+
+	i := NewIBFT(log, backend, transport, WithSigner(signer))
+	acceptTestProposal(i)
+
+	require.NoError(t, i.sendPrepareMessage(view))
+	require.NoError(t, i.sendCommitMessage(view))
+	require.NoError(t, i.sendRoundChangeMessage(view.Height, view.Round+1))
+
+	require.Len(t, multicasted, 3)
+
+	for _, message := range multicasted {
+		payload, err := message.PayloadNoSig()
+		require.NoError(t, err)
+
+		assert.True(
+			t,
+			signing.Ed25519Verifier{}.Verify(message.From, payload, message.Signature),
+		)
+	}
+}
+
+// TestIBFT_SignFailure makes sure messages that
+// cannot be signed are not sent out
+func TestIBFT_SignFailure(t *testing.T) {
+	t.Parallel()
+
+	var (
+		multicasted = false
+
+		log       = mockLogger{}
+		transport = mockTransport{
+			multicastFn: func(_ *proto.Message) {
+				multicasted = true
+			},
+		}
+		backend = mockBackend{
+			buildPrepareMessageFn: func(_ []byte, view *proto.View) *proto.Message {
+				return &proto.Message{
+					View: view,
+					Type: proto.MessageType_PREPARE,
+				}
+			},
+		}
+		signer = mockSigner{
+			signFn: func(_ []byte) ([]byte, error) {
+				return nil, errors.New("signer unavailable")
+			},
+		}
+	)
+
+	i := NewIBFT(log, backend, transport, WithSigner(signer))
+	acceptTestProposal(i)
+
+	require.NoError(t, i.sendPrepareMessage(&proto.View{Height: 1}))
+
+	assert.False(t, multicasted)
+}
+
+// TestIBFT_VerifyIncomingSignatures makes sure only
+// messages signed by their sender are accepted
+func TestIBFT_VerifyIncomingSignatures(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sender = newTestSigner(t)
+		other  = newTestSigner(t)
+	)
+
+	signedBy := func(signer *signing.Ed25519Signer) *proto.Message {
+		message := &proto.Message{
+			View: &proto.View{
+				Height: 1,
+				Round:  0,
+			},
+			From: sender.ID(),
+			Type: proto.MessageType_PREPARE,
+			Payload: &proto.Message_PrepareData{
+				PrepareData: &proto.PrepareMessage{
+					ProposalHash: []byte("proposal hash"),
+				},
+			},
+		}
+
+		payload, err := message.PayloadNoSig()
+		require.NoError(t, err)
+
+		message.Signature, err = signer.Sign(payload)
+		require.NoError(t, err)
+
+		return message
+	}
+
+	tamperedMessage := signedBy(sender)
+	tamperedMessage.GetPrepareData().ProposalHash = []byte("other hash")
+
+	testTable := []struct {
+		name        string
+		message     *proto.Message
+		expectedErr error
+	}{
+		{
+			"signed by the sender",
+			signedBy(sender),
+			nil,
+		},
+		{
+			"signed by another node",
+			signedBy(other),
+			ErrInvalidSignature,
+		},
+		{
+			"not signed",
+			&proto.Message{
+				View: &proto.View{Height: 1},
+				From: sender.ID(),
+				Type: proto.MessageType_PREPARE,
+				Payload: &proto.Message_PrepareData{
+					PrepareData: &proto.PrepareMessage{},
+				},
+			},
+			ErrInvalidSignature,
+		},
+		{
+			"tampered payload",
+			tamperedMessage,
+			ErrInvalidSignature,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			i := NewIBFT(
+				mockLogger{},
+				mockBackend{},
+				mockTransport{},
+				WithSignatureVerifier(signing.Ed25519Verifier{}),
+			)
+
+			i.state.setView(&proto.View{Height: 1})
+
+			assert.Equal(t, testCase.expectedErr, i.validateMessage(testCase.message))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"sync"
+	"time"
 
 	"github.com/nubank/go-ibft/messages"
 	"github.com/nubank/go-ibft/messages/proto"
@@ -54,6 +55,23 @@ type state struct {
 	roundStarted bool
 
 	name stateType
+
+	// durations are the times spent in each state for the current height
+	durations StateDurations
+
+	// roundChanges is the number of round changes for the current height
+	roundChanges uint64
+
+	// roundStart is the time the current round started
+	roundStart time.Time
+
+	// roundDeadline is the time the round timer of the current
+	// round expires. It is zero if the timer is not running
+	roundDeadline time.Time
+
+	// latency contains the times from the start of
+	// the current round until the quorums were reached
+	latency SequenceLatency
 }
 
 func (s *state) getView() *proto.View {
@@ -76,6 +94,11 @@ func (s *state) clear(height uint64) {
 	s.proposalMessage = nil
 	s.latestPC = nil
 	s.latestPreparedProposedBlock = nil
+	s.durations = StateDurations{}
+	s.roundChanges = 0
+	s.roundStart = time.Time{}
+	s.roundDeadline = time.Time{}
+	s.latency = SequenceLatency{}
 
 	s.view = &proto.View{
 		Height: height,
@@ -108,7 +131,11 @@ func (s *state) getProposalHash() []byte {
 	s.RLock()
 	defer s.RUnlock()
 
-	return messages.ExtractProposalHash(s.proposalMessage)
+	if s.proposalMessage != nil {
+		return messages.ExtractProposalHash(s.proposalMessage)
+	}
+
+	return nil
 }
 
 func (s *state) setProposalMessage(proposalMessage *proto.Message) {
@@ -185,6 +212,71 @@ func (s *state) setCommittedSeals(seals []*messages.CommittedSeal) {
 	s.seals = seals
 }
 
+func (s *state) addDuration(name stateType, duration time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	switch name {
+	case newRound:
+		s.durations.NewRound += duration
+	case prepare:
+		s.durations.Prepare += duration
+	case commit:
+		s.durations.Commit += duration
+	case fin:
+	}
+}
+
+func (s *state) getDurations() StateDurations {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.durations
+}
+
+func (s *state) incRoundChanges() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.roundChanges++
+}
+
+func (s *state) getRoundChanges() uint64 {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.roundChanges
+}
+
+func (s *state) setRoundStart(start time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.roundStart = start
+	s.latency = SequenceLatency{}
+}
+
+func (s *state) setPrepareLatency(now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.latency.Prepare = now.Sub(s.roundStart)
+}
+
+func (s *state) setCommitLatency(now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.latency.Commit = now.Sub(s.roundStart)
+}
+
+func (s *state) getLatency() SequenceLatency {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.latency
+}
+
 func (s *state) newRound() {
 	s.Lock()
 	defer s.Unlock()
@@ -209,3 +301,85 @@ func (s *state) finalizePrepare(
 	// Move to the commit state
 	s.name = commit
 }
+
+func (s *state) setRoundDeadline(deadline time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.roundDeadline = deadline
+}
+
+func (s *state) getRoundDeadline() time.Time {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.roundDeadline
+}
+
+// status returns the status of the state fields
+func (s *state) status() *Status {
+	s.RLock()
+	defer s.RUnlock()
+
+	status := &Status{
+		View: &proto.View{
+			Height: s.view.Height,
+			Round:  s.view.Round,
+		},
+		State:            s.name.String(),
+		ProposalAccepted: s.proposalMessage != nil,
+	}
+
+	if s.proposalMessage != nil {
+		status.ProposalHash = messages.ExtractProposalHash(s.proposalMessage)
+	}
+
+	if s.latestPC != nil && s.latestPC.ProposalMessage != nil {
+		round := s.latestPC.ProposalMessage.GetView().GetRound()
+		status.LatestPCRound = &round
+	}
+
+	return status
+}
+
+func (s *state) snapshot() *StateSnapshot {
+	s.RLock()
+	defer s.RUnlock()
+
+	return &StateSnapshot{
+		View: &proto.View{
+			Height: s.view.Height,
+			Round:  s.view.Round,
+		},
+		LatestPC:                    s.latestPC,
+		LatestPreparedProposedBlock: s.latestPreparedProposedBlock,
+		ProposalMessage:             s.proposalMessage,
+	}
+}
+
+func (s *state) restore(snapshot *StateSnapshot) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.view = &proto.View{
+		Height: snapshot.View.Height,
+		Round:  snapshot.View.Round,
+	}
+
+	s.latestPC = snapshot.LatestPC
+	s.latestPreparedProposedBlock = snapshot.LatestPreparedProposedBlock
+	s.proposalMessage = snapshot.ProposalMessage
+
+	if s.proposalMessage != nil {
+		// The proposal for the round was already accepted,
+		// resume from the prepare state
+		s.name = prepare
+		s.roundStarted = true
+
+		// The proposal was already prepared in the round (the COMMIT
+		// message was persisted), resume from the commit state
+		if s.latestPC != nil && s.latestPC.GetProposalMessage().GetView().GetRound() == s.view.Round {
+			s.name = commit
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"time"
+
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// MessageCounts are the numbers of messages held for a round
+type MessageCounts struct {
+	Prepare     int
+	Commit      int
+	RoundChange int
+}
+
+// Status is the snapshot of the consensus state of the node
+type Status struct {
+	// View is the current view (height, round)
+	View *proto.View
+
+	// State is the name of the current state
+	// ("new round", "prepare", "commit" or "fin")
+	State string
+
+	// ProposalAccepted is set if a proposal is accepted for the current round
+	ProposalAccepted bool
+
+	// ProposalHash is the hash of the accepted proposal, if any
+	ProposalHash []byte
+
+	// LatestPCRound is the round of the latest prepared
+	// certificate. It is nil if the node has not prepared
+	LatestPCRound *uint64
+
+	// Messages are the numbers of messages held
+	// for each round of the current height
+	Messages map[uint64]MessageCounts
+
+	// RoundTimeout is the time remaining until the round timer
+	// of the current round expires. It is zero if the timer is not running
+	RoundTimeout time.Duration
+}
+
+// Status returns the snapshot of the consensus state of the node.
+// The state fields are read atomically; the message counts are read
+// right after them, and may include messages received in the meantime
+func (i *IBFT) Status() *Status {
+	status := i.state.status()
+
+	status.Messages = make(map[uint64]MessageCounts)
+
+	for _, message := range i.messages.GetHeightMessages(status.View.Height) {
+		counts := status.Messages[message.View.Round]
+
+		switch message.Type {
+		case proto.MessageType_PREPARE:
+			counts.Prepare++
+		case proto.MessageType_COMMIT:
+			counts.Commit++
+		case proto.MessageType_ROUND_CHANGE:
+			counts.RoundChange++
+		default:
+			continue
+		}
+
+		status.Messages[message.View.Round] = counts
+	}
+
+	if deadline := i.state.getRoundDeadline(); !deadline.IsZero() {
+		if remaining := deadline.Sub(i.clock.Now()); remaining > 0 {
+			status.RoundTimeout = remaining
+		}
+	}
+
+	return status
+}
+
+// HeldMessages returns the messages of all types held
+// for the specified height, across all rounds
+func (i *IBFT) HeldMessages(height uint64) []*proto.Message {
+	return i.messages.GetHeightMessages(height)
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIBFT_Status(t *testing.T) {
+	t.Parallel()
+
+	var (
+		proposalHash = []byte("proposal hash")
+		view         = &proto.View{Height: 10, Round: 2}
+		pcRound      = uint64(1)
+	)
+
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+	i.state.setView(view)
+
+	// Make sure the status of the fresh round is reported
+	status := i.Status()
+
+	assert.Equal(t, view, status.View)
+	assert.Equal(t, "new round", status.State)
+	assert.False(t, status.ProposalAccepted)
+	assert.Nil(t, status.ProposalHash)
+	assert.Nil(t, status.LatestPCRound)
+	assert.Empty(t, status.Messages)
+	assert.Zero(t, status.RoundTimeout)
+
+	// Accept a proposal, prepare it, and receive some messages
+	i.acceptProposal(buildBasicPreprepareMessage([]byte("proposal"), proposalHash, nil, nil, view))
+	i.state.finalizePrepare(
+		&proto.PreparedCertificate{
+			ProposalMessage: buildBasicPreprepareMessage(
+				[]byte("proposal"),
+				proposalHash,
+				nil,
+				nil,
+				&proto.View{Height: view.Height, Round: pcRound},
+			),
+		},
+		[]byte("proposal"),
+	)
+
+	for _, from := range []string{"node 0", "node 1"} {
+		i.messages.AddMessage(buildBasicPrepareMessage(proposalHash, []byte(from), view))
+	}
+
+	i.messages.AddMessage(buildBasicCommitMessage(proposalHash, []byte("seal"), []byte("node 0"), view))
+	i.messages.AddMessage(buildBasicRoundChangeMessage(
+		nil,
+		nil,
+		&proto.View{Height: view.Height, Round: 3},
+		[]byte("node 2"),
+	))
+
+	// Messages for other heights are not counted
+	i.messages.AddMessage(buildBasicPrepareMessage(proposalHash, []byte("node 0"), &proto.View{Height: 11}))
+
+	status = i.Status()
+
+	assert.Equal(t, "commit", status.State)
+	assert.True(t, status.ProposalAccepted)
+	assert.Equal(t, proposalHash, status.ProposalHash)
+
+	if assert.NotNil(t, status.LatestPCRound) {
+		assert.Equal(t, pcRound, *status.LatestPCRound)
+	}
+
+	assert.Equal(
+		t,
+		map[uint64]MessageCounts{
+			2: {Prepare: 2, Commit: 1},
+			3: {RoundChange: 1},
+		},
+		status.Messages,
+	)
+}
+
+func TestIBFT_Status_RoundTimeout(t *testing.T) {
+	t.Parallel()
+
+	clock := newMockClock()
+
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{}, WithClock(clock))
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	i.wg.Add(1)
+
+	go i.startRoundTimer(ctx, 0)
+
+	clock.waitForTimer(round0Timeout)
+	clock.Advance(round0Timeout / 4)
+
+	// Make sure the remaining time of the running timer is reported
+	assert.Equal(t, round0Timeout*3/4, i.Status().RoundTimeout)
+
+	cancelFn()
+	i.wg.Wait()
+
+	assert.Zero(t, i.Status().RoundTimeout)
+}
+
+// TestIBFT_Status_NewHeight makes sure the round timing
+// of the previous height is not carried over
+func TestIBFT_Status_NewHeight(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = newMockClock()
+		now   = clock.Now()
+	)
+
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{}, WithClock(clock))
+
+	i.state.setRoundStart(now)
+	i.state.setRoundDeadline(now.Add(round0Timeout))
+	i.state.setPrepareLatency(now.Add(time.Second))
+	i.state.setCommitLatency(now.Add(2 * time.Second))
+
+	i.state.clear(2)
+
+	assert.Zero(t, i.Status().RoundTimeout)
+	assert.Zero(t, i.state.getLatency())
+	assert.True(t, i.state.roundStart.IsZero())
+}
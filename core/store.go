@@ -0,0 +1,47 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+var (
+	// ErrSnapshotNotFound is returned by the state store
+	// when there is no persisted snapshot
+	ErrSnapshotNotFound = errors.New("state snapshot not found")
+
+	// ErrPersistState is returned by RunSequence when the state store fails
+	// to persist the state the node is about to commit to on the network.
+	// The message is not sent, and the sequence is stopped
+	ErrPersistState = errors.New("unable to persist state")
+)
+
+// StateSnapshot is the part of the consensus state
+// that needs to survive a node restart
+type StateSnapshot struct {
+	// View is the latest view (height, round) the node participated in
+	View *proto.View
+
+	// LatestPC is the latest prepared certificate
+	LatestPC *proto.PreparedCertificate
+
+	// LatestPreparedProposedBlock is the block
+	// for which the latest PC was formed
+	LatestPreparedProposedBlock []byte
+
+	// ProposalMessage is the accepted proposal for the view, if any
+	ProposalMessage *proto.Message
+}
+
+// StateStore defines the persistence layer (write-ahead log)
+// the node uses to store its consensus state before
+// committing to it on the network
+type StateStore interface {
+	// Save durably persists the state snapshot
+	Save(snapshot *StateSnapshot) error
+
+	// Load returns the latest persisted state snapshot,
+	// or ErrSnapshotNotFound if there is none
+	Load() (*StateSnapshot, error)
+}
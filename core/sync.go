@@ -0,0 +1,169 @@
+package core
+
+import (
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// Syncer is the optional Backend extension that is notified
+// when the node falls behind the rest of the network
+type Syncer interface {
+	// Sync is called with the height the network is known to have
+	// finalized, and the (valid sender) messages proving it.
+	// It is called from AddMessage, so it should not block
+	Sync(height uint64, evidence []*proto.Message)
+}
+
+// futureTally is the running tally of the messages for a future height.
+// It is updated on each message, so the height can be checked
+// without scanning the stored messages
+type futureTally struct {
+	// senders are the first messages of the distinct senders
+	senders map[string]*proto.Message
+
+	// commits are the COMMIT messages by round and proposal hash,
+	// and sender
+	commits map[commitKey]map[string]*proto.Message
+}
+
+// commitKey is the round and the proposal hash a COMMIT message is for
+type commitKey struct {
+	round        uint64
+	proposalHash string
+}
+
+// checkFutureHeight checks if the message for a future height, along
+// with the previously received ones, proves that the node fell behind,
+// and triggers the sync if so. The network is known to have finalized:
+//
+// - the future height, if a quorum of COMMIT messages with valid
+// committed seals for the same round and proposal hash is present
+//
+// - the height preceding the future height, if F+1 distinct
+// senders sent messages for it (at least one honest node moved on)
+func (i *IBFT) checkFutureHeight(message *proto.Message) {
+	syncer, ok := i.backend.(Syncer)
+	if !ok {
+		return
+	}
+
+	currentHeight := i.state.getHeight()
+	if message.View.Height <= currentHeight {
+		return
+	}
+
+	target, evidence := i.tallyFutureMessage(currentHeight, message)
+	if target <= currentHeight || !i.setSyncTarget(target) {
+		return
+	}
+
+	i.log.Info("node is behind, triggering sync", "height", currentHeight, "target", target)
+
+	syncer.Sync(target, evidence)
+}
+
+// tallyFutureMessage adds the message to the tally of its height, and returns
+// the height the tally proves is finalized, along with the proving messages.
+// The thresholds are only checked when a new sender is tallied.
+// COMMIT messages only count toward the quorum if their committed seal is
+// valid, but they count as senders regardless
+func (i *IBFT) tallyFutureMessage(currentHeight uint64, message *proto.Message) (uint64, []*proto.Message) {
+	commitData := message.GetCommitData()
+	validCommit := message.Type == proto.MessageType_COMMIT &&
+		commitData != nil &&
+		i.backend.IsValidCommittedSeal(commitData.ProposalHash, messages.ExtractCommittedSeal(message))
+
+	i.syncLock.Lock()
+	defer i.syncLock.Unlock()
+
+	i.pruneFutureTallies(currentHeight)
+
+	var (
+		height = message.View.Height
+		from   = string(message.From)
+	)
+
+	tally, exists := i.futureTallies[height]
+	if !exists {
+		tally = &futureTally{
+			senders: make(map[string]*proto.Message),
+			commits: make(map[commitKey]map[string]*proto.Message),
+		}
+
+		i.futureTallies[height] = tally
+	}
+
+	if validCommit {
+		key := commitKey{
+			round:        message.View.Round,
+			proposalHash: string(commitData.ProposalHash),
+		}
+
+		committers, exists := tally.commits[key]
+		if !exists {
+			committers = make(map[string]*proto.Message)
+			tally.commits[key] = committers
+		}
+
+		if _, committed := committers[from]; !committed {
+			committers[from] = message
+
+			if committed := messageValues(committers); i.hasQuorum(height, committed) {
+				return height, committed
+			}
+		}
+	}
+
+	if _, exists := tally.senders[from]; exists {
+		return 0, nil
+	}
+
+	tally.senders[from] = message
+
+	if senderMsgs := messageValues(tally.senders); i.hasHonestSender(height, senderMsgs) {
+		return height - 1, senderMsgs
+	}
+
+	return 0, nil
+}
+
+// pruneFutureTallies removes the tallies for the heights
+// that are no longer in the future
+func (i *IBFT) pruneFutureTallies(currentHeight uint64) {
+	if currentHeight <= i.tallyHeight {
+		return
+	}
+
+	for height := range i.futureTallies {
+		if height <= currentHeight {
+			delete(i.futureTallies, height)
+		}
+	}
+
+	i.tallyHeight = currentHeight
+}
+
+// setSyncTarget records the sync target, if it is greater
+// than the previously triggered one
+func (i *IBFT) setSyncTarget(target uint64) bool {
+	i.syncLock.Lock()
+	defer i.syncLock.Unlock()
+
+	if target <= i.syncTarget {
+		return false
+	}
+
+	i.syncTarget = target
+
+	return true
+}
+
+// messageValues returns the messages from the sender -> message map
+func messageValues(messageMap map[string]*proto.Message) []*proto.Message {
+	msgs := make([]*proto.Message, 0, len(messageMap))
+	for _, msg := range messageMap {
+		msgs = append(msgs, msg)
+	}
+
+	return msgs
+}
@@ -0,0 +1,334 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockSyncerBackend is the mock backend
+// that implements the Syncer extension
+type mockSyncerBackend struct {
+	mockBackend
+
+	syncFn func(uint64, []*proto.Message)
+}
+
+func (m mockSyncerBackend) Sync(height uint64, evidence []*proto.Message) {
+	m.syncFn(height, evidence)
+}
+
+// invalidSeal is the committed seal rejected by the syncer node
+var invalidSeal = []byte("invalid seal")
+
+// syncCall is a single recorded Sync call
+type syncCall struct {
+	height   uint64
+	evidence []*proto.Message
+}
+
+// newSyncerNode creates a new IBFT instance (N = 4, F = 1)
+// on the specified height, that records the Sync calls.
+// All committed seals are valid, except for invalidSeal
+func newSyncerNode(height uint64) (*IBFT, *[]syncCall) {
+	calls := make([]syncCall, 0)
+
+	backend := mockSyncerBackend{
+		mockBackend: mockBackend{
+			quorumFn: func(_ uint64) uint64 {
+				return 3
+			},
+			maximumFaultyNodesFn: func() uint64 {
+				return 1
+			},
+			isValidCommittedSealFn: func(_ []byte, seal *messages.CommittedSeal) bool {
+				return !bytes.Equal(seal.Signature, invalidSeal)
+			},
+		},
+		syncFn: func(height uint64, evidence []*proto.Message) {
+			calls = append(calls, syncCall{height, evidence})
+		},
+	}
+
+	i := NewIBFT(mockLogger{}, backend, mockTransport{})
+	i.state.setView(&proto.View{
+		Height: height,
+		Round:  0,
+	})
+
+	return i, &calls
+}
+
+func TestIBFT_SyncTrigger(t *testing.T) {
+	t.Parallel()
+
+	var (
+		currentHeight = uint64(10)
+		nodes         = generateNodeAddresses(4)
+	)
+
+	view := func(height, round uint64) *proto.View {
+		return &proto.View{
+			Height: height,
+			Round:  round,
+		}
+	}
+
+	testTable := []struct {
+		name           string
+		messages       []*proto.Message
+		expectedHeight uint64
+		expectedCount  int
+	}{
+		{
+			"F+1 senders two heights ahead",
+			[]*proto.Message{
+				buildBasicPrepareMessage([]byte("hash"), nodes[0], view(currentHeight+2, 0)),
+				buildBasicRoundChangeMessage(nil, nil, view(currentHeight+2, 1), nodes[1]),
+			},
+			currentHeight + 1,
+			2,
+		},
+		{
+			"F+1 senders one height ahead",
+			[]*proto.Message{
+				buildBasicPrepareMessage([]byte("hash"), nodes[0], view(currentHeight+1, 0)),
+				buildBasicPrepareMessage([]byte("hash"), nodes[1], view(currentHeight+1, 0)),
+			},
+			0,
+			0,
+		},
+		{
+			"single sender two heights ahead",
+			[]*proto.Message{
+				buildBasicPrepareMessage([]byte("hash"), nodes[0], view(currentHeight+2, 0)),
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[0], view(currentHeight+2, 0)),
+			},
+			0,
+			0,
+		},
+		{
+			"quorum of commits one height ahead",
+			[]*proto.Message{
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[0], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[1], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[2], view(currentHeight+1, 1)),
+			},
+			currentHeight + 1,
+			3,
+		},
+		{
+			"quorum of commits with an invalid seal",
+			[]*proto.Message{
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[0], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash"), invalidSeal, nodes[1], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[2], view(currentHeight+1, 1)),
+			},
+			0,
+			0,
+		},
+		{
+			"quorum of valid commits among invalid ones",
+			[]*proto.Message{
+				buildBasicCommitMessage([]byte("hash"), invalidSeal, nodes[0], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[1], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[2], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[3], view(currentHeight+1, 1)),
+			},
+			currentHeight + 1,
+			3,
+		},
+		{
+			"commits for different proposals",
+			[]*proto.Message{
+				buildBasicCommitMessage([]byte("hash 1"), []byte("seal"), nodes[0], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash 2"), []byte("seal"), nodes[1], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash 1"), []byte("seal"), nodes[2], view(currentHeight+1, 1)),
+			},
+			0,
+			0,
+		},
+		{
+			"commits for different rounds",
+			[]*proto.Message{
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[0], view(currentHeight+1, 0)),
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[1], view(currentHeight+1, 1)),
+				buildBasicCommitMessage([]byte("hash"), []byte("seal"), nodes[2], view(currentHeight+1, 1)),
+			},
+			0,
+			0,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			i, calls := newSyncerNode(currentHeight)
+
+			for _, message := range testCase.messages {
+				i.AddMessage(message)
+			}
+
+			if testCase.expectedCount == 0 {
+				assert.Len(t, *calls, 0)
+
+				return
+			}
+
+			// Make sure the sync was triggered once, with the proving messages
+			if assert.Len(t, *calls, 1) {
+				assert.Equal(t, testCase.expectedHeight, (*calls)[0].height)
+				assert.Len(t, (*calls)[0].evidence, testCase.expectedCount)
+			}
+		})
+	}
+}
+
+// TestIBFT_SyncTriggerOnce makes sure the sync is triggered
+// only once for the same target height
+func TestIBFT_SyncTriggerOnce(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes    = generateNodeAddresses(4)
+		i, calls = newSyncerNode(1)
+	)
+
+	for _, node := range nodes {
+		i.AddMessage(buildBasicPrepareMessage([]byte("hash"), node, &proto.View{Height: 5}))
+	}
+
+	// Make sure a higher target triggers the sync again
+	for _, node := range nodes[:2] {
+		i.AddMessage(buildBasicPrepareMessage([]byte("hash"), node, &proto.View{Height: 7}))
+	}
+
+	if assert.Len(t, *calls, 2) {
+		assert.Equal(t, uint64(4), (*calls)[0].height)
+		assert.Equal(t, uint64(6), (*calls)[1].height)
+	}
+}
+
+// TestIBFT_SyncTriggerHigherRound makes sure a node stuck in a high
+// round still detects the network moved on to higher heights
+func TestIBFT_SyncTriggerHigherRound(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodes    = generateNodeAddresses(4)
+		i, calls = newSyncerNode(10)
+	)
+
+	i.state.setView(&proto.View{Height: 10, Round: 5})
+
+	for _, node := range nodes[:2] {
+		i.AddMessage(buildBasicPrepareMessage(
+			[]byte("hash"),
+			node,
+			&proto.View{Height: 12, Round: 0},
+		))
+	}
+
+	if assert.Len(t, *calls, 1) {
+		assert.Equal(t, uint64(11), (*calls)[0].height)
+	}
+}
+
+// TestIBFT_SyncTriggerNoSyncer makes sure future messages are
+// stored as before if the backend doesn't implement the Syncer
+func TestIBFT_SyncTriggerNoSyncer(t *testing.T) {
+	t.Parallel()
+
+	var (
+		added []*proto.Message
+		nodes = generateNodeAddresses(4)
+	)
+
+	i := NewIBFT(mockLogger{}, mockBackend{}, mockTransport{})
+	i.messages = mockMessages{
+		addMessageFn: func(message *proto.Message) {
+			added = append(added, message)
+		},
+		getHeightMessagesFn: func(_ uint64) []*proto.Message {
+			t.Fatal("future height checked without a syncer")
+
+			return nil
+		},
+	}
+
+	for _, node := range nodes {
+		i.AddMessage(buildBasicPrepareMessage([]byte("hash"), node, &proto.View{Height: 5}))
+	}
+
+	assert.Len(t, added, len(nodes))
+}
+
+// TestIBFT_SyncTriggerIncremental makes sure the future height messages
+// are tallied as they arrive, and the thresholds are only
+// checked when a new sender is tallied
+func TestIBFT_SyncTriggerIncremental(t *testing.T) {
+	t.Parallel()
+
+	var (
+		checks uint64
+		nodes  = generateNodeAddresses(4)
+		calls  = make([]syncCall, 0)
+	)
+
+	backend := mockSyncerBackend{
+		mockBackend: mockBackend{
+			maximumFaultyNodesFn: func() uint64 {
+				checks++
+
+				return 1
+			},
+		},
+		syncFn: func(height uint64, evidence []*proto.Message) {
+			calls = append(calls, syncCall{height, evidence})
+		},
+	}
+
+	i := NewIBFT(mockLogger{}, backend, mockTransport{})
+	i.state.setView(&proto.View{Height: 10})
+	i.messages = mockMessages{
+		getHeightMessagesFn: func(_ uint64) []*proto.Message {
+			t.Fatal("stored messages scanned for the future height")
+
+			return nil
+		},
+	}
+
+	// Make sure repeated messages from the same sender aren't rechecked
+	for round := uint64(0); round < 10; round++ {
+		i.AddMessage(buildBasicPrepareMessage(
+			[]byte("hash"),
+			nodes[0],
+			&proto.View{Height: 12, Round: round},
+		))
+	}
+
+	assert.Equal(t, uint64(1), checks)
+	assert.Len(t, calls, 0)
+
+	i.AddMessage(buildBasicPrepareMessage([]byte("hash"), nodes[1], &proto.View{Height: 12}))
+
+	assert.Equal(t, uint64(2), checks)
+
+	if assert.Len(t, calls, 1) {
+		assert.Equal(t, uint64(11), calls[0].height)
+		assert.Len(t, calls[0].evidence, 2)
+	}
+
+	// Make sure the tallies are pruned once the heights are reached
+	i.state.setView(&proto.View{Height: 12})
+	i.AddMessage(buildBasicPrepareMessage([]byte("hash"), nodes[0], &proto.View{Height: 13}))
+
+	assert.Len(t, i.futureTallies, 1)
+	assert.Contains(t, i.futureTallies, uint64(13))
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"math"
+	"time"
+)
+
+// maxRoundTimeout is the upper bound for any round timeout
+const maxRoundTimeout = time.Duration(math.MaxInt64)
+
+// RoundTimeoutPolicy defines how long each round of consensus lasts
+type RoundTimeoutPolicy interface {
+	// RoundTimeout returns the timeout for the specified round
+	RoundTimeout(round uint64) time.Duration
+}
+
+// SequenceLatency contains the times it took to reach the
+// consensus quorums, measured from the start of the finalizing round
+type SequenceLatency struct {
+	// Prepare is the time until the PREPARE quorum was reached
+	Prepare time.Duration
+
+	// Commit is the time until the COMMIT quorum was reached
+	Commit time.Duration
+}
+
+// LatencyObserver is implemented by round timeout policies
+// that adapt to the observed consensus latency
+type LatencyObserver interface {
+	// ObserveLatency is called after each successful sequence
+	ObserveLatency(latency SequenceLatency)
+}
+
+// RoundTimeoutFunc is an adapter that allows the use of
+// ordinary functions as round timeout policies
+type RoundTimeoutFunc func(round uint64) time.Duration
+
+// RoundTimeout returns the timeout for the specified round
+func (f RoundTimeoutFunc) RoundTimeout(round uint64) time.Duration {
+	return f(round)
+}
+
+// ExponentialRoundTimeout is the round timeout policy
+// where the timeout doubles with each round (base * 2^round)
+type ExponentialRoundTimeout struct {
+	base       time.Duration
+	maxTimeout time.Duration
+}
+
+// NewExponentialRoundTimeout creates a new exponential round timeout policy.
+// The timeout never exceeds maxTimeout, unless it is 0, in which case it is unbounded
+func NewExponentialRoundTimeout(base, maxTimeout time.Duration) *ExponentialRoundTimeout {
+	return &ExponentialRoundTimeout{
+		base:       base,
+		maxTimeout: maxTimeout,
+	}
+}
+
+// RoundTimeout returns the timeout for the specified round
+func (p *ExponentialRoundTimeout) RoundTimeout(round uint64) time.Duration {
+	limit := timeoutLimit(p.maxTimeout)
+
+	if p.base <= 0 {
+		return 0
+	}
+
+	// Make sure base * 2^round doesn't overflow the limit
+	if round >= 63 || p.base > limit>>round {
+		return limit
+	}
+
+	return p.base << round
+}
+
+// LinearRoundTimeout is the round timeout policy
+// where the timeout grows by a fixed increment with each round
+type LinearRoundTimeout struct {
+	base       time.Duration
+	increment  time.Duration
+	maxTimeout time.Duration
+}
+
+// NewLinearRoundTimeout creates a new linear round timeout policy (base + round * increment).
+// The timeout never exceeds maxTimeout, unless it is 0, in which case it is unbounded
+func NewLinearRoundTimeout(base, increment, maxTimeout time.Duration) *LinearRoundTimeout {
+	return &LinearRoundTimeout{
+		base:       base,
+		increment:  increment,
+		maxTimeout: maxTimeout,
+	}
+}
+
+// RoundTimeout returns the timeout for the specified round
+func (p *LinearRoundTimeout) RoundTimeout(round uint64) time.Duration {
+	limit := timeoutLimit(p.maxTimeout)
+
+	if p.base >= limit {
+		return limit
+	}
+
+	if p.increment <= 0 {
+		return p.base
+	}
+
+	// Make sure base + round * increment doesn't overflow the limit
+	if round > uint64((limit-p.base)/p.increment) {
+		return limit
+	}
+
+	return p.base + time.Duration(round)*p.increment
+}
+
+// ConstantRoundTimeout is the round timeout policy
+// where every round has the same timeout
+type ConstantRoundTimeout struct {
+	timeout time.Duration
+}
+
+// NewConstantRoundTimeout creates a new constant round timeout policy
+func NewConstantRoundTimeout(timeout time.Duration) *ConstantRoundTimeout {
+	return &ConstantRoundTimeout{
+		timeout: timeout,
+	}
+}
+
+// RoundTimeout returns the timeout for the specified round
+func (p *ConstantRoundTimeout) RoundTimeout(_ uint64) time.Duration {
+	return p.timeout
+}
+
+// timeoutLimit returns the effective upper bound for a round timeout
+func timeoutLimit(maxTimeout time.Duration) time.Duration {
+	if maxTimeout <= 0 {
+		return maxRoundTimeout
+	}
+
+	return maxTimeout
+}
+
+// addTimeouts adds up the timeouts, without overflowing
+func addTimeouts(a, b time.Duration) time.Duration {
+	if b > 0 && a > maxRoundTimeout-b {
+		return maxRoundTimeout
+	}
+
+	return a + b
+}
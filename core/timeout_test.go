@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundTimeoutPolicy_RoundTimeout(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name     string
+		policy   RoundTimeoutPolicy
+		round    uint64
+		expected time.Duration
+	}{
+		{
+			"exponential round 0",
+			NewExponentialRoundTimeout(10*time.Second, 0),
+			0,
+			10 * time.Second,
+		},
+		{
+			"exponential round 3",
+			NewExponentialRoundTimeout(10*time.Second, 0),
+			3,
+			80 * time.Second,
+		},
+		{
+			"exponential capped",
+			NewExponentialRoundTimeout(10*time.Second, time.Minute),
+			3,
+			time.Minute,
+		},
+		{
+			"exponential base over the cap",
+			NewExponentialRoundTimeout(10*time.Second, time.Second),
+			0,
+			time.Second,
+		},
+		{
+			"exponential high round does not overflow",
+			NewExponentialRoundTimeout(10*time.Second, 0),
+			40,
+			maxRoundTimeout,
+		},
+		{
+			"exponential huge round does not overflow",
+			NewExponentialRoundTimeout(time.Nanosecond, time.Hour),
+			1 << 40,
+			time.Hour,
+		},
+		{
+			"linear round 0",
+			NewLinearRoundTimeout(10*time.Second, 5*time.Second, 0),
+			0,
+			10 * time.Second,
+		},
+		{
+			"linear round 4",
+			NewLinearRoundTimeout(10*time.Second, 5*time.Second, 0),
+			4,
+			30 * time.Second,
+		},
+		{
+			"linear capped",
+			NewLinearRoundTimeout(10*time.Second, 5*time.Second, 20*time.Second),
+			4,
+			20 * time.Second,
+		},
+		{
+			"linear huge round does not overflow",
+			NewLinearRoundTimeout(10*time.Second, time.Hour, 0),
+			1 << 62,
+			maxRoundTimeout,
+		},
+		{
+			"constant",
+			NewConstantRoundTimeout(3 * time.Second),
+			100,
+			3 * time.Second,
+		},
+		{
+			"custom",
+			RoundTimeoutFunc(func(round uint64) time.Duration {
+				return time.Duration(round*round) * time.Second
+			}),
+			3,
+			9 * time.Second,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, testCase.policy.RoundTimeout(testCase.round))
+		})
+	}
+}
+
+func TestAddTimeouts(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 3*time.Second, addTimeouts(time.Second, 2*time.Second))
+	assert.Equal(t, maxRoundTimeout, addTimeouts(maxRoundTimeout, time.Second))
+}
+
+// TestIBFT_RoundTimeoutPolicy makes sure the round timer
+// uses the configured round timeout policy
+func TestIBFT_RoundTimeoutPolicy(t *testing.T) {
+	t.Parallel()
+
+	var (
+		round         = uint64(5)
+		receivedRound = make(chan uint64, 1)
+
+		log       = mockLogger{}
+		transport = mockTransport{}
+		backend   = mockBackend{}
+		policy    = RoundTimeoutFunc(func(round uint64) time.Duration {
+			receivedRound <- round
+
+			return 0
+		})
+	)
+
+	i := NewIBFT(log, backend, transport, WithRoundTimeoutPolicy(policy))
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	expired := make(chan struct{})
+
+	go func() {
+		defer close(expired)
+
+		select {
+		case <-i.roundExpired:
+		case <-time.After(5 * time.Second):
+		}
+	}()
+
+	i.wg.Add(1)
+	i.startRoundTimer(ctx, round)
+
+	<-expired
+
+	// Make sure the policy was queried for the correct round
+	assert.Equal(t, round, <-receivedRound)
+}
@@ -0,0 +1,275 @@
+// Package debug serves the consensus state of a node over HTTP, for
+// introspection during incidents. The handler serves the endpoints:
+//
+//	/status       the current status of the node (core.Status)
+//	/messages     the messages held for each round of the current height,
+//	              or of the height in the "height" query parameter. The
+//	              current round is listed even if no message is held for it
+//	/transitions  the most recent state transitions (see Recorder)
+//
+// The handler can be mounted on an existing mux under a prefix:
+//
+//	mux.Handle("/debug/ibft/", http.StripPrefix("/debug/ibft", handler))
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/nubank/go-ibft/core"
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Node is the consensus node introspected by the handler (core.IBFT)
+type Node interface {
+	// Status returns the snapshot of the consensus state
+	Status() *core.Status
+
+	// HeldMessages returns the messages held for the height
+	HeldMessages(height uint64) []*proto.Message
+}
+
+// ValidatorSetFunc returns the validator set for the height
+type ValidatorSetFunc func(height uint64) messages.ValidatorSet
+
+// Option is the configuration option of the Handler
+type Option func(*Handler)
+
+// WithRecorder sets the recorder of the served state transitions.
+// The recorder should be set as the observer of the node
+func WithRecorder(recorder *Recorder) Option {
+	return func(h *Handler) {
+		h.recorder = recorder
+	}
+}
+
+// WithValidatorSet sets the source of the validator sets, used
+// to report the validators whose PREPAREs or COMMITs are missing
+func WithValidatorSet(validatorSet ValidatorSetFunc) Option {
+	return func(h *Handler) {
+		h.validatorSet = validatorSet
+	}
+}
+
+// RoundMessages are the messages held for a round
+type RoundMessages struct {
+	Height uint64
+	Round  uint64
+
+	// Messages are the held messages, encoded as protobuf JSON
+	Messages []json.RawMessage
+
+	// MissingPrepares and MissingCommits are the validators whose PREPAREs
+	// or COMMITs are not held. The sender of the held PREPREPARE is not
+	// missing a PREPARE, as the proposal counts toward the prepare quorum.
+	// Set only if the validator set is known
+	MissingPrepares [][]byte `json:",omitempty"`
+	MissingCommits  [][]byte `json:",omitempty"`
+}
+
+// Handler is the http.Handler serving the consensus state of the node
+type Handler struct {
+	node         Node
+	recorder     *Recorder
+	validatorSet ValidatorSetFunc
+
+	mux *http.ServeMux
+}
+
+// NewHandler creates a new handler serving the consensus state of the node
+func NewHandler(node Node, opts ...Option) *Handler {
+	h := &Handler{
+		node: node,
+		mux:  http.NewServeMux(),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.mux.HandleFunc("/status", h.serveStatus)
+	h.mux.HandleFunc("/messages", h.serveMessages)
+	h.mux.HandleFunc("/transitions", h.serveTransitions)
+
+	return h
+}
+
+// ServeHTTP serves the debug endpoints
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	h.mux.ServeHTTP(w, r)
+}
+
+// serveStatus serves the current status of the node
+func (h *Handler) serveStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, h.node.Status())
+}
+
+// serveMessages serves the messages held for each round of the height
+func (h *Handler) serveMessages(w http.ResponseWriter, r *http.Request) {
+	var (
+		current = h.node.Status().View
+		height  = current.GetHeight()
+	)
+
+	if param := r.URL.Query().Get("height"); param != "" {
+		parsed, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid height", http.StatusBadRequest)
+
+			return
+		}
+
+		height = parsed
+	}
+
+	rounds, err := h.roundMessages(height, current)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, rounds)
+}
+
+// serveTransitions serves the most recent state transitions
+func (h *Handler) serveTransitions(w http.ResponseWriter, _ *http.Request) {
+	if h.recorder == nil {
+		http.Error(w, "transitions are not recorded", http.StatusNotFound)
+
+		return
+	}
+
+	writeJSON(w, h.recorder.Transitions())
+}
+
+// roundMessages groups the messages held for the height by round.
+// The round of the current view is always included, even if no message
+// is held for it, so the validators it is waiting for are reported
+func (h *Handler) roundMessages(height uint64, current *proto.View) ([]*RoundMessages, error) {
+	var (
+		byRound = make(map[uint64]*RoundMessages)
+		senders = make(map[uint64]map[proto.MessageType]map[string]struct{})
+	)
+
+	addRound := func(round uint64) {
+		if _, exists := byRound[round]; exists {
+			return
+		}
+
+		byRound[round] = &RoundMessages{
+			Height:   height,
+			Round:    round,
+			Messages: make([]json.RawMessage, 0),
+		}
+		senders[round] = make(map[proto.MessageType]map[string]struct{})
+	}
+
+	if current != nil && current.Height == height {
+		addRound(current.Round)
+	}
+
+	heldMessages := h.node.HeldMessages(height)
+
+	// Order the messages for a stable output
+	sort.Slice(heldMessages, func(i, j int) bool {
+		if heldMessages[i].Type != heldMessages[j].Type {
+			return heldMessages[i].Type < heldMessages[j].Type
+		}
+
+		return string(heldMessages[i].From) < string(heldMessages[j].From)
+	})
+
+	for _, message := range heldMessages {
+		round := message.View.Round
+
+		addRound(round)
+
+		encoded, err := protojson.Marshal(message)
+		if err != nil {
+			return nil, err
+		}
+
+		byRound[round].Messages = append(byRound[round].Messages, encoded)
+
+		if senders[round][message.Type] == nil {
+			senders[round][message.Type] = make(map[string]struct{})
+		}
+
+		senders[round][message.Type][string(message.From)] = struct{}{}
+	}
+
+	validators := h.getValidatorSet(height)
+
+	rounds := make([]*RoundMessages, 0, len(byRound))
+	for round, roundMessages := range byRound {
+		if validators != nil {
+			roundMessages.MissingPrepares = missingSenders(
+				validators,
+				senders[round][proto.MessageType_PREPARE],
+				senders[round][proto.MessageType_PREPREPARE],
+			)
+			roundMessages.MissingCommits = missingSenders(validators, senders[round][proto.MessageType_COMMIT])
+		}
+
+		rounds = append(rounds, roundMessages)
+	}
+
+	sort.Slice(rounds, func(i, j int) bool {
+		return rounds[i].Round < rounds[j].Round
+	})
+
+	return rounds, nil
+}
+
+// getValidatorSet returns the validator set for the height, if known
+func (h *Handler) getValidatorSet(height uint64) messages.ValidatorSet {
+	if h.validatorSet == nil {
+		return nil
+	}
+
+	return h.validatorSet(height)
+}
+
+// missingSenders returns the validators that are not
+// among the senders of any of the message types
+func missingSenders(validators messages.ValidatorSet, senders ...map[string]struct{}) [][]byte {
+	missing := make([][]byte, 0)
+
+	for _, validator := range validators.Validators() {
+		if !isSender(validator.ID, senders) {
+			missing = append(missing, validator.ID)
+		}
+	}
+
+	return missing
+}
+
+// isSender checks if the ID is among the senders of any of the message types
+func isSender(id []byte, senders []map[string]struct{}) bool {
+	for _, typeSenders := range senders {
+		if _, exists := typeSenders[string(id)]; exists {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeJSON writes the value encoded as JSON
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,289 @@
+package debug
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nubank/go-ibft/core"
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ Node          = (*core.IBFT)(nil)
+	_ core.Observer = (*Recorder)(nil)
+)
+
+// mockNode is the node with a fixed status and held messages
+type mockNode struct {
+	status   *core.Status
+	messages map[uint64][]*proto.Message
+}
+
+func (n mockNode) Status() *core.Status {
+	return n.status
+}
+
+func (n mockNode) HeldMessages(height uint64) []*proto.Message {
+	return n.messages[height]
+}
+
+// fixedClock is the clock stopped at a fixed time
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func (c fixedClock) NewTimer(time.Duration) core.Timer {
+	return nil
+}
+
+func (c fixedClock) After(time.Duration) <-chan time.Time {
+	return nil
+}
+
+// buildVote builds the vote of the sender for the view
+func buildVote(messageType proto.MessageType, from string, height, round uint64) *proto.Message {
+	message := &proto.Message{
+		View: &proto.View{Height: height, Round: round},
+		From: []byte(from),
+		Type: messageType,
+	}
+
+	switch messageType {
+	case proto.MessageType_PREPARE:
+		message.Payload = &proto.Message_PrepareData{
+			PrepareData: &proto.PrepareMessage{ProposalHash: []byte("hash")},
+		}
+	case proto.MessageType_COMMIT:
+		message.Payload = &proto.Message_CommitData{
+			CommitData: &proto.CommitMessage{ProposalHash: []byte("hash")},
+		}
+	case proto.MessageType_PREPREPARE:
+		message.Payload = &proto.Message_PreprepareData{
+			PreprepareData: &proto.PrePrepareMessage{ProposalHash: []byte("hash")},
+		}
+	}
+
+	return message
+}
+
+// get serves the GET request for the path, and returns the response
+func get(t *testing.T, handler http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return recorder
+}
+
+func newMockNode() mockNode {
+	return mockNode{
+		status: &core.Status{
+			View:  &proto.View{Height: 5, Round: 1},
+			State: "prepare",
+		},
+		messages: map[uint64][]*proto.Message{
+			5: {
+				buildVote(proto.MessageType_COMMIT, "b", 5, 1),
+				buildVote(proto.MessageType_PREPARE, "b", 5, 1),
+				buildVote(proto.MessageType_PREPARE, "a", 5, 1),
+				buildVote(proto.MessageType_ROUND_CHANGE, "c", 5, 0),
+				buildVote(proto.MessageType_PREPREPARE, "a", 5, 0),
+			},
+			6: {
+				buildVote(proto.MessageType_PREPARE, "a", 6, 0),
+			},
+		},
+	}
+}
+
+func TestHandler_Status(t *testing.T) {
+	t.Parallel()
+
+	response := get(t, NewHandler(newMockNode()), "/status")
+
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+	var status core.Status
+
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &status))
+	assert.Equal(t, uint64(5), status.View.Height)
+	assert.Equal(t, uint64(1), status.View.Round)
+	assert.Equal(t, "prepare", status.State)
+}
+
+func TestHandler_Messages(t *testing.T) {
+	t.Parallel()
+
+	var (
+		node       = newMockNode()
+		validators = messages.NewEqualValidatorSet([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	)
+
+	handler := NewHandler(
+		node,
+		WithValidatorSet(func(height uint64) messages.ValidatorSet {
+			return validators
+		}),
+	)
+
+	t.Run("current height", func(t *testing.T) {
+		t.Parallel()
+
+		response := get(t, handler, "/messages")
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var rounds []RoundMessages
+
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &rounds))
+		require.Len(t, rounds, 2)
+
+		// Make sure the rounds are ordered, and the missing votes are reported
+		assert.Equal(t, uint64(0), rounds[0].Round)
+		assert.Len(t, rounds[0].Messages, 2)
+
+		// The proposer doesn't send a PREPARE, so it is not missing one
+		assert.Equal(t, [][]byte{[]byte("b"), []byte("c")}, rounds[0].MissingPrepares)
+
+		assert.Equal(t, uint64(1), rounds[1].Round)
+		assert.Len(t, rounds[1].Messages, 3)
+		assert.Equal(t, [][]byte{[]byte("c")}, rounds[1].MissingPrepares)
+		assert.Equal(t, [][]byte{[]byte("a"), []byte("c")}, rounds[1].MissingCommits)
+
+		// Make sure the messages are decoded
+		var message map[string]interface{}
+
+		require.NoError(t, json.Unmarshal(rounds[1].Messages[0], &message))
+		assert.Equal(t, "PREPARE", message["type"])
+		assert.Contains(t, message, "prepareData")
+	})
+
+	t.Run("specified height", func(t *testing.T) {
+		t.Parallel()
+
+		response := get(t, handler, "/messages?height=6")
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var rounds []RoundMessages
+
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &rounds))
+		require.Len(t, rounds, 1)
+		assert.Equal(t, uint64(6), rounds[0].Height)
+	})
+
+	t.Run("current round with no messages", func(t *testing.T) {
+		t.Parallel()
+
+		node := newMockNode()
+		node.status = &core.Status{
+			View:  &proto.View{Height: 5, Round: 2},
+			State: "new round",
+		}
+
+		response := get(t, NewHandler(
+			node,
+			WithValidatorSet(func(height uint64) messages.ValidatorSet {
+				return validators
+			}),
+		), "/messages")
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var rounds []RoundMessages
+
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &rounds))
+		require.Len(t, rounds, 3)
+
+		// Make sure all of the validators are reported missing for the current round
+		assert.Equal(t, uint64(2), rounds[2].Round)
+		assert.Empty(t, rounds[2].Messages)
+		assert.Len(t, rounds[2].MissingPrepares, 3)
+		assert.Len(t, rounds[2].MissingCommits, 3)
+	})
+
+	t.Run("invalid height", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, http.StatusBadRequest, get(t, handler, "/messages?height=x").Code)
+	})
+}
+
+func TestHandler_Transitions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("recorded transitions", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Unix(1000, 0).UTC()
+
+		recorder := NewRecorder(3, WithRecorderClock(fixedClock{now: now}))
+
+		recorder.SequenceStarted(5)
+		recorder.RoundStarted(&proto.View{Height: 5, Round: 0})
+		recorder.RoundTimeout(&proto.View{Height: 5, Round: 0})
+		recorder.RoundStarted(&proto.View{Height: 5, Round: 1})
+		recorder.SequenceDone(5, nil, errors.New("cancelled"))
+
+		response := get(t, NewHandler(newMockNode(), WithRecorder(recorder)), "/transitions")
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var transitions []Transition
+
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &transitions))
+
+		// Make sure only the most recent transitions are kept, in order
+		events := make([]string, len(transitions))
+		for index, transition := range transitions {
+			events[index] = transition.Event
+		}
+
+		assert.Equal(t, []string{"round timeout", "round started", "sequence done"}, events)
+		assert.Equal(t, uint64(1), transitions[1].Round)
+		assert.Equal(t, "cancelled", transitions[2].Error)
+
+		// Make sure the transitions are timed by the clock of the recorder
+		assert.True(t, now.Equal(transitions[0].Time))
+	})
+
+	t.Run("no recorder", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, http.StatusNotFound, get(t, NewHandler(newMockNode()), "/transitions").Code)
+	})
+}
+
+func TestHandler_Mounted(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/ibft/", http.StripPrefix("/debug/ibft", NewHandler(newMockNode())))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/debug/ibft/status")
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Make sure only the GET requests are served
+	response, err = http.Post(server.URL+"/debug/ibft/status", "application/json", nil)
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+}
@@ -0,0 +1,146 @@
+package debug
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nubank/go-ibft/core"
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// DefaultCapacity is the default number of transitions kept by the Recorder
+const DefaultCapacity = 256
+
+// Transition is a recorded consensus state transition
+type Transition struct {
+	// Time is the time the transition was recorded
+	Time time.Time
+
+	// Event is the name of the transition ("round started", "prepared"...)
+	Event string
+
+	// Height and Round are the view of the transition
+	Height uint64
+	Round  uint64
+
+	// Error is the error the sequence ended with, if any
+	Error string `json:",omitempty"`
+}
+
+// RecorderOption is the configuration option of the Recorder
+type RecorderOption func(*Recorder)
+
+// WithRecorderClock sets the source of time of the recorded transitions.
+// It should be the clock of the node, so the transitions line up with
+// its round timers (core.RealClock by default)
+func WithRecorderClock(clock core.Clock) RecorderOption {
+	return func(r *Recorder) {
+		r.clock = clock
+	}
+}
+
+// Recorder is the core.Observer that keeps the most recent state transitions
+type Recorder struct {
+	clock core.Clock
+
+	transitions []Transition
+	next        int
+	full        bool
+	lock        sync.Mutex
+}
+
+// NewRecorder creates a new recorder that keeps up to the
+// capacity of the most recent transitions (DefaultCapacity if not positive)
+func NewRecorder(capacity int, opts ...RecorderOption) *Recorder {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+
+	r := &Recorder{
+		clock:       core.RealClock(),
+		transitions: make([]Transition, capacity),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Transitions returns the recorded transitions, from the oldest to the newest
+func (r *Recorder) Transitions() []Transition {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.full {
+		return append([]Transition{}, r.transitions[:r.next]...)
+	}
+
+	return append(
+		append([]Transition{}, r.transitions[r.next:]...),
+		r.transitions[:r.next]...,
+	)
+}
+
+// record adds the transition, overwriting the oldest one if the recorder is full
+func (r *Recorder) record(event string, height, round uint64, err error) {
+	transition := Transition{
+		Time:   r.clock.Now(),
+		Event:  event,
+		Height: height,
+		Round:  round,
+	}
+
+	if err != nil {
+		transition.Error = err.Error()
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.transitions[r.next] = transition
+
+	if r.next++; r.next == len(r.transitions) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+func (r *Recorder) SequenceStarted(height uint64) {
+	r.record("sequence started", height, 0, nil)
+}
+
+func (r *Recorder) RoundStarted(view *proto.View) {
+	r.record("round started", view.Height, view.Round, nil)
+}
+
+func (r *Recorder) ProposalAccepted(view *proto.View, _ []byte) {
+	r.record("proposal accepted", view.Height, view.Round, nil)
+}
+
+func (r *Recorder) Prepared(view *proto.View, _ *proto.PreparedCertificate) {
+	r.record("prepared", view.Height, view.Round, nil)
+}
+
+func (r *Recorder) Committed(view *proto.View, _ []*messages.CommittedSeal) {
+	r.record("committed", view.Height, view.Round, nil)
+}
+
+func (r *Recorder) RoundTimeout(view *proto.View) {
+	r.record("round timeout", view.Height, view.Round, nil)
+}
+
+func (r *Recorder) RoundChangeCertificateReceived(view *proto.View) {
+	r.record("round change certificate received", view.Height, view.Round, nil)
+}
+
+func (r *Recorder) SequenceDone(height uint64, result *core.SequenceResult, err error) {
+	var round uint64
+	if result != nil {
+		round = result.Round
+	}
+
+	r.record("sequence done", height, round, err)
+}
@@ -3,6 +3,7 @@ module github.com/nubank/go-ibft
 go 1.17
 
 require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.3.0
 	github.com/google/uuid v1.3.0
 	github.com/stretchr/testify v1.8.0
 	google.golang.org/protobuf v1.28.1
@@ -0,0 +1,307 @@
+// Package lightclient verifies the finality of proposals from their commit
+// certificates, without running the consensus state machine.
+//
+// The client starts from a trusted validator set, and follows the validator
+// set changes from their finality proofs. Heights sealed by a known validator
+// set require a quorum of its voting power. Heights past the last known
+// validator set change are verified by skipping: the set sealing them may have
+// changed unnoticed, so the proof carries the sealing validator set, and
+// requires a quorum of it, along with the seals of the last known validators
+// holding more than a third of their voting power (at least one honest
+// validator). The fault bound of the last known validators only holds for
+// a while after they are known, so a height can't be skipped to if it is
+// more than the maximum skip distance past the last known change
+package lightclient
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nubank/go-ibft/core"
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+var (
+	ErrHeightNotTrusted          = errors.New("height is not after the trusted height")
+	ErrStaleValidatorSetChange   = errors.New("validator set change is not after the latest change")
+	ErrInvalidProposalHash       = errors.New("proposal does not match the certificate proposal hash")
+	ErrInvalidProposalHeight     = errors.New("proposal is not for the certificate height")
+	ErrInvalidValidatorSetChange = errors.New("proposal does not commit to the validator set")
+	ErrInsufficientTrust         = errors.New("trusted validators do not hold more than a third of the voting power")
+	ErrSealingSetRequired        = errors.New("sealing validator set is required to verify by skipping")
+	ErrSkipDistanceExceeded      = errors.New("height is past the maximum skip distance from the trusted height")
+)
+
+// DefaultMaxSkipDistance is the default maximum number of heights
+// a certificate can be past the latest validator set change,
+// for it to be verified by skipping
+const DefaultMaxSkipDistance uint64 = 10000
+
+// Verifier is the chain specific verification logic of the light client.
+// It is the core Verifier, extended with the proposal heights
+// and the validator set changes
+type Verifier interface {
+	core.Verifier
+
+	// IsValidProposalHeight checks if the proposal is for the height.
+	// The seals only sign the proposal hash, so the certificate
+	// height is trusted only if the proposal commits to it
+	IsValidProposalHeight(proposal []byte, height uint64) bool
+
+	// IsValidValidatorSetChange checks if the finalized proposal commits to
+	// the validator set, which seals the heights following the proposal
+	IsValidValidatorSetChange(proposal []byte, validators messages.ValidatorSet) bool
+}
+
+// ValidatorSetChange is the proof that the validator set
+// changes after the height of the finalized proposal
+type ValidatorSetChange struct {
+	// Proposal is the finalized proposal that commits to the validator set
+	Proposal []byte
+
+	// Certificate is the commit certificate of the proposal
+	Certificate *proto.CommitCertificate
+
+	// Validators is the validator set sealing the heights following the proposal
+	Validators messages.ValidatorSet
+
+	// SealingValidators is the validator set that sealed the proposal.
+	// It is required only if the change is verified by skipping
+	SealingValidators messages.ValidatorSet
+}
+
+// checkpoint is the validator set sealing the heights following the height
+type checkpoint struct {
+	height     uint64
+	validators messages.ValidatorSet
+}
+
+// Option is the light client configuration option
+type Option func(*Client)
+
+// WithMaxSkipDistance sets the maximum number of heights a certificate
+// can be past the latest validator set change, for it to be verified
+// by skipping. It should be short enough for the validators that left
+// the latest known set to still be bound by the fault assumption
+func WithMaxSkipDistance(distance uint64) Option {
+	return func(c *Client) {
+		c.maxSkipDistance = distance
+	}
+}
+
+// Client is the light client that verifies the proposal finality
+type Client struct {
+	verifier        Verifier
+	maxSkipDistance uint64
+
+	// checkpoints are the known validator sets, sorted by height
+	checkpoints     []checkpoint
+	checkpointsLock sync.RWMutex
+}
+
+// NewClient creates a new light client that trusts the
+// validator set to seal the heights following the trusted height
+func NewClient(
+	verifier Verifier,
+	height uint64,
+	validators messages.ValidatorSet,
+	opts ...Option,
+) *Client {
+	client := &Client{
+		verifier:        verifier,
+		maxSkipDistance: DefaultMaxSkipDistance,
+		checkpoints: []checkpoint{
+			{
+				height:     height,
+				validators: validators,
+			},
+		},
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// TrustedHeight returns the height of the latest validator set change
+// (or the initial trusted height), and the validator set sealing the heights after it
+func (c *Client) TrustedHeight() (uint64, messages.ValidatorSet) {
+	c.checkpointsLock.RLock()
+	defer c.checkpointsLock.RUnlock()
+
+	latest := c.checkpoints[len(c.checkpoints)-1]
+
+	return latest.height, latest.validators
+}
+
+// VerifyFinality verifies the proposal was finalized at the certificate height.
+// Heights that can only be verified by skipping are rejected,
+// as they require the sealing validator set (VerifySkipping)
+func (c *Client) VerifyFinality(proposal []byte, certificate *proto.CommitCertificate) error {
+	return c.verifyFinality(proposal, certificate, nil)
+}
+
+// VerifySkipping verifies the proposal was finalized at the certificate
+// height, by the sealing validator set. The sealing validator set is
+// only used if the height is past the latest known validator set change
+func (c *Client) VerifySkipping(
+	proposal []byte,
+	certificate *proto.CommitCertificate,
+	sealingValidators messages.ValidatorSet,
+) error {
+	if sealingValidators == nil {
+		return ErrSealingSetRequired
+	}
+
+	return c.verifyFinality(proposal, certificate, sealingValidators)
+}
+
+// verifyFinality verifies the proposal was finalized at the certificate height,
+// using the sealing validator set if the height is verified by skipping
+func (c *Client) verifyFinality(
+	proposal []byte,
+	certificate *proto.CommitCertificate,
+	sealingValidators messages.ValidatorSet,
+) error {
+	if certificate.GetView() == nil {
+		return messages.ErrMissingCertificateView
+	}
+
+	if !c.verifier.IsValidProposalHash(proposal, certificate.ProposalHash) {
+		return ErrInvalidProposalHash
+	}
+
+	// The certificate view is not sealed, so a certificate
+	// could otherwise be relabeled to a different height
+	if !c.verifier.IsValidProposalHeight(proposal, certificate.View.Height) {
+		return ErrInvalidProposalHeight
+	}
+
+	latest, validators, skipping, err := c.validatorSet(certificate.View.Height)
+	if err != nil {
+		return err
+	}
+
+	if !skipping {
+		return messages.VerifyCommitCertificate(certificate, validators, c.verifier)
+	}
+
+	if sealingValidators == nil {
+		return ErrSealingSetRequired
+	}
+
+	if certificate.View.Height-latest > c.maxSkipDistance {
+		return ErrSkipDistanceExceeded
+	}
+
+	return c.verifySkipping(certificate, validators, sealingValidators)
+}
+
+// ApplyValidatorSetChanges verifies and applies the validator set
+// changes in order. Changes are applied up until the first invalid one
+func (c *Client) ApplyValidatorSetChanges(changes ...*ValidatorSetChange) error {
+	for _, change := range changes {
+		if err := c.applyValidatorSetChange(change); err != nil {
+			return fmt.Errorf(
+				"unable to apply validator set change at height %d: %w",
+				change.Certificate.GetView().GetHeight(),
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// applyValidatorSetChange verifies and applies the validator set change
+func (c *Client) applyValidatorSetChange(change *ValidatorSetChange) error {
+	if err := c.verifyFinality(change.Proposal, change.Certificate, change.SealingValidators); err != nil {
+		return err
+	}
+
+	if !c.verifier.IsValidValidatorSetChange(change.Proposal, change.Validators) {
+		return ErrInvalidValidatorSetChange
+	}
+
+	c.checkpointsLock.Lock()
+	defer c.checkpointsLock.Unlock()
+
+	// The height is bound to the proposal by verifyFinality
+	height := change.Certificate.View.Height
+	if height <= c.checkpoints[len(c.checkpoints)-1].height {
+		return ErrStaleValidatorSetChange
+	}
+
+	c.checkpoints = append(c.checkpoints, checkpoint{
+		height:     height,
+		validators: change.Validators,
+	})
+
+	return nil
+}
+
+// validatorSet returns the height of the latest checkpoint before the height,
+// the validator set sealing the height, and whether the height is verified
+// by skipping. Heights after the one following the latest validator set
+// change are verified by skipping
+func (c *Client) validatorSet(height uint64) (uint64, messages.ValidatorSet, bool, error) {
+	c.checkpointsLock.RLock()
+	defer c.checkpointsLock.RUnlock()
+
+	if height <= c.checkpoints[0].height {
+		return 0, nil, false, ErrHeightNotTrusted
+	}
+
+	// Find the latest checkpoint before the height
+	index := sort.Search(len(c.checkpoints), func(i int) bool {
+		return c.checkpoints[i].height >= height
+	}) - 1
+
+	latest := c.checkpoints[index]
+	skipping := index == len(c.checkpoints)-1 && height > latest.height+1
+
+	return latest.height, latest.validators, skipping, nil
+}
+
+// verifySkipping verifies the certificate is sealed by a quorum of the
+// sealing validator set, and that the signers from the trusted validator
+// set hold more than a third of its voting power
+func (c *Client) verifySkipping(
+	certificate *proto.CommitCertificate,
+	trusted messages.ValidatorSet,
+	sealing messages.ValidatorSet,
+) error {
+	if err := messages.VerifyCommitCertificate(certificate, sealing, c.verifier); err != nil {
+		return err
+	}
+
+	// All the signers are valid sealing validators at this point
+	var signers [][]byte
+
+	if aggregated := certificate.GetAggregatedSeal(); aggregated != nil {
+		for _, validator := range messages.BitmapValidators(sealing, aggregated.GetSigners()) {
+			signers = append(signers, validator.ID)
+		}
+	} else {
+		for _, seal := range messages.ExtractCertificateSeals(certificate) {
+			signers = append(signers, seal.Signer)
+		}
+	}
+
+	var power uint64
+
+	for _, signer := range signers {
+		power += trusted.VotingPower(signer)
+	}
+
+	if power <= messages.MaximumFaultyVotingPower(trusted) {
+		return ErrInsufficientTrust
+	}
+
+	return nil
+}
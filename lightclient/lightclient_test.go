@@ -0,0 +1,575 @@
+package lightclient
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockVerifier is the verifier of the mock chain, in which the proposals
+// start with their height, the proposal hash is the SHA-256 hash of the
+// proposal, a seal is the hash of the signer and the proposal hash,
+// and the validator set change proposals list the new validators
+type mockVerifier struct{}
+
+func (mockVerifier) IsValidBlock(_ []byte) bool {
+	return true
+}
+
+func (mockVerifier) IsValidSender(_ *proto.Message) bool {
+	return true
+}
+
+func (mockVerifier) IsProposer(_ []byte, _, _ uint64) bool {
+	return false
+}
+
+func (mockVerifier) IsValidProposalHash(proposal, hash []byte) bool {
+	return string(proposalHash(proposal)) == string(hash)
+}
+
+func (mockVerifier) IsValidCommittedSeal(proposalHash []byte, seal *messages.CommittedSeal) bool {
+	return string(mockSeal(seal.Signer, proposalHash)) == string(seal.Signature)
+}
+
+func (mockVerifier) IsValidProposalHeight(proposal []byte, height uint64) bool {
+	return strings.HasPrefix(string(proposal), fmt.Sprintf("%d: ", height))
+}
+
+func (mockVerifier) IsValidValidatorSetChange(proposal []byte, validators messages.ValidatorSet) bool {
+	return strings.HasSuffix(string(proposal), ": "+validatorsContent(validators))
+}
+
+// buildProposal builds the proposal of the content at the height
+func buildProposal(height uint64, content string) []byte {
+	return []byte(fmt.Sprintf("%d: %s", height, content))
+}
+
+// proposalHash returns the hash of the proposal
+func proposalHash(proposal []byte) []byte {
+	hash := sha256.Sum256(proposal)
+
+	return hash[:]
+}
+
+// mockSeal returns the seal of the signer over the proposal hash
+func mockSeal(signer, proposalHash []byte) []byte {
+	seal := sha256.Sum256(append(append([]byte{}, signer...), proposalHash...))
+
+	return seal[:]
+}
+
+// validatorsContent returns the proposal content
+// that commits to the validator set
+func validatorsContent(validators messages.ValidatorSet) string {
+	ids := make([]string, 0)
+	for _, validator := range validators.Validators() {
+		ids = append(ids, string(validator.ID))
+	}
+
+	return "validators: " + strings.Join(ids, ",")
+}
+
+// newValidatorSet creates a new equal validator set of the validators
+func newValidatorSet(ids ...string) messages.ValidatorSet {
+	validators := make([][]byte, len(ids))
+	for index, id := range ids {
+		validators[index] = []byte(id)
+	}
+
+	return messages.NewEqualValidatorSet(validators)
+}
+
+// buildCertificate builds the certificate of the
+// proposal at the height, sealed by the signers
+func buildCertificate(height uint64, proposal []byte, signers ...string) *proto.CommitCertificate {
+	hash := proposalHash(proposal)
+
+	seals := make([]*messages.CommittedSeal, len(signers))
+	for index, signer := range signers {
+		seals[index] = &messages.CommittedSeal{
+			Signer:    []byte(signer),
+			Signature: mockSeal([]byte(signer), hash),
+		}
+	}
+
+	return messages.NewCommitCertificate(&proto.View{Height: height}, hash, seals)
+}
+
+// buildChange builds the change to the validator
+// set at the height, sealed by the signers
+func buildChange(height uint64, validators messages.ValidatorSet, signers ...string) *ValidatorSetChange {
+	proposal := buildProposal(height, validatorsContent(validators))
+
+	return &ValidatorSetChange{
+		Proposal:    proposal,
+		Certificate: buildCertificate(height, proposal, signers...),
+		Validators:  validators,
+	}
+}
+
+func TestClient_VerifyFinality(t *testing.T) {
+	t.Parallel()
+
+	var (
+		trustedHeight = uint64(10)
+		trustedSet    = newValidatorSet("a", "b", "c", "d")
+
+		// proposal is the proposal of the height after the trusted one
+		proposal = buildProposal(trustedHeight+1, "proposal")
+
+		// skipped is the proposal of the height verified by skipping
+		skipped = buildProposal(trustedHeight+10, "proposal")
+
+		// distant is the proposal of the height past the maximum skip distance
+		distant = buildProposal(trustedHeight+DefaultMaxSkipDistance+1, "proposal")
+	)
+
+	testTable := []struct {
+		name        string
+		proposal    []byte
+		certificate *proto.CommitCertificate
+		sealingSet  messages.ValidatorSet
+		expectedErr error
+	}{
+		{
+			"quorum of the trusted validators",
+			proposal,
+			buildCertificate(trustedHeight+1, proposal, "a", "b", "c"),
+			nil,
+			nil,
+		},
+		{
+			"no quorum of the trusted validators",
+			proposal,
+			buildCertificate(trustedHeight+1, proposal, "a", "b"),
+			nil,
+			messages.ErrInsufficientSigners,
+		},
+		{
+			"seals of unknown validators",
+			proposal,
+			buildCertificate(trustedHeight+1, proposal, "a", "b", "e"),
+			nil,
+			messages.ErrUnknownSealSigner,
+		},
+		{
+			"different proposal",
+			buildProposal(trustedHeight+1, "other proposal"),
+			buildCertificate(trustedHeight+1, proposal, "a", "b", "c"),
+			nil,
+			ErrInvalidProposalHash,
+		},
+		{
+			"certificate relabeled to a different height",
+			skipped,
+			buildCertificate(trustedHeight+1, skipped, "a", "b", "c"),
+			nil,
+			ErrInvalidProposalHeight,
+		},
+		{
+			"trusted height",
+			buildProposal(trustedHeight, "proposal"),
+			buildCertificate(trustedHeight, buildProposal(trustedHeight, "proposal"), "a", "b", "c"),
+			nil,
+			ErrHeightNotTrusted,
+		},
+		{
+			"skipping with more than a third of the trusted validators",
+			skipped,
+			buildCertificate(trustedHeight+10, skipped, "c", "d", "e", "f"),
+			newValidatorSet("c", "d", "e", "f"),
+			nil,
+		},
+		{
+			"skipping with a third of the trusted validators",
+			skipped,
+			buildCertificate(trustedHeight+10, skipped, "d", "e", "f", "g"),
+			newValidatorSet("d", "e", "f", "g"),
+			ErrInsufficientTrust,
+		},
+		{
+			"skipping without the sealing validator set",
+			skipped,
+			buildCertificate(trustedHeight+10, skipped, "a", "b", "c"),
+			nil,
+			ErrSealingSetRequired,
+		},
+		{
+			"skipping without a quorum of the sealing validator set",
+			skipped,
+			buildCertificate(trustedHeight+10, skipped, "c", "d"),
+			newValidatorSet("c", "d", "e", "f"),
+			messages.ErrInsufficientSigners,
+		},
+		{
+			"skipping with seals outside of the sealing validator set",
+			skipped,
+			buildCertificate(trustedHeight+10, skipped, "a", "b", "c", "d"),
+			newValidatorSet("c", "d", "e", "f"),
+			messages.ErrUnknownSealSigner,
+		},
+		{
+			"skipping past the maximum skip distance",
+			distant,
+			buildCertificate(trustedHeight+DefaultMaxSkipDistance+1, distant, "a", "b", "c"),
+			trustedSet,
+			ErrSkipDistanceExceeded,
+		},
+		{
+			"skipping with aggregated seals",
+			skipped,
+			messages.NewAggregatedCommitCertificate(
+				&proto.View{Height: trustedHeight + 10},
+				proposalHash(skipped),
+				&messages.AggregatedSeal{
+					Signers:   messages.ValidatorBitmap{0x0f},
+					Signature: []byte("signature"),
+				},
+			),
+			trustedSet,
+			messages.ErrAggregationNotSupported,
+		},
+		{
+			"missing view",
+			proposal,
+			&proto.CommitCertificate{},
+			nil,
+			messages.ErrMissingCertificateView,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient(mockVerifier{}, trustedHeight, trustedSet)
+
+			var err error
+			if testCase.sealingSet != nil {
+				err = client.VerifySkipping(testCase.proposal, testCase.certificate, testCase.sealingSet)
+			} else {
+				err = client.VerifyFinality(testCase.proposal, testCase.certificate)
+			}
+
+			if testCase.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+			}
+		})
+	}
+
+	t.Run("invalid seal of a trusted validator while skipping", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient(mockVerifier{}, trustedHeight, trustedSet)
+
+		certificate := buildCertificate(trustedHeight+10, skipped, "a", "b", "c")
+		certificate.GetCommittedSeals().Seals[1].Signature = []byte("invalid")
+
+		assert.ErrorIs(
+			t,
+			client.VerifySkipping(skipped, certificate, trustedSet),
+			messages.ErrInvalidCommittedSeal,
+		)
+	})
+
+	t.Run("maximum skip distance option", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient(mockVerifier{}, trustedHeight, trustedSet, WithMaxSkipDistance(5))
+
+		var (
+			lastProposal = buildProposal(trustedHeight+5, "proposal")
+			pastProposal = buildProposal(trustedHeight+6, "proposal")
+		)
+
+		assert.NoError(t, client.VerifySkipping(
+			lastProposal,
+			buildCertificate(trustedHeight+5, lastProposal, "a", "b", "c"),
+			trustedSet,
+		))
+
+		assert.ErrorIs(t, client.VerifySkipping(
+			pastProposal,
+			buildCertificate(trustedHeight+6, pastProposal, "a", "b", "c"),
+			trustedSet,
+		), ErrSkipDistanceExceeded)
+	})
+}
+
+func TestClient_ValidatorSetChanges(t *testing.T) {
+	t.Parallel()
+
+	var (
+		trustedHeight = uint64(10)
+		firstSet      = newValidatorSet("a", "b", "c", "d")
+		secondSet     = newValidatorSet("c", "d", "e", "f")
+		thirdSet      = newValidatorSet("e", "f", "g", "h")
+	)
+
+	client := NewClient(mockVerifier{}, trustedHeight, firstSet)
+
+	// The second set is proven by skipping, and the third set
+	// by the quorum of the second set
+	skippedChange := buildChange(20, secondSet, "b", "c", "d")
+	skippedChange.SealingValidators = firstSet
+
+	require.NoError(t, client.ApplyValidatorSetChanges(
+		skippedChange,
+		buildChange(21, thirdSet, "c", "d", "e"),
+	))
+
+	height, validators := client.TrustedHeight()
+	assert.Equal(t, uint64(21), height)
+	assert.Equal(t, thirdSet, validators)
+
+	// Make sure the heights are verified with the validator set sealing them
+	testTable := []struct {
+		name        string
+		height      uint64
+		signers     []string
+		sealingSet  messages.ValidatorSet
+		expectedErr error
+	}{
+		{
+			"sealed by the initial validator set",
+			15,
+			[]string{"a", "b", "c"},
+			nil,
+			nil,
+		},
+		{
+			"sealed by the initial validator set without quorum",
+			15,
+			[]string{"c", "d"},
+			nil,
+			messages.ErrInsufficientSigners,
+		},
+		{
+			"last height sealed by the initial validator set",
+			20,
+			[]string{"a", "b", "d"},
+			nil,
+			nil,
+		},
+		{
+			"sealed by the second validator set",
+			21,
+			[]string{"d", "e", "f"},
+			nil,
+			nil,
+		},
+		{
+			"sealed by the third validator set",
+			22,
+			[]string{"f", "g", "h"},
+			nil,
+			nil,
+		},
+		{
+			"sealed by the previous validator set",
+			22,
+			[]string{"c", "d", "e"},
+			nil,
+			messages.ErrUnknownSealSigner,
+		},
+		{
+			"skipping from the third validator set",
+			30,
+			[]string{"g", "h", "i", "j"},
+			newValidatorSet("g", "h", "i", "j"),
+			nil,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				proposal    = buildProposal(testCase.height, "proposal")
+				certificate = buildCertificate(testCase.height, proposal, testCase.signers...)
+				err         error
+			)
+
+			if testCase.sealingSet != nil {
+				err = client.VerifySkipping(proposal, certificate, testCase.sealingSet)
+			} else {
+				err = client.VerifyFinality(proposal, certificate)
+			}
+
+			if testCase.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+			}
+		})
+	}
+}
+
+func TestClient_InvalidValidatorSetChanges(t *testing.T) {
+	t.Parallel()
+
+	var (
+		trustedHeight = uint64(10)
+		firstSet      = newValidatorSet("a", "b", "c", "d")
+		secondSet     = newValidatorSet("c", "d", "e", "f")
+	)
+
+	t.Run("proposal does not commit to the validator set", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient(mockVerifier{}, trustedHeight, firstSet)
+
+		change := buildChange(11, secondSet, "a", "b", "c")
+		change.Validators = newValidatorSet("e", "f", "g", "h")
+
+		assert.ErrorIs(t, client.ApplyValidatorSetChanges(change), ErrInvalidValidatorSetChange)
+
+		height, validators := client.TrustedHeight()
+		assert.Equal(t, trustedHeight, height)
+		assert.Equal(t, firstSet, validators)
+	})
+
+	t.Run("change relabeled to a different height", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient(mockVerifier{}, trustedHeight, firstSet)
+
+		// The change is sealed by a quorum of the trusted validators, but relabeled
+		// to the height after the trusted one, so it wouldn't need to be skipped to
+		change := buildChange(15, secondSet, "a", "b", "c")
+		change.Certificate.View.Height = trustedHeight + 1
+
+		assert.ErrorIs(t, client.ApplyValidatorSetChanges(change), ErrInvalidProposalHeight)
+
+		height, validators := client.TrustedHeight()
+		assert.Equal(t, trustedHeight, height)
+		assert.Equal(t, firstSet, validators)
+	})
+
+	t.Run("changes are applied up until the invalid one", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient(mockVerifier{}, trustedHeight, firstSet)
+
+		err := client.ApplyValidatorSetChanges(
+			buildChange(11, secondSet, "a", "b", "c"),
+			buildChange(12, firstSet, "a", "b", "c"),
+		)
+
+		assert.ErrorIs(t, err, messages.ErrUnknownSealSigner)
+
+		height, validators := client.TrustedHeight()
+		assert.Equal(t, uint64(11), height)
+		assert.Equal(t, secondSet, validators)
+	})
+
+	t.Run("stale change", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient(mockVerifier{}, trustedHeight, firstSet)
+
+		change := buildChange(15, secondSet, "a", "b", "c")
+		change.SealingValidators = firstSet
+
+		require.NoError(t, client.ApplyValidatorSetChanges(change))
+
+		assert.ErrorIs(
+			t,
+			client.ApplyValidatorSetChanges(buildChange(12, secondSet, "a", "b", "c")),
+			ErrStaleValidatorSetChange,
+		)
+	})
+}
+
+// TestClient_ForgedSkippedChange makes sure the validators that left the
+// trusted validator set can't take over the client by forging skipped
+// validator set changes, even if they hold more than a third of its voting power
+func TestClient_ForgedSkippedChange(t *testing.T) {
+	t.Parallel()
+
+	var (
+		trustedHeight = uint64(10)
+		trustedSet    = newValidatorSet("a", "b", "c", "d")
+		actualSet     = newValidatorSet("c", "d", "e", "f")
+		forgedSet     = newValidatorSet("a", "b", "x")
+	)
+
+	// The former validators "a" and "b" hold half of the trusted voting power
+	testTable := []struct {
+		name        string
+		change      *ValidatorSetChange
+		expectedErr error
+	}{
+		{
+			"forged change past the maximum skip distance",
+			func() *ValidatorSetChange {
+				change := buildChange(trustedHeight+1000, forgedSet, "a", "b", "x")
+				change.SealingValidators = forgedSet
+
+				return change
+			}(),
+			ErrSkipDistanceExceeded,
+		},
+		{
+			"forged change claiming the actual sealing validator set",
+			func() *ValidatorSetChange {
+				change := buildChange(trustedHeight+50, forgedSet, "a", "b")
+				change.SealingValidators = actualSet
+
+				return change
+			}(),
+			messages.ErrUnknownSealSigner,
+		},
+		{
+			"forged change without the sealing validator set",
+			buildChange(trustedHeight+50, forgedSet, "a", "b"),
+			ErrSealingSetRequired,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient(mockVerifier{}, trustedHeight, trustedSet, WithMaxSkipDistance(100))
+
+			assert.ErrorIs(t, client.ApplyValidatorSetChanges(testCase.change), testCase.expectedErr)
+
+			// Make sure the forged validator set is not trusted
+			height, validators := client.TrustedHeight()
+			assert.Equal(t, trustedHeight, height)
+			assert.Equal(t, trustedSet, validators)
+		})
+	}
+
+	t.Run("actual change sealed by the remaining validators", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient(mockVerifier{}, trustedHeight, trustedSet, WithMaxSkipDistance(100))
+
+		change := buildChange(trustedHeight+50, actualSet, "c", "d", "e")
+		change.SealingValidators = actualSet
+
+		require.NoError(t, client.ApplyValidatorSetChanges(change))
+
+		height, validators := client.TrustedHeight()
+		assert.Equal(t, trustedHeight+50, height)
+		assert.Equal(t, actualSet, validators)
+	})
+}
@@ -0,0 +1,132 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoCommittedSeals      = errors.New("no committed seals")
+	ErrUnknownSealSigner     = errors.New("committed seal signer is not a validator")
+	ErrInvalidSignerBitmap   = errors.New("signer bitmap does not match the validator set")
+	ErrInsufficientSigners   = errors.New("committed seal signers do not form a quorum")
+	ErrInvalidAggregatedSeal = errors.New("invalid aggregated seal signature")
+)
+
+// SealAggregator aggregates committed seals that are signatures of an
+// aggregatable signature scheme (BLS signatures over the proposal hash).
+// The signature scheme, and the validator key lookup, are left to the implementation
+type SealAggregator interface {
+	// AggregateSignatures aggregates the signatures into a single signature
+	AggregateSignatures(signatures [][]byte) ([]byte, error)
+
+	// VerifyAggregatedSignature checks if the aggregated signature
+	// is the signature of all the signers over the proposal hash
+	VerifyAggregatedSignature(signers [][]byte, proposalHash, signature []byte) bool
+}
+
+// AggregatedSeal is the committed seals of a proposal,
+// aggregated into a single signature and the bitmap of its signers
+type AggregatedSeal struct {
+	// Signers is the bitmap of the validators
+	// whose seals are part of the signature
+	Signers ValidatorBitmap
+
+	// Signature is the aggregated signature
+	Signature []byte
+}
+
+// AggregateSeals aggregates the committed seals of the validators.
+// The seals are aggregated in the validator set order, and a signer's
+// duplicate seals are aggregated only once
+func AggregateSeals(
+	aggregator SealAggregator,
+	set ValidatorSet,
+	seals []*CommittedSeal,
+) (*AggregatedSeal, error) {
+	if len(seals) == 0 {
+		return nil, ErrNoCommittedSeals
+	}
+
+	signatures := make(map[int][]byte, len(seals))
+
+	for _, seal := range seals {
+		index, ok := set.Index(seal.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%w: %x", ErrUnknownSealSigner, seal.Signer)
+		}
+
+		if _, exists := signatures[index]; !exists {
+			signatures[index] = seal.Signature
+		}
+	}
+
+	var (
+		bitmap  = NewValidatorBitmap(len(set.Validators()))
+		ordered = make([][]byte, 0, len(signatures))
+	)
+
+	for index := range set.Validators() {
+		signature, exists := signatures[index]
+		if !exists {
+			continue
+		}
+
+		bitmap.Set(index)
+
+		ordered = append(ordered, signature)
+	}
+
+	signature, err := aggregator.AggregateSignatures(ordered)
+	if err != nil {
+		return nil, fmt.Errorf("unable to aggregate seals: %w", err)
+	}
+
+	return &AggregatedSeal{
+		Signers:   bitmap,
+		Signature: signature,
+	}, nil
+}
+
+// VerifyAggregatedSeal checks if the aggregated seal is signed by a
+// quorum of the validators, over the specified proposal hash
+func VerifyAggregatedSeal(
+	aggregator SealAggregator,
+	set ValidatorSet,
+	proposalHash []byte,
+	seal *AggregatedSeal,
+) error {
+	if seal == nil {
+		return ErrNoCommittedSeals
+	}
+
+	validators := set.Validators()
+
+	// Make sure the bitmap marks only validators
+	if len(seal.Signers) > len(NewValidatorBitmap(len(validators))) {
+		return ErrInvalidSignerBitmap
+	}
+
+	signers := BitmapValidators(set, seal.Signers)
+	if len(signers) != seal.Signers.Count() {
+		return ErrInvalidSignerBitmap
+	}
+
+	var power uint64
+
+	signerIDs := make([][]byte, len(signers))
+	for index, signer := range signers {
+		signerIDs[index] = signer.ID
+		power += signer.VotingPower
+	}
+
+	if power < QuorumVotingPower(set) {
+		return ErrInsufficientSigners
+	}
+
+	if !aggregator.VerifyAggregatedSignature(signerIDs, proposalHash, seal.Signature) {
+		return ErrInvalidAggregatedSeal
+	}
+
+	return nil
+}
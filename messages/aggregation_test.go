@@ -0,0 +1,225 @@
+package messages
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSealAggregator is the aggregatable signature scheme in which
+// a seal is the hash of the signer and the proposal hash, and the
+// aggregated signature is the XOR of the seals
+type mockSealAggregator struct {
+	aggregated [][]byte
+}
+
+// mockSeal returns the seal of the signer over the proposal hash
+func mockSeal(signer, proposalHash []byte) []byte {
+	seal := sha256.Sum256(append(append([]byte{}, signer...), proposalHash...))
+
+	return seal[:]
+}
+
+func (m *mockSealAggregator) AggregateSignatures(signatures [][]byte) ([]byte, error) {
+	m.aggregated = signatures
+
+	aggregated := make([]byte, sha256.Size)
+
+	for _, signature := range signatures {
+		if len(signature) != sha256.Size {
+			return nil, errors.New("invalid signature")
+		}
+
+		for index := range aggregated {
+			aggregated[index] ^= signature[index]
+		}
+	}
+
+	return aggregated, nil
+}
+
+func (m *mockSealAggregator) VerifyAggregatedSignature(signers [][]byte, proposalHash, signature []byte) bool {
+	seals := make([][]byte, len(signers))
+	for index, signer := range signers {
+		seals[index] = mockSeal(signer, proposalHash)
+	}
+
+	expected, err := m.AggregateSignatures(seals)
+	if err != nil {
+		return false
+	}
+
+	return string(expected) == string(signature)
+}
+
+// buildSeals builds the seals of the signers over the proposal hash
+func buildSeals(proposalHash []byte, signers ...string) []*CommittedSeal {
+	seals := make([]*CommittedSeal, len(signers))
+	for index, signer := range signers {
+		seals[index] = &CommittedSeal{
+			Signer:    []byte(signer),
+			Signature: mockSeal([]byte(signer), proposalHash),
+		}
+	}
+
+	return seals
+}
+
+func TestAggregateSeals(t *testing.T) {
+	t.Parallel()
+
+	var (
+		proposalHash = []byte("proposal hash")
+		set          = NewEqualValidatorSet([][]byte{
+			[]byte("0"), []byte("1"), []byte("2"), []byte("3"),
+		})
+	)
+
+	t.Run("seals are aggregated in the validator set order", func(t *testing.T) {
+		t.Parallel()
+
+		aggregator := &mockSealAggregator{}
+
+		seal, err := AggregateSeals(aggregator, set, buildSeals(proposalHash, "3", "0", "2", "0"))
+		require.NoError(t, err)
+
+		assert.Equal(t, ValidatorBitmap{0b1101}, seal.Signers)
+		assert.Equal(
+			t,
+			[][]byte{
+				mockSeal([]byte("0"), proposalHash),
+				mockSeal([]byte("2"), proposalHash),
+				mockSeal([]byte("3"), proposalHash),
+			},
+			aggregator.aggregated,
+		)
+
+		assert.NoError(t, VerifyAggregatedSeal(aggregator, set, proposalHash, seal))
+	})
+
+	t.Run("unknown signer", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := AggregateSeals(&mockSealAggregator{}, set, buildSeals(proposalHash, "0", "4"))
+
+		assert.ErrorIs(t, err, ErrUnknownSealSigner)
+	})
+
+	t.Run("no seals", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := AggregateSeals(&mockSealAggregator{}, set, nil)
+
+		assert.ErrorIs(t, err, ErrNoCommittedSeals)
+	})
+
+	t.Run("aggregation failure", func(t *testing.T) {
+		t.Parallel()
+
+		seals := buildSeals(proposalHash, "0")
+		seals[0].Signature = []byte("invalid")
+
+		_, err := AggregateSeals(&mockSealAggregator{}, set, seals)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestVerifyAggregatedSeal(t *testing.T) {
+	t.Parallel()
+
+	var (
+		proposalHash = []byte("proposal hash")
+		set          = NewValidatorSet([]Validator{
+			{ID: []byte("0"), VotingPower: 5},
+			{ID: []byte("1"), VotingPower: 1},
+			{ID: []byte("2"), VotingPower: 1},
+			{ID: []byte("3"), VotingPower: 1},
+		})
+	)
+
+	aggregate := func(signers ...string) *AggregatedSeal {
+		seal, err := AggregateSeals(&mockSealAggregator{}, set, buildSeals(proposalHash, signers...))
+		require.NoError(t, err)
+
+		return seal
+	}
+
+	testTable := []struct {
+		name         string
+		seal         *AggregatedSeal
+		proposalHash []byte
+		expectedErr  error
+	}{
+		{
+			"quorum of voting power",
+			aggregate("0", "1"),
+			proposalHash,
+			nil,
+		},
+		{
+			"quorum of signers without the voting power",
+			aggregate("1", "2", "3"),
+			proposalHash,
+			ErrInsufficientSigners,
+		},
+		{
+			"different proposal hash",
+			aggregate("0", "1"),
+			[]byte("other hash"),
+			ErrInvalidAggregatedSeal,
+		},
+		{
+			"bitmap claims a signer that did not sign",
+			&AggregatedSeal{
+				Signers:   ValidatorBitmap{0b0111},
+				Signature: aggregate("0", "1").Signature,
+			},
+			proposalHash,
+			ErrInvalidAggregatedSeal,
+		},
+		{
+			"bitmap marks non validators",
+			&AggregatedSeal{
+				Signers:   ValidatorBitmap{0b10011},
+				Signature: aggregate("0", "1").Signature,
+			},
+			proposalHash,
+			ErrInvalidSignerBitmap,
+		},
+		{
+			"bitmap is too long",
+			&AggregatedSeal{
+				Signers:   ValidatorBitmap{0b0011, 0},
+				Signature: aggregate("0", "1").Signature,
+			},
+			proposalHash,
+			ErrInvalidSignerBitmap,
+		},
+		{
+			"missing seal",
+			nil,
+			proposalHash,
+			ErrNoCommittedSeals,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifyAggregatedSeal(&mockSealAggregator{}, set, testCase.proposalHash, testCase.seal)
+
+			if testCase.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package messages
+
+import "math/bits"
+
+// ValidatorBitmap is the set of validators, where each validator
+// is represented by the bit at its position in the validator set.
+// The bit of validator i is the (i % 8)-th least significant bit
+// of the (i / 8)-th byte
+type ValidatorBitmap []byte
+
+// NewValidatorBitmap creates a new empty bitmap
+// for the specified number of validators
+func NewValidatorBitmap(size int) ValidatorBitmap {
+	return make(ValidatorBitmap, (size+7)/8)
+}
+
+// Set marks the validator at the specified index,
+// growing the bitmap if needed
+func (b *ValidatorBitmap) Set(index int) {
+	for len(*b) <= index/8 {
+		*b = append(*b, 0)
+	}
+
+	(*b)[index/8] |= 1 << (index % 8)
+}
+
+// IsSet checks if the validator at the specified index is marked
+func (b ValidatorBitmap) IsSet(index int) bool {
+	if index < 0 || index/8 >= len(b) {
+		return false
+	}
+
+	return b[index/8]&(1<<(index%8)) != 0
+}
+
+// Count returns the number of marked validators
+func (b ValidatorBitmap) Count() int {
+	count := 0
+	for _, value := range b {
+		count += bits.OnesCount8(value)
+	}
+
+	return count
+}
+
+// SignerBitmap creates the bitmap of the signers in the validator set.
+// Signers that are not validators are ignored
+func SignerBitmap(set ValidatorSet, signers [][]byte) ValidatorBitmap {
+	bitmap := NewValidatorBitmap(len(set.Validators()))
+
+	for _, signer := range signers {
+		if index, ok := set.Index(signer); ok {
+			bitmap.Set(index)
+		}
+	}
+
+	return bitmap
+}
+
+// SealSigners returns the signers of the committed seals
+func SealSigners(seals []*CommittedSeal) [][]byte {
+	signers := make([][]byte, len(seals))
+	for index, seal := range seals {
+		signers[index] = seal.Signer
+	}
+
+	return signers
+}
+
+// BitmapValidators returns the validators marked in the bitmap
+func BitmapValidators(set ValidatorSet, bitmap ValidatorBitmap) []Validator {
+	marked := make([]Validator, 0)
+
+	for index, validator := range set.Validators() {
+		if bitmap.IsSet(index) {
+			marked = append(marked, validator)
+		}
+	}
+
+	return marked
+}
+
+// MissingValidators returns the validators not marked in the bitmap
+func MissingValidators(set ValidatorSet, bitmap ValidatorBitmap) []Validator {
+	missing := make([]Validator, 0)
+
+	for index, validator := range set.Validators() {
+		if !bitmap.IsSet(index) {
+			missing = append(missing, validator)
+		}
+	}
+
+	return missing
+}
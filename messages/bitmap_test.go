@@ -0,0 +1,68 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorBitmap(t *testing.T) {
+	t.Parallel()
+
+	bitmap := NewValidatorBitmap(10)
+	assert.Len(t, bitmap, 2)
+
+	bitmap.Set(0)
+	bitmap.Set(9)
+
+	// Make sure the bitmap grows when needed
+	bitmap.Set(17)
+
+	assert.Equal(t, ValidatorBitmap{0x01, 0x02, 0x02}, bitmap)
+	assert.Equal(t, 3, bitmap.Count())
+
+	assert.True(t, bitmap.IsSet(9))
+	assert.False(t, bitmap.IsSet(8))
+	assert.False(t, bitmap.IsSet(-1))
+	assert.False(t, bitmap.IsSet(100))
+}
+
+func TestValidatorBitmap_Signers(t *testing.T) {
+	t.Parallel()
+
+	set := NewEqualValidatorSet([][]byte{
+		[]byte("node 0"),
+		[]byte("node 1"),
+		[]byte("node 2"),
+		[]byte("node 3"),
+	})
+
+	seals := []*CommittedSeal{
+		{Signer: []byte("node 3")},
+		{Signer: []byte("node 1")},
+		{Signer: []byte("unknown")},
+	}
+
+	bitmap := SignerBitmap(set, SealSigners(seals))
+
+	// Make sure only the validators are marked
+	assert.Equal(t, ValidatorBitmap{0x0a}, bitmap)
+
+	assert.Equal(
+		t,
+		[]Validator{
+			{ID: []byte("node 1"), VotingPower: 1},
+			{ID: []byte("node 3"), VotingPower: 1},
+		},
+		BitmapValidators(set, bitmap),
+	)
+
+	assert.Equal(
+		t,
+		[]Validator{
+			{ID: []byte("node 0"), VotingPower: 1},
+			{ID: []byte("node 2"), VotingPower: 1},
+		},
+		MissingValidators(set, bitmap),
+	)
+}
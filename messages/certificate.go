@@ -0,0 +1,163 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+var (
+	ErrMissingCertificateView  = errors.New("commit certificate view is missing")
+	ErrMissingProposalHash     = errors.New("commit certificate proposal hash is missing")
+	ErrDuplicateSealSigner     = errors.New("committed seal signer is duplicated")
+	ErrInvalidCommittedSeal    = errors.New("committed seal is invalid")
+	ErrAggregationNotSupported = errors.New("seal verifier does not support aggregated seals")
+)
+
+// SealVerifier verifies the committed seals (implemented by the core Verifier).
+// If the verifier is also a SealAggregator, aggregated seals can be verified
+type SealVerifier interface {
+	// IsValidCommittedSeal checks if the seal for the proposal hash is valid
+	IsValidCommittedSeal(proposalHash []byte, committedSeal *CommittedSeal) bool
+}
+
+// NewCommitCertificate creates the portable proof that the
+// proposal with the specified hash was finalized in the view
+func NewCommitCertificate(
+	view *proto.View,
+	proposalHash []byte,
+	seals []*CommittedSeal,
+) *proto.CommitCertificate {
+	protoSeals := make([]*proto.CommittedSeal, len(seals))
+	for index, seal := range seals {
+		protoSeals[index] = &proto.CommittedSeal{
+			Signer:    seal.Signer,
+			Signature: seal.Signature,
+		}
+	}
+
+	return &proto.CommitCertificate{
+		View:         view,
+		ProposalHash: proposalHash,
+		Seals: &proto.CommitCertificate_CommittedSeals{
+			CommittedSeals: &proto.CommittedSeals{
+				Seals: protoSeals,
+			},
+		},
+	}
+}
+
+// NewAggregatedCommitCertificate creates the portable proof that the proposal
+// with the specified hash was finalized in the view, with the aggregated seal
+func NewAggregatedCommitCertificate(
+	view *proto.View,
+	proposalHash []byte,
+	seal *AggregatedSeal,
+) *proto.CommitCertificate {
+	return &proto.CommitCertificate{
+		View:         view,
+		ProposalHash: proposalHash,
+		Seals: &proto.CommitCertificate_AggregatedSeal{
+			AggregatedSeal: &proto.AggregatedSeal{
+				Signers:   seal.Signers,
+				Signature: seal.Signature,
+			},
+		},
+	}
+}
+
+// ExtractCertificateSeals extracts the individual committed seals from
+// the certificate. Aggregated seals are not extracted
+func ExtractCertificateSeals(certificate *proto.CommitCertificate) []*CommittedSeal {
+	protoSeals := certificate.GetCommittedSeals().GetSeals()
+
+	committedSeals := make([]*CommittedSeal, 0, len(protoSeals))
+	for _, seal := range protoSeals {
+		committedSeals = append(committedSeals, &CommittedSeal{
+			Signer:    seal.Signer,
+			Signature: seal.Signature,
+		})
+	}
+
+	return committedSeals
+}
+
+// VerifyCommitCertificate verifies the proof that a proposal was finalized:
+// the proposal hash is sealed by a quorum of the validator set voting power,
+// and all the seals are valid. The validator set should be the one for the
+// certificate height. The seals only sign the proposal hash, so the certificate
+// view is not authenticated: callers must check that the proposal commits to
+// the certificate height (ex. the lightclient Verifier), or it can be relabeled
+func VerifyCommitCertificate(
+	certificate *proto.CommitCertificate,
+	set ValidatorSet,
+	verifier SealVerifier,
+) error {
+	if certificate.GetView() == nil {
+		return ErrMissingCertificateView
+	}
+
+	if len(certificate.ProposalHash) == 0 {
+		return ErrMissingProposalHash
+	}
+
+	switch seals := certificate.Seals.(type) {
+	case *proto.CommitCertificate_CommittedSeals:
+		return verifyCommittedSeals(certificate.ProposalHash, ExtractCertificateSeals(certificate), set, verifier)
+	case *proto.CommitCertificate_AggregatedSeal:
+		aggregator, ok := verifier.(SealAggregator)
+		if !ok {
+			return ErrAggregationNotSupported
+		}
+
+		return VerifyAggregatedSeal(aggregator, set, certificate.ProposalHash, &AggregatedSeal{
+			Signers:   seals.AggregatedSeal.GetSigners(),
+			Signature: seals.AggregatedSeal.GetSignature(),
+		})
+	default:
+		return ErrNoCommittedSeals
+	}
+}
+
+// verifyCommittedSeals verifies the individual validator seals
+// over the proposal hash form a quorum
+func verifyCommittedSeals(
+	proposalHash []byte,
+	seals []*CommittedSeal,
+	set ValidatorSet,
+	verifier SealVerifier,
+) error {
+	if len(seals) == 0 {
+		return ErrNoCommittedSeals
+	}
+
+	var (
+		power   uint64
+		signers = make(map[string]struct{}, len(seals))
+	)
+
+	for _, seal := range seals {
+		if _, ok := set.Index(seal.Signer); !ok {
+			return fmt.Errorf("%w: %x", ErrUnknownSealSigner, seal.Signer)
+		}
+
+		if _, exists := signers[string(seal.Signer)]; exists {
+			return fmt.Errorf("%w: %x", ErrDuplicateSealSigner, seal.Signer)
+		}
+
+		signers[string(seal.Signer)] = struct{}{}
+
+		if !verifier.IsValidCommittedSeal(proposalHash, seal) {
+			return fmt.Errorf("%w: %x", ErrInvalidCommittedSeal, seal.Signer)
+		}
+
+		power += set.VotingPower(seal.Signer)
+	}
+
+	if power < QuorumVotingPower(set) {
+		return ErrInsufficientSigners
+	}
+
+	return nil
+}
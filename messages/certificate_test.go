@@ -0,0 +1,180 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// mockSealVerifier accepts the seals of the mock seal scheme
+type mockSealVerifier struct{}
+
+func (mockSealVerifier) IsValidCommittedSeal(proposalHash []byte, seal *CommittedSeal) bool {
+	return string(seal.Signature) == string(mockSeal(seal.Signer, proposalHash))
+}
+
+// mockAggregatingSealVerifier accepts the seals,
+// and the aggregated seals, of the mock seal scheme
+type mockAggregatingSealVerifier struct {
+	mockSealVerifier
+	*mockSealAggregator
+}
+
+func TestVerifyCommitCertificate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view         = &proto.View{Height: 1, Round: 2}
+		proposalHash = []byte("proposal hash")
+		set          = NewValidatorSet([]Validator{
+			{ID: []byte("0"), VotingPower: 5},
+			{ID: []byte("1"), VotingPower: 1},
+			{ID: []byte("2"), VotingPower: 1},
+			{ID: []byte("3"), VotingPower: 1},
+		})
+	)
+
+	aggregated := func(signers ...string) *proto.CommitCertificate {
+		seal, err := AggregateSeals(&mockSealAggregator{}, set, buildSeals(proposalHash, signers...))
+		require.NoError(t, err)
+
+		return NewAggregatedCommitCertificate(view, proposalHash, seal)
+	}
+
+	invalidSeals := buildSeals(proposalHash, "0", "1")
+	invalidSeals[1].Signature = []byte("invalid")
+
+	testTable := []struct {
+		name        string
+		certificate *proto.CommitCertificate
+		verifier    SealVerifier
+		expectedErr error
+	}{
+		{
+			"quorum of seals",
+			NewCommitCertificate(view, proposalHash, buildSeals(proposalHash, "1", "0")),
+			mockSealVerifier{},
+			nil,
+		},
+		{
+			"quorum of aggregated seals",
+			aggregated("0", "1"),
+			mockAggregatingSealVerifier{mockSealAggregator: &mockSealAggregator{}},
+			nil,
+		},
+		{
+			"seals without the quorum voting power",
+			NewCommitCertificate(view, proposalHash, buildSeals(proposalHash, "1", "2", "3")),
+			mockSealVerifier{},
+			ErrInsufficientSigners,
+		},
+		{
+			"aggregated seals without the quorum voting power",
+			aggregated("1", "2", "3"),
+			mockAggregatingSealVerifier{mockSealAggregator: &mockSealAggregator{}},
+			ErrInsufficientSigners,
+		},
+		{
+			"invalid seal",
+			NewCommitCertificate(view, proposalHash, invalidSeals),
+			mockSealVerifier{},
+			ErrInvalidCommittedSeal,
+		},
+		{
+			"duplicate seal",
+			NewCommitCertificate(view, proposalHash, buildSeals(proposalHash, "1", "1")),
+			mockSealVerifier{},
+			ErrDuplicateSealSigner,
+		},
+		{
+			"seal of a non validator",
+			NewCommitCertificate(view, proposalHash, buildSeals(proposalHash, "0", "4")),
+			mockSealVerifier{},
+			ErrUnknownSealSigner,
+		},
+		{
+			"seals for a different proposal",
+			NewCommitCertificate(view, []byte("other hash"), buildSeals(proposalHash, "0", "1")),
+			mockSealVerifier{},
+			ErrInvalidCommittedSeal,
+		},
+		{
+			"aggregated seals without aggregation support",
+			aggregated("0", "1"),
+			mockSealVerifier{},
+			ErrAggregationNotSupported,
+		},
+		{
+			"no seals",
+			NewCommitCertificate(view, proposalHash, nil),
+			mockSealVerifier{},
+			ErrNoCommittedSeals,
+		},
+		{
+			"missing seals",
+			&proto.CommitCertificate{
+				View:         view,
+				ProposalHash: proposalHash,
+			},
+			mockSealVerifier{},
+			ErrNoCommittedSeals,
+		},
+		{
+			"missing view",
+			NewCommitCertificate(nil, proposalHash, buildSeals(proposalHash, "0", "1")),
+			mockSealVerifier{},
+			ErrMissingCertificateView,
+		},
+		{
+			"missing proposal hash",
+			NewCommitCertificate(view, nil, buildSeals(proposalHash, "0", "1")),
+			mockSealVerifier{},
+			ErrMissingProposalHash,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifyCommitCertificate(testCase.certificate, set, testCase.verifier)
+
+			if testCase.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+			}
+		})
+	}
+}
+
+// TestCommitCertificate_Serialization makes sure the certificate
+// can be verified after it is transferred
+func TestCommitCertificate_Serialization(t *testing.T) {
+	t.Parallel()
+
+	var (
+		proposalHash = []byte("proposal hash")
+		set          = NewEqualValidatorSet([][]byte{[]byte("0"), []byte("1"), []byte("2")})
+	)
+
+	certificate := NewCommitCertificate(
+		&proto.View{Height: 1, Round: 0},
+		proposalHash,
+		buildSeals(proposalHash, "0", "1", "2"),
+	)
+
+	raw, err := protobuf.Marshal(certificate)
+	require.NoError(t, err)
+
+	decoded := &proto.CommitCertificate{}
+	require.NoError(t, protobuf.Unmarshal(raw, decoded))
+
+	assert.True(t, protobuf.Equal(certificate, decoded))
+	assert.NoError(t, VerifyCommitCertificate(decoded, set, mockSealVerifier{}))
+}
@@ -0,0 +1,130 @@
+package messages
+
+import (
+	"bytes"
+
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// Equivocation is a pair of conflicting messages of the same type,
+// sent by the same sender for the same view
+type Equivocation struct {
+	// First is the message that was received first,
+	// and is the one used for consensus
+	First *proto.Message
+
+	// Second is the conflicting message received afterwards
+	Second *proto.Message
+}
+
+// EquivocationHandler is notified of each detected equivocation
+type EquivocationHandler func(equivocation Equivocation)
+
+// Option is the message store configuration option
+type Option func(*Messages)
+
+// WithEquivocationHandler sets the handler that is
+// notified of each detected equivocation
+func WithEquivocationHandler(handler EquivocationHandler) Option {
+	return func(ms *Messages) {
+		ms.equivocationHandler = handler
+	}
+}
+
+// IsEquivocation checks if the messages of the same type, from the same sender
+// and for the same view, vote for different values. Messages that differ only
+// in parts that don't change the vote (ex. the signature) are not conflicting
+func IsEquivocation(first, second *proto.Message) bool {
+	if !sameVoteKey(first, second) {
+		return false
+	}
+
+	switch first.Type {
+	case proto.MessageType_PREPREPARE:
+		return !bytes.Equal(
+			first.GetPreprepareData().GetProposalHash(),
+			second.GetPreprepareData().GetProposalHash(),
+		)
+	case proto.MessageType_PREPARE:
+		return !bytes.Equal(
+			first.GetPrepareData().GetProposalHash(),
+			second.GetPrepareData().GetProposalHash(),
+		)
+	case proto.MessageType_COMMIT:
+		return !bytes.Equal(
+			first.GetCommitData().GetProposalHash(),
+			second.GetCommitData().GetProposalHash(),
+		)
+	case proto.MessageType_ROUND_CHANGE:
+		var (
+			firstData  = first.GetRoundChangeData()
+			secondData = second.GetRoundChangeData()
+		)
+
+		return !bytes.Equal(
+			firstData.GetLastPreparedProposedBlock(),
+			secondData.GetLastPreparedProposedBlock(),
+		) || !bytes.Equal(
+			firstData.GetLatestPreparedCertificate().GetProposalMessage().GetPreprepareData().GetProposalHash(),
+			secondData.GetLatestPreparedCertificate().GetProposalMessage().GetPreprepareData().GetProposalHash(),
+		)
+	}
+
+	return false
+}
+
+// addEquivocation records the equivocation, unless one was already recorded
+// for the same sender, message type and view, and reports if it is new.
+// A single proof per key is enough to hold the sender accountable,
+// so further conflicting votes don't grow the store
+func (ms *Messages) addEquivocation(equivocation Equivocation) bool {
+	ms.equivocationsLock.Lock()
+	defer ms.equivocationsLock.Unlock()
+
+	height := equivocation.First.View.Height
+
+	for _, recorded := range ms.equivocations[height] {
+		if sameVoteKey(recorded.First, equivocation.First) {
+			// The sender was already caught equivocating for this vote
+			return false
+		}
+	}
+
+	ms.equivocations[height] = append(ms.equivocations[height], equivocation)
+
+	return true
+}
+
+// sameVoteKey checks if the messages are of the same type,
+// from the same sender and for the same view
+func sameVoteKey(first, second *proto.Message) bool {
+	return first.Type == second.Type &&
+		bytes.Equal(first.From, second.From) &&
+		first.GetView().GetHeight() == second.GetView().GetHeight() &&
+		first.GetView().GetRound() == second.GetView().GetRound()
+}
+
+// GetEquivocations fetches the equivocations
+// detected for the specified height
+func (ms *Messages) GetEquivocations(height uint64) []Equivocation {
+	ms.equivocationsLock.RLock()
+	defer ms.equivocationsLock.RUnlock()
+
+	equivocations := make([]Equivocation, len(ms.equivocations[height]))
+	copy(equivocations, ms.equivocations[height])
+
+	return equivocations
+}
+
+// pruneEquivocations removes the equivocations
+// for all heights lower than the specified one
+func (ms *Messages) pruneEquivocations(height uint64) {
+	ms.equivocationsLock.Lock()
+	defer ms.equivocationsLock.Unlock()
+
+	for equivocationHeight := range ms.equivocations {
+		if equivocationHeight < height {
+			delete(ms.equivocations, equivocationHeight)
+		}
+	}
+}
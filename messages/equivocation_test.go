@@ -0,0 +1,175 @@
+package messages
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildVote builds a message of the specified type,
+// voting for the specified proposal hash
+func buildVote(
+	messageType proto.MessageType,
+	from string,
+	view *proto.View,
+	proposalHash []byte,
+) *proto.Message {
+	message := &proto.Message{
+		View: view,
+		From: []byte(from),
+		Type: messageType,
+	}
+
+	switch messageType {
+	case proto.MessageType_PREPREPARE:
+		message.Payload = &proto.Message_PreprepareData{
+			PreprepareData: &proto.PrePrepareMessage{
+				Proposal:     proposalHash,
+				ProposalHash: proposalHash,
+			},
+		}
+	case proto.MessageType_PREPARE:
+		message.Payload = &proto.Message_PrepareData{
+			PrepareData: &proto.PrepareMessage{
+				ProposalHash: proposalHash,
+			},
+		}
+	case proto.MessageType_COMMIT:
+		message.Payload = &proto.Message_CommitData{
+			CommitData: &proto.CommitMessage{
+				ProposalHash:  proposalHash,
+				CommittedSeal: []byte("seal"),
+			},
+		}
+	case proto.MessageType_ROUND_CHANGE:
+		message.Payload = &proto.Message_RoundChangeData{
+			RoundChangeData: &proto.RoundChangeMessage{
+				LastPreparedProposedBlock: proposalHash,
+			},
+		}
+	}
+
+	return message
+}
+
+func TestIsEquivocation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view      = &proto.View{Height: 1, Round: 2}
+		otherView = &proto.View{Height: 1, Round: 3}
+	)
+
+	messageTypes := []proto.MessageType{
+		proto.MessageType_PREPREPARE,
+		proto.MessageType_PREPARE,
+		proto.MessageType_COMMIT,
+		proto.MessageType_ROUND_CHANGE,
+	}
+
+	for _, messageType := range messageTypes {
+		messageType := messageType
+
+		t.Run(messageType.String(), func(t *testing.T) {
+			t.Parallel()
+
+			first := buildVote(messageType, "node", view, []byte("hash 1"))
+
+			// Make sure votes for different values conflict
+			assert.True(t, IsEquivocation(first, buildVote(messageType, "node", view, []byte("hash 2"))))
+
+			// Make sure the same vote doesn't conflict, even if re-signed
+			resigned := buildVote(messageType, "node", view, []byte("hash 1"))
+			resigned.Signature = []byte("signature")
+
+			assert.False(t, IsEquivocation(first, resigned))
+
+			// Make sure votes from different senders or views don't conflict
+			assert.False(t, IsEquivocation(first, buildVote(messageType, "other", view, []byte("hash 2"))))
+			assert.False(t, IsEquivocation(first, buildVote(messageType, "node", otherView, []byte("hash 2"))))
+		})
+	}
+
+	t.Run("different message types", func(t *testing.T) {
+		t.Parallel()
+
+		assert.False(t, IsEquivocation(
+			buildVote(proto.MessageType_PREPARE, "node", view, []byte("hash 1")),
+			buildVote(proto.MessageType_COMMIT, "node", view, []byte("hash 2")),
+		))
+	})
+
+	t.Run("round change certificates", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			first  = buildVote(proto.MessageType_ROUND_CHANGE, "node", view, []byte("block"))
+			second = buildVote(proto.MessageType_ROUND_CHANGE, "node", view, []byte("block"))
+		)
+
+		second.GetRoundChangeData().LatestPreparedCertificate = &proto.PreparedCertificate{
+			ProposalMessage: buildVote(proto.MessageType_PREPREPARE, "proposer", view, []byte("hash")),
+		}
+
+		assert.True(t, IsEquivocation(first, second))
+	})
+}
+
+func TestMessages_Equivocation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view     = &proto.View{Height: 1, Round: 0}
+		detected = make([]Equivocation, 0)
+		first    = buildVote(proto.MessageType_COMMIT, "node", view, []byte("hash 1"))
+		second   = buildVote(proto.MessageType_COMMIT, "node", view, []byte("hash 2"))
+	)
+
+	messages := NewMessages(WithEquivocationHandler(func(equivocation Equivocation) {
+		detected = append(detected, equivocation)
+	}))
+	defer messages.Close()
+
+	messages.AddMessage(first)
+	messages.AddMessage(second)
+
+	// Make sure the repeated equivocation is reported once
+	messages.AddMessage(buildVote(proto.MessageType_COMMIT, "node", view, []byte("hash 2")))
+
+	// Make sure the repeated first vote is not an equivocation
+	messages.AddMessage(buildVote(proto.MessageType_COMMIT, "node", view, []byte("hash 1")))
+
+	// Make sure further conflicting votes for the same view
+	// don't grow the store, as the first proof is enough
+	for index := 3; index < 100; index++ {
+		messages.AddMessage(
+			buildVote(proto.MessageType_COMMIT, "node", view, []byte(fmt.Sprintf("hash %d", index))),
+		)
+	}
+
+	expected := []Equivocation{
+		{
+			First:  first,
+			Second: second,
+		},
+	}
+
+	assert.Equal(t, expected, detected)
+	assert.Equal(t, expected, messages.GetEquivocations(view.Height))
+
+	// Make sure the first message is kept for consensus
+	validMessages := messages.GetValidMessages(view, proto.MessageType_COMMIT, func(_ *proto.Message) bool {
+		return true
+	})
+
+	if assert.Len(t, validMessages, 1) {
+		assert.Equal(t, []byte("hash 1"), validMessages[0].GetCommitData().ProposalHash)
+	}
+
+	// Make sure the equivocations are pruned with the messages
+	messages.PruneByHeight(view.Height + 1)
+
+	assert.Len(t, messages.GetEquivocations(view.Height), 0)
+}
@@ -0,0 +1,99 @@
+package messages
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+var (
+	ErrMissingEvidenceMessage = errors.New("evidence message is missing")
+	ErrDifferentSigners       = errors.New("evidence messages are from different signers")
+	ErrDifferentViews         = errors.New("evidence messages are for different views")
+	ErrDifferentTypes         = errors.New("evidence messages are of different types")
+	ErrNoConflict             = errors.New("evidence messages do not conflict")
+	ErrInvalidSignature       = errors.New("evidence message signature is invalid")
+)
+
+// SenderVerifier verifies the message signatures
+// (implemented by the core Verifier)
+type SenderVerifier interface {
+	// IsValidSender checks if signature is from sender
+	IsValidSender(msg *proto.Message) bool
+}
+
+// SignatureVerifier verifies the signatures over the canonical message
+// payload (implemented by the core SignatureVerifier)
+type SignatureVerifier interface {
+	// Verify checks if the signature of the payload is from the signer
+	Verify(signer, payload, signature []byte) bool
+}
+
+// signatureSenderVerifier checks the message signature
+// over its canonical payload (without the signature)
+type signatureSenderVerifier struct {
+	verifier SignatureVerifier
+}
+
+// IsValidSender checks if the message is signed by its sender
+func (v signatureSenderVerifier) IsValidSender(msg *proto.Message) bool {
+	payload, err := msg.PayloadNoSig()
+	if err != nil {
+		return false
+	}
+
+	return v.verifier.Verify(msg.From, payload, msg.Signature)
+}
+
+// NewDuplicateVoteEvidence creates the portable
+// proof of the detected equivocation
+func NewDuplicateVoteEvidence(equivocation Equivocation) *proto.DuplicateVoteEvidence {
+	return &proto.DuplicateVoteEvidence{
+		First:  equivocation.First,
+		Second: equivocation.Second,
+	}
+}
+
+// VerifyEvidence verifies the proof that a validator sent two conflicting
+// messages: both messages are from the same signer, for the same view,
+// of the same type, vote for different values, and are validly signed
+func VerifyEvidence(evidence *proto.DuplicateVoteEvidence, verifier SenderVerifier) error {
+	var (
+		first  = evidence.GetFirst()
+		second = evidence.GetSecond()
+	)
+
+	if first.GetView() == nil || second.GetView() == nil {
+		return ErrMissingEvidenceMessage
+	}
+
+	if !bytes.Equal(first.From, second.From) {
+		return ErrDifferentSigners
+	}
+
+	if first.View.Height != second.View.Height || first.View.Round != second.View.Round {
+		return ErrDifferentViews
+	}
+
+	if first.Type != second.Type {
+		return ErrDifferentTypes
+	}
+
+	if !IsEquivocation(first, second) {
+		return ErrNoConflict
+	}
+
+	if !verifier.IsValidSender(first) || !verifier.IsValidSender(second) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// VerifyEvidenceSignatures verifies the evidence like VerifyEvidence, checking
+// the message signatures over their canonical payload, as signed by the
+// built-in message signing
+func VerifyEvidenceSignatures(evidence *proto.DuplicateVoteEvidence, verifier SignatureVerifier) error {
+	return VerifyEvidence(evidence, signatureSenderVerifier{verifier: verifier})
+}
@@ -0,0 +1,237 @@
+package messages
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/nubank/go-ibft/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// mockSenderVerifier accepts messages signed with the valid signature
+type mockSenderVerifier struct{}
+
+func (mockSenderVerifier) IsValidSender(msg *proto.Message) bool {
+	return string(msg.Signature) == "valid"
+}
+
+// buildSignedVote builds the validly signed vote
+func buildSignedVote(from string, view *proto.View, proposalHash string) *proto.Message {
+	message := buildVote(proto.MessageType_COMMIT, from, view, []byte(proposalHash))
+	message.Signature = []byte("valid")
+
+	return message
+}
+
+func TestVerifyEvidence(t *testing.T) {
+	t.Parallel()
+
+	var (
+		view      = &proto.View{Height: 1, Round: 0}
+		otherView = &proto.View{Height: 1, Round: 1}
+	)
+
+	testTable := []struct {
+		name        string
+		first       *proto.Message
+		second      *proto.Message
+		expectedErr error
+	}{
+		{
+			"valid evidence",
+			buildSignedVote("node", view, "hash 1"),
+			buildSignedVote("node", view, "hash 2"),
+			nil,
+		},
+		{
+			"missing message",
+			buildSignedVote("node", view, "hash 1"),
+			nil,
+			ErrMissingEvidenceMessage,
+		},
+		{
+			"different signers",
+			buildSignedVote("node", view, "hash 1"),
+			buildSignedVote("other", view, "hash 2"),
+			ErrDifferentSigners,
+		},
+		{
+			"different views",
+			buildSignedVote("node", view, "hash 1"),
+			buildSignedVote("node", otherView, "hash 2"),
+			ErrDifferentViews,
+		},
+		{
+			"different types",
+			buildSignedVote("node", view, "hash 1"),
+			func() *proto.Message {
+				message := buildVote(proto.MessageType_PREPARE, "node", view, []byte("hash 2"))
+				message.Signature = []byte("valid")
+
+				return message
+			}(),
+			ErrDifferentTypes,
+		},
+		{
+			"same vote",
+			buildSignedVote("node", view, "hash 1"),
+			buildSignedVote("node", view, "hash 1"),
+			ErrNoConflict,
+		},
+		{
+			"invalid signature",
+			buildSignedVote("node", view, "hash 1"),
+			func() *proto.Message {
+				message := buildSignedVote("node", view, "hash 2")
+				message.Signature = []byte("forged")
+
+				return message
+			}(),
+			ErrInvalidSignature,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			evidence := &proto.DuplicateVoteEvidence{
+				First:  testCase.first,
+				Second: testCase.second,
+			}
+
+			assert.ErrorIs(t, VerifyEvidence(evidence, mockSenderVerifier{}), testCase.expectedErr)
+		})
+	}
+}
+
+// signVote builds the vote from the signer, signed
+// over its canonical payload
+func signVote(t *testing.T, signer *signing.Ed25519Signer, view *proto.View, proposalHash string) *proto.Message {
+	t.Helper()
+
+	message := buildVote(proto.MessageType_COMMIT, "", view, []byte(proposalHash))
+	message.From = signer.ID()
+
+	payload, err := message.PayloadNoSig()
+	require.NoError(t, err)
+
+	message.Signature, err = signer.Sign(payload)
+	require.NoError(t, err)
+
+	return message
+}
+
+func TestVerifyEvidenceSignatures(t *testing.T) {
+	t.Parallel()
+
+	newSigner := func(seed byte) *signing.Ed25519Signer {
+		signer, err := signing.NewEd25519Signer(
+			ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize)),
+		)
+		require.NoError(t, err)
+
+		return signer
+	}
+
+	var (
+		view   = &proto.View{Height: 1, Round: 0}
+		signer = newSigner(1)
+		other  = newSigner(2)
+	)
+
+	testTable := []struct {
+		name        string
+		second      func() *proto.Message
+		expectedErr error
+	}{
+		{
+			"valid evidence",
+			func() *proto.Message {
+				return signVote(t, signer, view, "hash 2")
+			},
+			nil,
+		},
+		{
+			"signed by another key",
+			func() *proto.Message {
+				message := signVote(t, other, view, "hash 2")
+				message.From = signer.ID()
+
+				return message
+			},
+			ErrInvalidSignature,
+		},
+		{
+			"altered after signing",
+			func() *proto.Message {
+				message := signVote(t, signer, view, "hash 2")
+				message.GetCommitData().ProposalHash = []byte("hash 3")
+
+				return message
+			},
+			ErrInvalidSignature,
+		},
+		{
+			"unsigned",
+			func() *proto.Message {
+				message := signVote(t, signer, view, "hash 2")
+				message.Signature = nil
+
+				return message
+			},
+			ErrInvalidSignature,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			evidence := &proto.DuplicateVoteEvidence{
+				First:  signVote(t, signer, view, "hash 1"),
+				Second: testCase.second(),
+			}
+
+			assert.ErrorIs(
+				t,
+				VerifyEvidenceSignatures(evidence, signing.Ed25519Verifier{}),
+				testCase.expectedErr,
+			)
+		})
+	}
+}
+
+// TestVerifyEvidence_Serialized makes sure the evidence
+// can be verified after being serialized
+func TestVerifyEvidence_Serialized(t *testing.T) {
+	t.Parallel()
+
+	view := &proto.View{Height: 5, Round: 2}
+
+	evidence := &proto.Evidence{
+		Evidence: &proto.Evidence_DuplicateVote{
+			DuplicateVote: NewDuplicateVoteEvidence(Equivocation{
+				First:  buildSignedVote("node", view, "hash 1"),
+				Second: buildSignedVote("node", view, "hash 2"),
+			}),
+		},
+	}
+
+	raw, err := protobuf.Marshal(evidence)
+	require.NoError(t, err)
+
+	decoded := &proto.Evidence{}
+	require.NoError(t, protobuf.Unmarshal(raw, decoded))
+
+	assert.True(t, protobuf.Equal(evidence, decoded))
+	assert.NoError(t, VerifyEvidence(decoded.GetDuplicateVote(), mockSenderVerifier{}))
+}
@@ -28,11 +28,9 @@ func ExtractCommittedSeals(commitMessages []*proto.Message) []*CommittedSeal {
 
 // ExtractCommittedSeal extracts the committed seal from the passed in message
 func ExtractCommittedSeal(commitMessage *proto.Message) *CommittedSeal {
-	commitData, _ := commitMessage.Payload.(*proto.Message_CommitData)
-
 	return &CommittedSeal{
 		Signer:    commitMessage.From,
-		Signature: commitData.CommitData.CommittedSeal,
+		Signature: commitMessage.GetCommitData().GetCommittedSeal(),
 	}
 }
 
@@ -42,9 +40,7 @@ func ExtractCommitHash(commitMessage *proto.Message) []byte {
 		return nil
 	}
 
-	commitData, _ := commitMessage.Payload.(*proto.Message_CommitData)
-
-	return commitData.CommitData.ProposalHash
+	return commitMessage.GetCommitData().GetProposalHash()
 }
 
 // ExtractProposal extracts the proposal from the passed in message
@@ -53,9 +49,7 @@ func ExtractProposal(proposalMessage *proto.Message) []byte {
 		return nil
 	}
 
-	preprepareData, _ := proposalMessage.Payload.(*proto.Message_PreprepareData)
-
-	return preprepareData.PreprepareData.Proposal
+	return proposalMessage.GetPreprepareData().GetProposal()
 }
 
 // ExtractProposalHash extracts the proposal hash from the passed in message
@@ -64,9 +58,7 @@ func ExtractProposalHash(proposalMessage *proto.Message) []byte {
 		return nil
 	}
 
-	preprepareData, _ := proposalMessage.Payload.(*proto.Message_PreprepareData)
-
-	return preprepareData.PreprepareData.ProposalHash
+	return proposalMessage.GetPreprepareData().GetProposalHash()
 }
 
 // ExtractRoundChangeCertificate extracts the RCC from the passed in message
@@ -75,9 +67,7 @@ func ExtractRoundChangeCertificate(proposalMessage *proto.Message) *proto.RoundC
 		return nil
 	}
 
-	preprepareData, _ := proposalMessage.Payload.(*proto.Message_PreprepareData)
-
-	return preprepareData.PreprepareData.Certificate
+	return proposalMessage.GetPreprepareData().GetCertificate()
 }
 
 // ExtractPrepareHash extracts the prepare proposal hash from the passed in message
@@ -86,9 +76,7 @@ func ExtractPrepareHash(prepareMessage *proto.Message) []byte {
 		return nil
 	}
 
-	prepareData, _ := prepareMessage.Payload.(*proto.Message_PrepareData)
-
-	return prepareData.PrepareData.ProposalHash
+	return prepareMessage.GetPrepareData().GetProposalHash()
 }
 
 // ExtractLatestPC extracts the latest PC from the passed in message
@@ -97,9 +85,7 @@ func ExtractLatestPC(roundChangeMessage *proto.Message) *proto.PreparedCertifica
 		return nil
 	}
 
-	rcData, _ := roundChangeMessage.Payload.(*proto.Message_RoundChangeData)
-
-	return rcData.RoundChangeData.LatestPreparedCertificate
+	return roundChangeMessage.GetRoundChangeData().GetLatestPreparedCertificate()
 }
 
 // ExtractLastPreparedProposedBlock extracts the latest prepared proposed block from the passed in message
@@ -108,9 +94,7 @@ func ExtractLastPreparedProposedBlock(roundChangeMessage *proto.Message) []byte
 		return nil
 	}
 
-	rcData, _ := roundChangeMessage.Payload.(*proto.Message_RoundChangeData)
-
-	return rcData.RoundChangeData.LastPreparedProposedBlock
+	return roundChangeMessage.GetRoundChangeData().GetLastPreparedProposedBlock()
 }
 
 // HasUniqueSenders checks if the messages have unique senders
@@ -168,14 +152,15 @@ func HaveSameProposalHash(messages []*proto.Message) bool {
 	return true
 }
 
-// AllHaveLowerRound checks if all messages have the same round
+// AllHaveLowerRound checks if all messages have a round lower than the round.
+// Messages without a view don't have a lower round
 func AllHaveLowerRound(messages []*proto.Message, round uint64) bool {
 	if len(messages) < 1 {
 		return false
 	}
 
 	for _, message := range messages {
-		if message.View.Round >= round {
+		if view := message.GetView(); view == nil || view.GetRound() >= round {
 			return false
 		}
 	}
@@ -183,14 +168,31 @@ func AllHaveLowerRound(messages []*proto.Message, round uint64) bool {
 	return true
 }
 
-// AllHaveSameHeight checks if all messages have the same height
+// AllHaveSameHeight checks if all messages have the same height.
+// Messages without a view don't have the same height
 func AllHaveSameHeight(messages []*proto.Message, height uint64) bool {
 	if len(messages) < 1 {
 		return false
 	}
 
 	for _, message := range messages {
-		if message.View.Height != height {
+		if view := message.GetView(); view == nil || view.GetHeight() != height {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AllHaveSameRound checks if all messages have the same round.
+// Messages without a view don't have the same round
+func AllHaveSameRound(messages []*proto.Message, round uint64) bool {
+	if len(messages) < 1 {
+		return false
+	}
+
+	for _, message := range messages {
+		if view := message.GetView(); view == nil || view.GetRound() != round {
 			return false
 		}
 	}
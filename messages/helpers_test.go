@@ -564,6 +564,20 @@ func TestMessages_AllHaveLowerRond(t *testing.T) {
 			round,
 			false,
 		},
+		{
+			"missing view",
+			[]*proto.Message{
+				{
+					View: &proto.View{
+						Height: 0,
+						Round:  round,
+					},
+				},
+				{},
+			},
+			2,
+			false,
+		},
 		{
 			"lower round match",
 			[]*proto.Message{
@@ -634,6 +648,18 @@ func TestMessages_AllHaveSameHeight(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"missing view",
+			[]*proto.Message{
+				{
+					View: &proto.View{
+						Height: height,
+					},
+				},
+				{},
+			},
+			false,
+		},
 		{
 			"same height",
 			[]*proto.Message{
@@ -669,3 +695,82 @@ func TestMessages_AllHaveSameHeight(t *testing.T) {
 		})
 	}
 }
+
+func TestMessages_AllHaveSameRound(t *testing.T) {
+	t.Parallel()
+
+	round := uint64(1)
+
+	testTable := []struct {
+		name     string
+		messages []*proto.Message
+		haveSame bool
+	}{
+		{
+			"empty messages",
+			nil,
+			false,
+		},
+		{
+			"not same round",
+			[]*proto.Message{
+				{
+					View: &proto.View{
+						Round: round - 1,
+					},
+				},
+				{
+					View: &proto.View{
+						Round: round,
+					},
+				},
+			},
+			false,
+		},
+		{
+			"missing view",
+			[]*proto.Message{
+				{
+					View: &proto.View{
+						Round: round,
+					},
+				},
+				{},
+			},
+			false,
+		},
+		{
+			"same round",
+			[]*proto.Message{
+				{
+					View: &proto.View{
+						Round: round,
+					},
+				},
+				{
+					View: &proto.View{
+						Round: round,
+					},
+				},
+			},
+			true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(
+				t,
+				testCase.haveSame,
+				AllHaveSameRound(
+					testCase.messages,
+					round,
+				),
+			)
+		})
+	}
+}
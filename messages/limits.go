@@ -0,0 +1,298 @@
+package messages
+
+import (
+	"sync/atomic"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// DropReason is the reason a message was dropped from the store
+type DropReason int
+
+const (
+	// DropSenderQuota is the drop of a message that is over
+	// the sender's quota for the message type
+	DropSenderQuota DropReason = iota
+
+	// DropMemoryLimit is the drop of a message that
+	// is over the memory limit of the store
+	DropMemoryLimit
+
+	numDropReasons
+)
+
+// String returns the string representation of the drop reason
+func (r DropReason) String() string {
+	switch r {
+	case DropSenderQuota:
+		return "sender quota"
+	case DropMemoryLimit:
+		return "memory limit"
+	default:
+		return "unknown"
+	}
+}
+
+// DropHandler is notified of each message dropped from the store
+type DropHandler func(message *proto.Message, reason DropReason)
+
+// WithSenderQuota sets the maximum number of messages of each type kept
+// for a single sender, across all views. Once the quota is reached, the
+// sender's message for the furthest view is dropped to make room for the new
+// one, or the new message is dropped if it is for the furthest view.
+// The sender's messages for the nearest views (its current votes) are kept
+func WithSenderQuota(quota int) Option {
+	return func(ms *Messages) {
+		ms.senderQuota = quota
+	}
+}
+
+// WithMemoryLimit sets the maximum summed (encoded) size of the
+// stored messages, in bytes. Once the limit is exceeded, the messages
+// for the furthest views are dropped. The limit should leave enough
+// room for the messages of the current view
+func WithMemoryLimit(limit int) Option {
+	return func(ms *Messages) {
+		ms.memoryLimit = int64(limit)
+	}
+}
+
+// WithDropHandler sets the handler that is
+// notified of each message dropped from the store
+func WithDropHandler(handler DropHandler) Option {
+	return func(ms *Messages) {
+		ms.dropHandler = handler
+	}
+}
+
+// DroppedMessages returns the number of messages dropped for the reason
+func (ms *Messages) DroppedMessages(reason DropReason) uint64 {
+	if reason < 0 || reason >= numDropReasons {
+		return 0
+	}
+
+	return atomic.LoadUint64(&ms.dropped[reason])
+}
+
+// MemoryUsage returns the summed (encoded) size of the stored
+// messages, in bytes. It is tracked only if the memory limit is set
+func (ms *Messages) MemoryUsage() int {
+	return int(atomic.LoadInt64(&ms.memoryUsage))
+}
+
+// drop counts the dropped messages, and notifies the drop handler.
+// It should be called outside of the message type locks
+func (ms *Messages) drop(messages []*proto.Message, reason DropReason) {
+	atomic.AddUint64(&ms.dropped[reason], uint64(len(messages)))
+
+	if ms.dropHandler == nil {
+		return
+	}
+
+	for _, message := range messages {
+		ms.dropHandler(message, reason)
+	}
+}
+
+// trackAdded accounts for the message added to the store.
+// It should be called with the message type lock held
+func (ms *Messages) trackAdded(message *proto.Message) {
+	if ms.senderQuota > 0 {
+		ms.senderCounts[message.Type][string(message.From)]++
+	}
+
+	if ms.memoryLimit > 0 {
+		atomic.AddInt64(&ms.memoryUsage, int64(protobuf.Size(message)))
+	}
+}
+
+// trackRemoved accounts for the message removed from the store.
+// It should be called with the message type lock held
+func (ms *Messages) trackRemoved(message *proto.Message) {
+	if ms.senderQuota > 0 {
+		counts := ms.senderCounts[message.Type]
+
+		if counts[string(message.From)]--; counts[string(message.From)] <= 0 {
+			delete(counts, string(message.From))
+		}
+	}
+
+	if ms.memoryLimit > 0 {
+		atomic.AddInt64(&ms.memoryUsage, -int64(protobuf.Size(message)))
+	}
+}
+
+// trackRemovedRounds accounts for the messages of the rounds removed
+// from the store. It should be called with the message type lock held
+func (ms *Messages) trackRemovedRounds(roundMsgMap roundMessageMap) {
+	if ms.senderQuota <= 0 && ms.memoryLimit <= 0 {
+		return
+	}
+
+	for _, msgs := range roundMsgMap {
+		for _, msg := range msgs {
+			ms.trackRemoved(msg)
+		}
+	}
+}
+
+// evictForQuota makes room for the sender's new message, if the sender
+// reached its quota for the message type. The message for the furthest view
+// is dropped, which is the new message itself if it is for a view further than
+// all of the sender's stored ones. This way, a sender flooding far-future views
+// can't push its own votes for the current view out of the store.
+// It returns the dropped message, if any. It should be called with
+// the message type lock held, before the sender's new message is added
+func (ms *Messages) evictForQuota(message *proto.Message) *proto.Message {
+	if ms.senderQuota <= 0 || ms.senderCounts[message.Type][string(message.From)] < ms.senderQuota {
+		return nil
+	}
+
+	var (
+		heightMsgMap = ms.getMessageMap(message.Type)
+		furthest     *proto.Message
+	)
+
+	for _, roundMsgMap := range heightMsgMap {
+		for _, msgs := range roundMsgMap {
+			msg, exists := msgs[string(message.From)]
+			if !exists {
+				continue
+			}
+
+			if furthest == nil || isLowerView(furthest.View, msg.View) {
+				furthest = msg
+			}
+		}
+	}
+
+	if furthest == nil || !isLowerView(message.View, furthest.View) {
+		return message
+	}
+
+	var (
+		roundMsgMap = heightMsgMap[furthest.View.Height]
+		msgs        = roundMsgMap[furthest.View.Round]
+	)
+
+	delete(msgs, string(message.From))
+	ms.trackRemoved(furthest)
+
+	if len(msgs) == 0 {
+		delete(roundMsgMap, furthest.View.Round)
+	}
+
+	if len(roundMsgMap) == 0 {
+		delete(heightMsgMap, furthest.View.Height)
+	}
+
+	return furthest
+}
+
+// enforceMemoryLimit drops the messages for the furthest
+// views, until the memory usage is within the limit
+func (ms *Messages) enforceMemoryLimit() {
+	if ms.memoryLimit <= 0 {
+		return
+	}
+
+	ms.evictionLock.Lock()
+	defer ms.evictionLock.Unlock()
+
+	for atomic.LoadInt64(&ms.memoryUsage) > ms.memoryLimit {
+		dropped := ms.evictFurthestView()
+		if len(dropped) == 0 {
+			return
+		}
+
+		ms.drop(dropped, DropMemoryLimit)
+	}
+}
+
+// evictFurthestView removes the messages for the furthest view
+// (of any message type), and returns them
+func (ms *Messages) evictFurthestView() []*proto.Message {
+	var (
+		furthestType proto.MessageType
+		furthestView *proto.View
+	)
+
+	for _, messageType := range messageTypes {
+		mux := ms.muxMap[messageType]
+		mux.RLock()
+		view := highestView(ms.getMessageMap(messageType))
+		mux.RUnlock()
+
+		if view != nil && (furthestView == nil || isLowerView(furthestView, view)) {
+			furthestType = messageType
+			furthestView = view
+		}
+	}
+
+	if furthestView == nil {
+		return nil
+	}
+
+	mux := ms.muxMap[furthestType]
+	mux.Lock()
+	defer mux.Unlock()
+
+	heightMsgMap := ms.getMessageMap(furthestType)
+
+	// The furthest view may have changed in the meantime
+	if furthestView = highestView(heightMsgMap); furthestView == nil {
+		return nil
+	}
+
+	roundMsgMap := heightMsgMap[furthestView.Height]
+	msgs := roundMsgMap[furthestView.Round]
+
+	dropped := make([]*proto.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		ms.trackRemoved(msg)
+
+		dropped = append(dropped, msg)
+	}
+
+	delete(roundMsgMap, furthestView.Round)
+
+	if len(roundMsgMap) == 0 {
+		delete(heightMsgMap, furthestView.Height)
+	}
+
+	return dropped
+}
+
+// highestView returns the highest view that has messages
+func highestView(heightMsgMap heightMessageMap) *proto.View {
+	var furthest *proto.View
+
+	for height, roundMsgMap := range heightMsgMap {
+		for round, msgs := range roundMsgMap {
+			if len(msgs) == 0 {
+				continue
+			}
+
+			view := &proto.View{
+				Height: height,
+				Round:  round,
+			}
+
+			if furthest == nil || isLowerView(furthest, view) {
+				furthest = view
+			}
+		}
+	}
+
+	return furthest
+}
+
+// isLowerView checks if the first view is lower than the second one
+func isLowerView(first, second *proto.View) bool {
+	if first.Height != second.Height {
+		return first.Height < second.Height
+	}
+
+	return first.Round < second.Round
+}
@@ -0,0 +1,146 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// buildPrepare builds the prepare message from the sender for the view
+func buildPrepare(from string, height, round uint64) *proto.Message {
+	return buildVote(
+		proto.MessageType_PREPARE,
+		from,
+		&proto.View{Height: height, Round: round},
+		[]byte("proposal hash"),
+	)
+}
+
+func TestMessages_SenderQuota(t *testing.T) {
+	t.Parallel()
+
+	var dropped []*proto.Message
+
+	messages := NewMessages(
+		WithSenderQuota(2),
+		WithDropHandler(func(message *proto.Message, reason DropReason) {
+			assert.Equal(t, DropSenderQuota, reason)
+
+			dropped = append(dropped, message)
+		}),
+	)
+	defer messages.Close()
+
+	// The sender votes for the current view, and floods the future views
+	for _, round := range []uint64{1, 5, 9} {
+		messages.AddMessage(buildPrepare("spammer", 1, round))
+	}
+
+	// A message for a nearer view makes room by evicting the furthest one
+	messages.AddMessage(buildPrepare("spammer", 1, 3))
+
+	// Re-sending a message for a kept view doesn't count against the quota
+	messages.AddMessage(buildPrepare("spammer", 1, 3))
+	messages.AddMessage(buildPrepare("node", 1, 1))
+
+	// Make sure the messages for the furthest views are dropped,
+	// starting with the new message that was further than all others
+	if assert.Len(t, dropped, 2) {
+		assert.Equal(t, uint64(9), dropped[0].View.Round)
+		assert.Equal(t, uint64(5), dropped[1].View.Round)
+	}
+
+	// Make sure the vote for the current view is kept
+	assert.Equal(t, uint64(2), messages.DroppedMessages(DropSenderQuota))
+	assert.Equal(t, 2, messages.numMessages(&proto.View{Height: 1, Round: 1}, proto.MessageType_PREPARE))
+	assert.Equal(t, 1, messages.numMessages(&proto.View{Height: 1, Round: 3}, proto.MessageType_PREPARE))
+	assert.Equal(t, 0, messages.numMessages(&proto.View{Height: 1, Round: 5}, proto.MessageType_PREPARE))
+	assert.Equal(t, 0, messages.numMessages(&proto.View{Height: 1, Round: 9}, proto.MessageType_PREPARE))
+
+	// Make sure the dropped views are not left behind in the store
+	assert.Equal(t, &proto.View{Height: 1, Round: 3}, highestView(messages.getMessageMap(proto.MessageType_PREPARE)))
+
+	// Make sure the quota is per message type
+	messages.AddMessage(buildVote(
+		proto.MessageType_COMMIT,
+		"spammer",
+		&proto.View{Height: 1, Round: 1},
+		[]byte("proposal hash"),
+	))
+	assert.Equal(t, uint64(2), messages.DroppedMessages(DropSenderQuota))
+
+	// Make sure the pruned messages are not counted against the quota
+	messages.PruneByHeight(2)
+
+	messages.AddMessage(buildPrepare("spammer", 2, 0))
+	messages.AddMessage(buildPrepare("spammer", 2, 1))
+	assert.Equal(t, uint64(2), messages.DroppedMessages(DropSenderQuota))
+}
+
+func TestMessages_MemoryLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		messageSize = protobuf.Size(buildPrepare("0", 1, 0))
+		dropped     []*proto.Message
+	)
+
+	messages := NewMessages(
+		WithMemoryLimit(3*messageSize),
+		WithDropHandler(func(message *proto.Message, reason DropReason) {
+			assert.Equal(t, DropMemoryLimit, reason)
+
+			dropped = append(dropped, message)
+		}),
+	)
+	defer messages.Close()
+
+	// Fill up the memory with the messages for the far ahead view
+	messages.AddMessage(buildPrepare("0", 100, 0))
+	messages.AddMessage(buildPrepare("1", 100, 0))
+	messages.AddMessage(buildPrepare("0", 1, 0))
+	assert.Len(t, dropped, 0)
+	assert.Equal(t, 3*messageSize, messages.MemoryUsage())
+
+	// Make sure the messages for the furthest view are dropped
+	messages.AddMessage(buildPrepare("1", 1, 0))
+
+	assert.Len(t, dropped, 2)
+	assert.Equal(t, uint64(2), messages.DroppedMessages(DropMemoryLimit))
+	assert.Equal(t, 2*messageSize, messages.MemoryUsage())
+	assert.Equal(t, 0, messages.numMessages(&proto.View{Height: 100, Round: 0}, proto.MessageType_PREPARE))
+	assert.Equal(t, 2, messages.numMessages(&proto.View{Height: 1, Round: 0}, proto.MessageType_PREPARE))
+
+	// Make sure the messages furthest ahead are dropped first, even if new
+	messages.AddMessage(buildPrepare("2", 1, 0))
+	messages.AddMessage(buildPrepare("3", 1, 5))
+
+	if assert.Len(t, dropped, 3) {
+		assert.Equal(t, uint64(5), dropped[2].View.Round)
+	}
+
+	assert.Equal(t, 3*messageSize, messages.MemoryUsage())
+
+	// Make sure the removed messages are accounted for
+	messages.GetValidMessages(
+		&proto.View{Height: 1, Round: 0},
+		proto.MessageType_PREPARE,
+		func(message *proto.Message) bool {
+			return string(message.From) == "0"
+		},
+	)
+	assert.Equal(t, messageSize, messages.MemoryUsage())
+
+	messages.PruneByHeight(2)
+	assert.Zero(t, messages.MemoryUsage())
+}
+
+func TestDropReason_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "sender quota", DropSenderQuota.String())
+	assert.Equal(t, "memory limit", DropMemoryLimit.String())
+	assert.Equal(t, "unknown", DropReason(100).String())
+}
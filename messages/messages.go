@@ -6,6 +6,14 @@ import (
 	"github.com/nubank/go-ibft/messages/proto"
 )
 
+// messageTypes are all the message types
+var messageTypes = []proto.MessageType{
+	proto.MessageType_PREPREPARE,
+	proto.MessageType_PREPARE,
+	proto.MessageType_COMMIT,
+	proto.MessageType_ROUND_CHANGE,
+}
+
 // Messages contains the relevant messages for each view (height, round)
 type Messages struct {
 	// manager for incoming message events
@@ -19,6 +27,36 @@ type Messages struct {
 	prepareMessages,
 	commitMessages,
 	roundChangeMessages heightMessageMap
+
+	// equivocations are the detected conflicting messages, by height
+	equivocations     map[uint64][]Equivocation
+	equivocationsLock sync.RWMutex
+
+	// equivocationHandler is notified of each detected equivocation
+	equivocationHandler EquivocationHandler
+
+	// senderQuota is the maximum number of messages
+	// of each type kept for a single sender
+	senderQuota int
+
+	// senderCounts are the numbers of messages kept for each
+	// sender, by message type (protected by the type mutexes)
+	senderCounts map[proto.MessageType]map[string]int
+
+	// memoryLimit is the maximum summed size of the messages
+	memoryLimit int64
+
+	// memoryUsage is the summed size of the messages
+	memoryUsage int64
+
+	// evictionLock serializes the memory limit evictions
+	evictionLock sync.Mutex
+
+	// dropped are the numbers of dropped messages, by reason
+	dropped [numDropReasons]uint64
+
+	// dropHandler is notified of each dropped message
+	dropHandler DropHandler
 }
 
 // Subscribe creates a new message type subscription
@@ -44,13 +82,22 @@ func (ms *Messages) Unsubscribe(id SubscriptionID) {
 }
 
 // NewMessages returns a new Messages wrapper
-func NewMessages() *Messages {
-	return &Messages{
+func NewMessages(opts ...Option) *Messages {
+	ms := &Messages{
 		preprepareMessages:  make(heightMessageMap),
 		prepareMessages:     make(heightMessageMap),
 		commitMessages:      make(heightMessageMap),
 		roundChangeMessages: make(heightMessageMap),
 
+		equivocations: make(map[uint64][]Equivocation),
+
+		senderCounts: map[proto.MessageType]map[string]int{
+			proto.MessageType_PREPREPARE:   {},
+			proto.MessageType_PREPARE:      {},
+			proto.MessageType_COMMIT:       {},
+			proto.MessageType_ROUND_CHANGE: {},
+		},
+
 		eventManager: newEventManager(),
 
 		muxMap: map[proto.MessageType]*sync.RWMutex{
@@ -60,10 +107,42 @@ func NewMessages() *Messages {
 			proto.MessageType_ROUND_CHANGE: {},
 		},
 	}
+
+	for _, opt := range opts {
+		opt(ms)
+	}
+
+	return ms
 }
 
-// AddMessage adds a new message to the message queue
+// AddMessage adds a new message to the message queue.
+// If the sender already sent a conflicting message for the same view,
+// the first message is kept, and the equivocation is recorded
 func (ms *Messages) AddMessage(message *proto.Message) {
+	equivocation, evicted := ms.addMessage(message)
+
+	// The handlers are notified outside of the lock,
+	// so they're free to query the store
+	if equivocation != nil {
+		if ms.addEquivocation(*equivocation) && ms.equivocationHandler != nil {
+			ms.equivocationHandler(*equivocation)
+		}
+
+		return
+	}
+
+	if evicted != nil {
+		ms.drop([]*proto.Message{evicted}, DropSenderQuota)
+	}
+
+	ms.enforceMemoryLimit()
+}
+
+// addMessage adds a new message to the message queue,
+// unless it conflicts with an earlier message from the same sender.
+// If the sender is over its quota, the dropped message (either the
+// evicted one, or the new one) is returned
+func (ms *Messages) addMessage(message *proto.Message) (*Equivocation, *proto.Message) {
 	mux := ms.muxMap[message.Type]
 	mux.Lock()
 	defer mux.Unlock()
@@ -71,9 +150,29 @@ func (ms *Messages) AddMessage(message *proto.Message) {
 	// Get the corresponding height map
 	heightMsgMap := ms.getMessageMap(message.Type)
 
+	var evicted *proto.Message
+
+	existing, exists := heightMsgMap[message.View.Height][message.View.Round][string(message.From)]
+	switch {
+	case exists && IsEquivocation(existing, message):
+		return &Equivocation{
+			First:  existing,
+			Second: message,
+		}, nil
+	case exists:
+		ms.trackRemoved(existing)
+	default:
+		if evicted = ms.evictForQuota(message); evicted == message {
+			// The sender is over its quota, and the message
+			// is for a view further than all of its stored ones
+			return nil, evicted
+		}
+	}
+
 	// Append the message to the appropriate queue
 	messages := heightMsgMap.getViewMessages(message.View)
 	messages[string(message.From)] = message
+	ms.trackAdded(message)
 
 	ms.eventManager.signalEvent(
 		message.Type,
@@ -83,6 +182,8 @@ func (ms *Messages) AddMessage(message *proto.Message) {
 		},
 		len(messages),
 	)
+
+	return nil, evicted
 }
 
 func (ms *Messages) Close() {
@@ -134,15 +235,8 @@ func (ms *Messages) numMessages(
 // PruneByHeight prunes out all old messages from the message queues
 // by the specified height in the view
 func (ms *Messages) PruneByHeight(height uint64) {
-	possibleMaps := []proto.MessageType{
-		proto.MessageType_PREPREPARE,
-		proto.MessageType_PREPARE,
-		proto.MessageType_COMMIT,
-		proto.MessageType_ROUND_CHANGE,
-	}
-
 	// Prune out the views from all possible message types
-	for _, messageType := range possibleMaps {
+	for _, messageType := range messageTypes {
 		mux := ms.muxMap[messageType]
 		mux.Lock()
 
@@ -150,14 +244,17 @@ func (ms *Messages) PruneByHeight(height uint64) {
 
 		// Delete all height maps up until the specified
 		// view height
-		for msgHeight := range messageMap {
+		for msgHeight, roundMsgMap := range messageMap {
 			if msgHeight < height {
+				ms.trackRemovedRounds(roundMsgMap)
 				delete(messageMap, msgHeight)
 			}
 		}
 
 		mux.Unlock()
 	}
+
+	ms.pruneEquivocations(height)
 }
 
 // getProtoMessages fetches the underlying proto messages for the specified view
@@ -205,6 +302,7 @@ func (ms *Messages) GetValidMessages(
 
 	// Prune out invalid messages
 	for _, key := range invalidMessageKeys {
+		ms.trackRemoved(messages[key])
 		delete(messages, key)
 	}
 
@@ -252,6 +350,61 @@ func (ms *Messages) GetMostRoundChangeMessages(minRound, height uint64) []*proto
 	return messages
 }
 
+// GetHighestRoundChangeMessages fetches the highest round change message
+// of each sender, for the minimum round and above
+func (ms *Messages) GetHighestRoundChangeMessages(minRound, height uint64) []*proto.Message {
+	messageType := proto.MessageType_ROUND_CHANGE
+
+	mux := ms.muxMap[messageType]
+	mux.RLock()
+	defer mux.RUnlock()
+
+	roundMessageMap := ms.getMessageMap(messageType)[height]
+
+	highestMessages := make(map[string]*proto.Message)
+
+	for round, msgs := range roundMessageMap {
+		if round < minRound {
+			continue
+		}
+
+		for sender, msg := range msgs {
+			highest, exists := highestMessages[sender]
+			if !exists || highest.View.Round < round {
+				highestMessages[sender] = msg
+			}
+		}
+	}
+
+	messages := make([]*proto.Message, 0, len(highestMessages))
+	for _, msg := range highestMessages {
+		messages = append(messages, msg)
+	}
+
+	return messages
+}
+
+// GetHeightMessages fetches all messages of all types
+// for the specified height, across all rounds
+func (ms *Messages) GetHeightMessages(height uint64) []*proto.Message {
+	messages := make([]*proto.Message, 0)
+
+	for _, messageType := range messageTypes {
+		mux := ms.muxMap[messageType]
+		mux.RLock()
+
+		for _, msgs := range ms.getMessageMap(messageType)[height] {
+			for _, msg := range msgs {
+				messages = append(messages, msg)
+			}
+		}
+
+		mux.RUnlock()
+	}
+
+	return messages
+}
+
 // heightMessageMap maps the height number -> round message map
 type heightMessageMap map[uint64]roundMessageMap
 
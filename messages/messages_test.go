@@ -342,3 +342,86 @@ func TestMessages_EventManager(t *testing.T) {
 	// Make sure the number of messages is actually accurate
 	assert.Equal(t, numMessages, messages.numMessages(baseView, messageType))
 }
+
+// TestMessages_GetHighestRoundChangeMessages makes sure
+// the highest round change message of each sender is fetched
+func TestMessages_GetHighestRoundChangeMessages(t *testing.T) {
+	t.Parallel()
+
+	messages := NewMessages()
+	defer messages.Close()
+
+	// Senders 0 and 1 are on round 2, sender 2 on round 3
+	for round, count := range map[uint64]int{1: 3, 2: 2, 3: 3} {
+		for _, message := range generateRandomMessages(count, &proto.View{
+			Height: 0,
+			Round:  round,
+		}, proto.MessageType_ROUND_CHANGE) {
+			if round == 3 && string(message.From) != "2" {
+				continue
+			}
+
+			messages.AddMessage(message)
+		}
+	}
+
+	highestRounds := make(map[string]uint64)
+	for _, message := range messages.GetHighestRoundChangeMessages(2, 0) {
+		highestRounds[string(message.From)] = message.View.Round
+	}
+
+	assert.Equal(
+		t,
+		map[string]uint64{
+			"0": 2,
+			"1": 2,
+			"2": 3,
+		},
+		highestRounds,
+	)
+
+	// Make sure there are no messages for higher rounds
+	assert.Len(t, messages.GetHighestRoundChangeMessages(4, 0), 0)
+}
+
+// TestMessages_GetHeightMessages makes sure all
+// messages for the specified height are fetched
+func TestMessages_GetHeightMessages(t *testing.T) {
+	t.Parallel()
+
+	messages := NewMessages()
+	defer messages.Close()
+
+	messageTypes := []proto.MessageType{
+		proto.MessageType_PREPREPARE,
+		proto.MessageType_PREPARE,
+		proto.MessageType_COMMIT,
+		proto.MessageType_ROUND_CHANGE,
+	}
+
+	// Add messages for heights 1 and 2, on rounds 0 and 1
+	for _, messageType := range messageTypes {
+		for height := uint64(1); height <= 2; height++ {
+			for round := uint64(0); round <= 1; round++ {
+				for _, message := range generateRandomMessages(2, &proto.View{
+					Height: height,
+					Round:  round,
+				}, messageType) {
+					messages.AddMessage(message)
+				}
+			}
+		}
+	}
+
+	heightMessages := messages.GetHeightMessages(2)
+
+	// Make sure only the messages for height 2 are fetched
+	assert.Len(t, heightMessages, len(messageTypes)*2*2)
+
+	for _, message := range heightMessages {
+		assert.Equal(t, uint64(2), message.View.Height)
+	}
+
+	// Make sure there are no messages for unknown heights
+	assert.Len(t, messages.GetHeightMessages(3), 0)
+}
@@ -2,11 +2,13 @@ package proto
 
 import "google.golang.org/protobuf/proto"
 
+// PayloadNoSig returns the canonical (deterministic)
+// encoding of the message, without the signature
 func (m *Message) PayloadNoSig() ([]byte, error) {
 	mm, _ := proto.Clone(m).(*Message)
 	mm.Signature = nil
 
-	raw, err := proto.Marshal(mm)
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(mm)
 	if err != nil {
 		return nil, err
 	}
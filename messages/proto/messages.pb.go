@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.27.1
+// 	protoc-gen-go v1.28.1
 // 	protoc        v3.21.2
 // source: messages.proto
 
@@ -624,6 +624,403 @@ func (x *RoundChangeCertificate) GetRoundChangeMessages() []*Message {
 	return nil
 }
 
+// DuplicateVoteEvidence is the proof that a validator sent
+// two conflicting messages for the same view
+type DuplicateVoteEvidence struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// first is the first of the conflicting messages
+	First *Message `protobuf:"bytes,1,opt,name=first,proto3" json:"first,omitempty"`
+	// second is the second of the conflicting messages
+	Second *Message `protobuf:"bytes,2,opt,name=second,proto3" json:"second,omitempty"`
+}
+
+func (x *DuplicateVoteEvidence) Reset() {
+	*x = DuplicateVoteEvidence{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DuplicateVoteEvidence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DuplicateVoteEvidence) ProtoMessage() {}
+
+func (x *DuplicateVoteEvidence) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DuplicateVoteEvidence.ProtoReflect.Descriptor instead.
+func (*DuplicateVoteEvidence) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DuplicateVoteEvidence) GetFirst() *Message {
+	if x != nil {
+		return x.First
+	}
+	return nil
+}
+
+func (x *DuplicateVoteEvidence) GetSecond() *Message {
+	if x != nil {
+		return x.Second
+	}
+	return nil
+}
+
+// Evidence is the proof of a validator misbehaviour
+type Evidence struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// evidence is the specific misbehaviour proof
+	//
+	// Types that are assignable to Evidence:
+	//	*Evidence_DuplicateVote
+	Evidence isEvidence_Evidence `protobuf_oneof:"evidence"`
+}
+
+func (x *Evidence) Reset() {
+	*x = Evidence{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Evidence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Evidence) ProtoMessage() {}
+
+func (x *Evidence) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Evidence.ProtoReflect.Descriptor instead.
+func (*Evidence) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{9}
+}
+
+func (m *Evidence) GetEvidence() isEvidence_Evidence {
+	if m != nil {
+		return m.Evidence
+	}
+	return nil
+}
+
+func (x *Evidence) GetDuplicateVote() *DuplicateVoteEvidence {
+	if x, ok := x.GetEvidence().(*Evidence_DuplicateVote); ok {
+		return x.DuplicateVote
+	}
+	return nil
+}
+
+type isEvidence_Evidence interface {
+	isEvidence_Evidence()
+}
+
+type Evidence_DuplicateVote struct {
+	DuplicateVote *DuplicateVoteEvidence `protobuf:"bytes,1,opt,name=duplicateVote,proto3,oneof"`
+}
+
+func (*Evidence_DuplicateVote) isEvidence_Evidence() {}
+
+// CommittedSeal is the seal of a single validator
+type CommittedSeal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// signer is the validator that sealed the proposal
+	Signer []byte `protobuf:"bytes,1,opt,name=signer,proto3" json:"signer,omitempty"`
+	// signature is the seal signature over the proposal hash
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *CommittedSeal) Reset() {
+	*x = CommittedSeal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommittedSeal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommittedSeal) ProtoMessage() {}
+
+func (x *CommittedSeal) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommittedSeal.ProtoReflect.Descriptor instead.
+func (*CommittedSeal) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CommittedSeal) GetSigner() []byte {
+	if x != nil {
+		return x.Signer
+	}
+	return nil
+}
+
+func (x *CommittedSeal) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// CommittedSeals is a collection of validator seals
+type CommittedSeals struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// seals are the seals of the validators
+	Seals []*CommittedSeal `protobuf:"bytes,1,rep,name=seals,proto3" json:"seals,omitempty"`
+}
+
+func (x *CommittedSeals) Reset() {
+	*x = CommittedSeals{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommittedSeals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommittedSeals) ProtoMessage() {}
+
+func (x *CommittedSeals) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommittedSeals.ProtoReflect.Descriptor instead.
+func (*CommittedSeals) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CommittedSeals) GetSeals() []*CommittedSeal {
+	if x != nil {
+		return x.Seals
+	}
+	return nil
+}
+
+// AggregatedSeal is a collection of validator seals
+// aggregated into a single signature
+type AggregatedSeal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// signers is the bitmap of the validators whose
+	// seals are part of the signature
+	Signers []byte `protobuf:"bytes,1,opt,name=signers,proto3" json:"signers,omitempty"`
+	// signature is the aggregated signature
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *AggregatedSeal) Reset() {
+	*x = AggregatedSeal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AggregatedSeal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregatedSeal) ProtoMessage() {}
+
+func (x *AggregatedSeal) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregatedSeal.ProtoReflect.Descriptor instead.
+func (*AggregatedSeal) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AggregatedSeal) GetSigners() []byte {
+	if x != nil {
+		return x.Signers
+	}
+	return nil
+}
+
+func (x *AggregatedSeal) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// CommitCertificate is the proof that a proposal was finalized
+type CommitCertificate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// view is the view in which the proposal was finalized.
+	// It is not covered by the seals
+	View *View `protobuf:"bytes,1,opt,name=view,proto3" json:"view,omitempty"`
+	// proposalHash is the hash of the finalized proposal
+	ProposalHash []byte `protobuf:"bytes,2,opt,name=proposalHash,proto3" json:"proposalHash,omitempty"`
+	// seals are the committed seals of the proposal
+	//
+	// Types that are assignable to Seals:
+	//	*CommitCertificate_CommittedSeals
+	//	*CommitCertificate_AggregatedSeal
+	Seals isCommitCertificate_Seals `protobuf_oneof:"seals"`
+}
+
+func (x *CommitCertificate) Reset() {
+	*x = CommitCertificate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommitCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitCertificate) ProtoMessage() {}
+
+func (x *CommitCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitCertificate.ProtoReflect.Descriptor instead.
+func (*CommitCertificate) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CommitCertificate) GetView() *View {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+func (x *CommitCertificate) GetProposalHash() []byte {
+	if x != nil {
+		return x.ProposalHash
+	}
+	return nil
+}
+
+func (m *CommitCertificate) GetSeals() isCommitCertificate_Seals {
+	if m != nil {
+		return m.Seals
+	}
+	return nil
+}
+
+func (x *CommitCertificate) GetCommittedSeals() *CommittedSeals {
+	if x, ok := x.GetSeals().(*CommitCertificate_CommittedSeals); ok {
+		return x.CommittedSeals
+	}
+	return nil
+}
+
+func (x *CommitCertificate) GetAggregatedSeal() *AggregatedSeal {
+	if x, ok := x.GetSeals().(*CommitCertificate_AggregatedSeal); ok {
+		return x.AggregatedSeal
+	}
+	return nil
+}
+
+type isCommitCertificate_Seals interface {
+	isCommitCertificate_Seals()
+}
+
+type CommitCertificate_CommittedSeals struct {
+	CommittedSeals *CommittedSeals `protobuf:"bytes,3,opt,name=committedSeals,proto3,oneof"`
+}
+
+type CommitCertificate_AggregatedSeal struct {
+	AggregatedSeal *AggregatedSeal `protobuf:"bytes,4,opt,name=aggregatedSeal,proto3,oneof"`
+}
+
+func (*CommitCertificate_CommittedSeals) isCommitCertificate_Seals() {}
+
+func (*CommitCertificate_AggregatedSeal) isCommitCertificate_Seals() {}
+
 var File_messages_proto protoreflect.FileDescriptor
 
 var file_messages_proto_rawDesc = []byte{
@@ -696,7 +1093,44 @@ var file_messages_proto_rawDesc = []byte{
 	0x68, 0x61, 0x6e, 0x67, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20,
 	0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x13, 0x72,
 	0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x73, 0x2a, 0x48, 0x0a, 0x0b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x73, 0x22, 0x59, 0x0a, 0x15, 0x44, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x56,
+	0x6f, 0x74, 0x65, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1e, 0x0a, 0x05, 0x66,
+	0x69, 0x72, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x52, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x06, 0x73,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x06, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x22, 0x56, 0x0a,
+	0x08, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x3e, 0x0a, 0x0d, 0x64, 0x75, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x44, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65,
+	0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x48, 0x00, 0x52, 0x0d, 0x64, 0x75, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x65, 0x76, 0x69,
+	0x64, 0x65, 0x6e, 0x63, 0x65, 0x22, 0x45, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74,
+	0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x1c,
+	0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x36, 0x0a, 0x0e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x24,
+	0x0a, 0x05, 0x73, 0x65, 0x61, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x52, 0x05, 0x73,
+	0x65, 0x61, 0x6c, 0x73, 0x22, 0x48, 0x0a, 0x0e, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74,
+	0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x73,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0xd1,
+	0x01, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x04, 0x76, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x05, 0x2e, 0x56, 0x69, 0x65, 0x77, 0x52, 0x04, 0x76, 0x69, 0x65, 0x77, 0x12,
+	0x22, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48,
+	0x61, 0x73, 0x68, 0x12, 0x39, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64,
+	0x53, 0x65, 0x61, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x73, 0x48, 0x00, 0x52, 0x0e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x39,
+	0x0a, 0x0e, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61,
+	0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x0e, 0x61, 0x67, 0x67, 0x72, 0x65,
+	0x67, 0x61, 0x74, 0x65, 0x64, 0x53, 0x65, 0x61, 0x6c, 0x42, 0x07, 0x0a, 0x05, 0x73, 0x65, 0x61,
+	0x6c, 0x73, 0x2a, 0x48, 0x0a, 0x0b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70,
 	0x65, 0x12, 0x0e, 0x0a, 0x0a, 0x50, 0x52, 0x45, 0x50, 0x52, 0x45, 0x50, 0x41, 0x52, 0x45, 0x10,
 	0x00, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x52, 0x45, 0x50, 0x41, 0x52, 0x45, 0x10, 0x01, 0x12, 0x0a,
 	0x0a, 0x06, 0x43, 0x4f, 0x4d, 0x4d, 0x49, 0x54, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x52, 0x4f,
@@ -718,7 +1152,7 @@ func file_messages_proto_rawDescGZIP() []byte {
 }
 
 var file_messages_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_messages_proto_goTypes = []interface{}{
 	(MessageType)(0),               // 0: MessageType
 	(*View)(nil),                   // 1: View
@@ -729,6 +1163,12 @@ var file_messages_proto_goTypes = []interface{}{
 	(*RoundChangeMessage)(nil),     // 6: RoundChangeMessage
 	(*PreparedCertificate)(nil),    // 7: PreparedCertificate
 	(*RoundChangeCertificate)(nil), // 8: RoundChangeCertificate
+	(*DuplicateVoteEvidence)(nil),  // 9: DuplicateVoteEvidence
+	(*Evidence)(nil),               // 10: Evidence
+	(*CommittedSeal)(nil),          // 11: CommittedSeal
+	(*CommittedSeals)(nil),         // 12: CommittedSeals
+	(*AggregatedSeal)(nil),         // 13: AggregatedSeal
+	(*CommitCertificate)(nil),      // 14: CommitCertificate
 }
 var file_messages_proto_depIdxs = []int32{
 	1,  // 0: Message.view:type_name -> View
@@ -742,11 +1182,18 @@ var file_messages_proto_depIdxs = []int32{
 	2,  // 8: PreparedCertificate.proposalMessage:type_name -> Message
 	2,  // 9: PreparedCertificate.prepareMessages:type_name -> Message
 	2,  // 10: RoundChangeCertificate.roundChangeMessages:type_name -> Message
-	11, // [11:11] is the sub-list for method output_type
-	11, // [11:11] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	2,  // 11: DuplicateVoteEvidence.first:type_name -> Message
+	2,  // 12: DuplicateVoteEvidence.second:type_name -> Message
+	9,  // 13: Evidence.duplicateVote:type_name -> DuplicateVoteEvidence
+	11, // 14: CommittedSeals.seals:type_name -> CommittedSeal
+	1,  // 15: CommitCertificate.view:type_name -> View
+	12, // 16: CommitCertificate.committedSeals:type_name -> CommittedSeals
+	13, // 17: CommitCertificate.aggregatedSeal:type_name -> AggregatedSeal
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_messages_proto_init() }
@@ -851,6 +1298,78 @@ func file_messages_proto_init() {
 				return nil
 			}
 		}
+		file_messages_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DuplicateVoteEvidence); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Evidence); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommittedSeal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommittedSeals); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AggregatedSeal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommitCertificate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_messages_proto_msgTypes[1].OneofWrappers = []interface{}{
 		(*Message_PreprepareData)(nil),
@@ -858,13 +1377,20 @@ func file_messages_proto_init() {
 		(*Message_CommitData)(nil),
 		(*Message_RoundChangeData)(nil),
 	}
+	file_messages_proto_msgTypes[9].OneofWrappers = []interface{}{
+		(*Evidence_DuplicateVote)(nil),
+	}
+	file_messages_proto_msgTypes[13].OneofWrappers = []interface{}{
+		(*CommitCertificate_CommittedSeals)(nil),
+		(*CommitCertificate_AggregatedSeal)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_messages_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   8,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,153 @@
+package messages
+
+import (
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// ValidatorSet defines the ordered set of validators,
+// and their voting power distribution, for a single height
+type ValidatorSet interface {
+	// Validators returns the ordered list of validators
+	Validators() []Validator
+
+	// Index returns the position of the validator in the
+	// list of validators, if the node is a validator
+	Index(id []byte) (int, bool)
+
+	// VotingPower returns the voting power of the validator.
+	// Nodes that are not validators have no voting power
+	VotingPower(id []byte) uint64
+
+	// TotalVotingPower returns the sum of the
+	// voting powers of all the validators
+	TotalVotingPower() uint64
+}
+
+// Validator is a single validator with its voting power
+type Validator struct {
+	ID          []byte
+	VotingPower uint64
+}
+
+// validatorSet is the ValidatorSet based on a static list of validators
+type validatorSet struct {
+	validators []Validator
+	indices    map[string]int
+	totalPower uint64
+}
+
+// NewValidatorSet creates a new validator set from the ordered
+// list of validators. Validators listed more than once keep their
+// first position, and have their voting powers summed up
+func NewValidatorSet(validators []Validator) ValidatorSet {
+	set := &validatorSet{
+		validators: make([]Validator, 0, len(validators)),
+		indices:    make(map[string]int, len(validators)),
+	}
+
+	for _, validator := range validators {
+		set.totalPower += validator.VotingPower
+
+		if index, exists := set.indices[string(validator.ID)]; exists {
+			set.validators[index].VotingPower += validator.VotingPower
+
+			continue
+		}
+
+		set.indices[string(validator.ID)] = len(set.validators)
+		set.validators = append(set.validators, validator)
+	}
+
+	return set
+}
+
+// NewEqualValidatorSet creates a new validator set in which all
+// the validators have the same voting power (a voting power of 1)
+func NewEqualValidatorSet(ids [][]byte) ValidatorSet {
+	validators := make([]Validator, len(ids))
+	for index, id := range ids {
+		validators[index] = Validator{
+			ID:          id,
+			VotingPower: 1,
+		}
+	}
+
+	return NewValidatorSet(validators)
+}
+
+func (s *validatorSet) Validators() []Validator {
+	validators := make([]Validator, len(s.validators))
+	copy(validators, s.validators)
+
+	return validators
+}
+
+func (s *validatorSet) Index(id []byte) (int, bool) {
+	index, exists := s.indices[string(id)]
+
+	return index, exists
+}
+
+func (s *validatorSet) VotingPower(id []byte) uint64 {
+	index, exists := s.indices[string(id)]
+	if !exists {
+		return 0
+	}
+
+	return s.validators[index].VotingPower
+}
+
+func (s *validatorSet) TotalVotingPower() uint64 {
+	return s.totalPower
+}
+
+// QuorumVotingPower returns the minimum voting power
+// that forms a quorum (more than 2/3 of the total voting power)
+func QuorumVotingPower(set ValidatorSet) uint64 {
+	total := set.TotalVotingPower()
+
+	// floor(2 * total / 3) + 1, without overflowing
+	return total - ceilDiv(total, 3) + 1
+}
+
+// MaximumFaultyVotingPower returns the maximum voting power that can be
+// faulty (less than 1/3 of the total voting power). Any voting power above
+// it is guaranteed to include at least one honest validator
+func MaximumFaultyVotingPower(set ValidatorSet) uint64 {
+	total := set.TotalVotingPower()
+	if total == 0 {
+		return 0
+	}
+
+	return (total - 1) / 3
+}
+
+// SendersVotingPower returns the summed voting power of the distinct
+// message senders. Senders that are not validators are not counted
+func SendersVotingPower(set ValidatorSet, messages []*proto.Message) uint64 {
+	var (
+		power   uint64
+		senders = make(map[string]struct{}, len(messages))
+	)
+
+	for _, message := range messages {
+		if _, exists := senders[string(message.From)]; exists {
+			continue
+		}
+
+		senders[string(message.From)] = struct{}{}
+		power += set.VotingPower(message.From)
+	}
+
+	return power
+}
+
+// ceilDiv returns the ceiling of a / b
+func ceilDiv(a, b uint64) uint64 {
+	result := a / b
+	if a%b != 0 {
+		result++
+	}
+
+	return result
+}
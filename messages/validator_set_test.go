@@ -0,0 +1,126 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorSet_VotingPower(t *testing.T) {
+	t.Parallel()
+
+	set := NewValidatorSet([]Validator{
+		{ID: []byte("node 0"), VotingPower: 5},
+		{ID: []byte("node 1"), VotingPower: 2},
+		{ID: []byte("node 1"), VotingPower: 1},
+	})
+
+	assert.Equal(t, uint64(5), set.VotingPower([]byte("node 0")))
+	assert.Equal(t, uint64(3), set.VotingPower([]byte("node 1")))
+	assert.Zero(t, set.VotingPower([]byte("node 2")))
+	assert.Equal(t, uint64(8), set.TotalVotingPower())
+}
+
+func TestValidatorSet_Validators(t *testing.T) {
+	t.Parallel()
+
+	set := NewValidatorSet([]Validator{
+		{ID: []byte("node 2"), VotingPower: 1},
+		{ID: []byte("node 0"), VotingPower: 5},
+		{ID: []byte("node 2"), VotingPower: 2},
+	})
+
+	// Make sure the order is kept, and duplicates are merged
+	assert.Equal(
+		t,
+		[]Validator{
+			{ID: []byte("node 2"), VotingPower: 3},
+			{ID: []byte("node 0"), VotingPower: 5},
+		},
+		set.Validators(),
+	)
+
+	index, ok := set.Index([]byte("node 0"))
+	assert.True(t, ok)
+	assert.Equal(t, 1, index)
+
+	_, ok = set.Index([]byte("node 1"))
+	assert.False(t, ok)
+
+	// Make sure the returned list can't modify the set
+	set.Validators()[0].VotingPower = 100
+	assert.Equal(t, uint64(3), set.VotingPower([]byte("node 2")))
+}
+
+func TestValidatorSet_Thresholds(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name           string
+		totalPower     uint64
+		expectedQuorum uint64
+		expectedFaulty uint64
+	}{
+		{"no voting power", 0, 1, 0},
+		{"single validator", 1, 1, 0},
+		{"total power 3", 3, 3, 0},
+		{"total power 4", 4, 3, 1},
+		{"total power 6", 6, 5, 1},
+		{"total power 7", 7, 5, 2},
+		{"total power 100", 100, 67, 33},
+		{"huge total power", ^uint64(0), ^uint64(0)/3*2 + 1, ^uint64(0)/3 - 1},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			set := NewValidatorSet([]Validator{
+				{ID: []byte("node"), VotingPower: testCase.totalPower},
+			})
+
+			assert.Equal(t, testCase.expectedQuorum, QuorumVotingPower(set))
+			assert.Equal(t, testCase.expectedFaulty, MaximumFaultyVotingPower(set))
+		})
+	}
+}
+
+func TestValidatorSet_EqualVotingPower(t *testing.T) {
+	t.Parallel()
+
+	set := NewEqualValidatorSet([][]byte{
+		[]byte("node 0"),
+		[]byte("node 1"),
+		[]byte("node 2"),
+		[]byte("node 3"),
+	})
+
+	// Make sure the thresholds match the count based ones (N = 4)
+	assert.Equal(t, uint64(4), set.TotalVotingPower())
+	assert.Equal(t, uint64(3), QuorumVotingPower(set))
+	assert.Equal(t, uint64(1), MaximumFaultyVotingPower(set))
+}
+
+func TestValidatorSet_SendersVotingPower(t *testing.T) {
+	t.Parallel()
+
+	set := NewValidatorSet([]Validator{
+		{ID: []byte("node 0"), VotingPower: 5},
+		{ID: []byte("node 1"), VotingPower: 2},
+	})
+
+	messages := []*proto.Message{
+		{From: []byte("node 0"), Type: proto.MessageType_PREPARE},
+		{From: []byte("node 0"), Type: proto.MessageType_COMMIT},
+		{From: []byte("node 1"), Type: proto.MessageType_PREPARE},
+		{From: []byte("node 2"), Type: proto.MessageType_PREPARE},
+	}
+
+	// Make sure each sender is counted once,
+	// and non-validators are not counted
+	assert.Equal(t, uint64(7), SendersVotingPower(set, messages))
+	assert.Zero(t, SendersVotingPower(set, nil))
+}
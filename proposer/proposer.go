@@ -0,0 +1,88 @@
+// Package proposer provides the proposer election strategies
+// a Backend can delegate Verifier.IsProposer to
+package proposer
+
+import (
+	"bytes"
+
+	"github.com/nubank/go-ibft/messages"
+)
+
+// Elector selects the proposer for a view (height, round)
+type Elector interface {
+	// Proposer returns the ID of the proposer for the view,
+	// or nil if there are no eligible validators
+	Proposer(height, round uint64) []byte
+}
+
+// IsProposer checks if the passed in ID is the proposer for the view
+func IsProposer(elector Elector, id []byte, height, round uint64) bool {
+	proposer := elector.Proposer(height, round)
+	if proposer == nil {
+		return false
+	}
+
+	return bytes.Equal(proposer, id)
+}
+
+// RoundRobinElector rotates the proposer through the validators,
+// moving to the next validator with each height and each round
+type RoundRobinElector struct {
+	validators []messages.Validator
+}
+
+// NewRoundRobinElector creates a new round-robin elector
+// over the validators in the set
+func NewRoundRobinElector(set messages.ValidatorSet) *RoundRobinElector {
+	return &RoundRobinElector{
+		validators: set.Validators(),
+	}
+}
+
+// Proposer returns the validator at the position (height + round)
+func (e *RoundRobinElector) Proposer(height, round uint64) []byte {
+	if len(e.validators) == 0 {
+		return nil
+	}
+
+	return e.validators[offsetIndex(height, round, len(e.validators))].ID
+}
+
+// StickyElector keeps the proposer of the previous block for round 0,
+// and moves to the next validator with each round (Istanbul-style
+// "last proposer + round")
+type StickyElector struct {
+	validators []messages.Validator
+	lastIndex  uint64
+}
+
+// NewStickyElector creates a new sticky elector over the validators in the set.
+// The lastProposer is the proposer of the previous block. If it is not
+// in the set (ex. the first block), the rotation starts at the first validator
+func NewStickyElector(set messages.ValidatorSet, lastProposer []byte) *StickyElector {
+	elector := &StickyElector{
+		validators: set.Validators(),
+	}
+
+	if index, ok := set.Index(lastProposer); ok {
+		elector.lastIndex = uint64(index)
+	}
+
+	return elector
+}
+
+// Proposer returns the validator at the position (last proposer + round)
+func (e *StickyElector) Proposer(_, round uint64) []byte {
+	if len(e.validators) == 0 {
+		return nil
+	}
+
+	return e.validators[offsetIndex(e.lastIndex, round, len(e.validators))].ID
+}
+
+// offsetIndex returns (base + offset) mod size, without overflowing
+func offsetIndex(base, offset uint64, size int) int {
+	n := uint64(size)
+
+	return int((base%n + offset%n) % n)
+}
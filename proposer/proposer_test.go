@@ -0,0 +1,312 @@
+package proposer
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+// generateIDs generates dummy validator IDs
+func generateIDs(count int) [][]byte {
+	ids := make([][]byte, count)
+	for index := range ids {
+		ids[index] = []byte(fmt.Sprintf("node %d", index))
+	}
+
+	return ids
+}
+
+// generateValidatorSets generates validator sets
+// of random sizes, with random voting powers
+func generateValidatorSets(count int) []messages.ValidatorSet {
+	var (
+		random = rand.New(rand.NewSource(1))
+		sets   = make([]messages.ValidatorSet, count)
+	)
+
+	for index := range sets {
+		ids := generateIDs(1 + random.Intn(20))
+		validators := make([]messages.Validator, len(ids))
+
+		for validatorIndex, id := range ids {
+			validators[validatorIndex] = messages.Validator{
+				ID:          id,
+				VotingPower: uint64(random.Intn(10)),
+			}
+		}
+
+		// Make sure at least one validator has voting power
+		validators[0].VotingPower++
+
+		sets[index] = messages.NewValidatorSet(validators)
+	}
+
+	return sets
+}
+
+// countProposers counts how many times each
+// validator is the proposer in the views
+func countProposers(
+	elector Elector,
+	height uint64,
+	views int,
+	nextView func(height, round uint64) (uint64, uint64),
+) map[string]int {
+	var (
+		counts = make(map[string]int)
+		round  uint64
+	)
+
+	for view := 0; view < views; view++ {
+		counts[string(elector.Proposer(height, round))]++
+
+		height, round = nextView(height, round)
+	}
+
+	return counts
+}
+
+// nextHeight moves to the next height
+func nextHeight(height, _ uint64) (uint64, uint64) {
+	return height + 1, 0
+}
+
+// nextRound moves to the next round
+func nextRound(height, round uint64) (uint64, uint64) {
+	return height, round + 1
+}
+
+func TestIsProposer(t *testing.T) {
+	t.Parallel()
+
+	ids := generateIDs(3)
+	elector := NewRoundRobinElector(messages.NewEqualValidatorSet(ids))
+
+	assert.True(t, IsProposer(elector, ids[1], 1, 0))
+	assert.False(t, IsProposer(elector, ids[0], 1, 0))
+
+	// Make sure nobody is the proposer without validators
+	empty := NewRoundRobinElector(messages.NewValidatorSet(nil))
+
+	assert.False(t, IsProposer(empty, nil, 1, 0))
+}
+
+func TestRoundRobinElector(t *testing.T) {
+	t.Parallel()
+
+	ids := generateIDs(4)
+	elector := NewRoundRobinElector(messages.NewEqualValidatorSet(ids))
+
+	testTable := []struct {
+		name     string
+		height   uint64
+		round    uint64
+		expected []byte
+	}{
+		{"first view", 0, 0, ids[0]},
+		{"next height", 1, 0, ids[1]},
+		{"next round", 1, 2, ids[3]},
+		{"wraps around", 3, 2, ids[1]},
+		{"huge view does not overflow", ^uint64(0), ^uint64(0), ids[2]},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, elector.Proposer(testCase.height, testCase.round))
+		})
+	}
+}
+
+func TestStickyElector(t *testing.T) {
+	t.Parallel()
+
+	ids := generateIDs(4)
+	set := messages.NewEqualValidatorSet(ids)
+
+	t.Run("last proposer keeps round 0", func(t *testing.T) {
+		t.Parallel()
+
+		elector := NewStickyElector(set, ids[2])
+
+		assert.Equal(t, ids[2], elector.Proposer(10, 0))
+		assert.Equal(t, ids[3], elector.Proposer(10, 1))
+		assert.Equal(t, ids[0], elector.Proposer(10, 2))
+	})
+
+	t.Run("unknown last proposer", func(t *testing.T) {
+		t.Parallel()
+
+		elector := NewStickyElector(set, []byte("unknown"))
+
+		assert.Equal(t, ids[0], elector.Proposer(10, 0))
+		assert.Equal(t, ids[1], elector.Proposer(10, 1))
+	})
+}
+
+func TestWeightedElector(t *testing.T) {
+	t.Parallel()
+
+	ids := generateIDs(3)
+
+	t.Run("schedule is interleaved", func(t *testing.T) {
+		t.Parallel()
+
+		elector := NewWeightedElector(messages.NewValidatorSet([]messages.Validator{
+			{ID: ids[0], VotingPower: 5},
+			{ID: ids[1], VotingPower: 1},
+			{ID: ids[2], VotingPower: 1},
+		}))
+
+		proposers := make([][]byte, 0)
+		for height := uint64(0); height < 7; height++ {
+			proposers = append(proposers, elector.Proposer(height, 0))
+		}
+
+		assert.Equal(
+			t,
+			[][]byte{ids[0], ids[0], ids[1], ids[0], ids[2], ids[0], ids[0]},
+			proposers,
+		)
+	})
+
+	t.Run("ties are broken by the set order", func(t *testing.T) {
+		t.Parallel()
+
+		elector := NewWeightedElector(messages.NewEqualValidatorSet(ids))
+
+		assert.Equal(t, ids[0], elector.Proposer(0, 0))
+		assert.Equal(t, ids[1], elector.Proposer(0, 1))
+		assert.Equal(t, ids[2], elector.Proposer(0, 2))
+	})
+
+	t.Run("validators without voting power are skipped", func(t *testing.T) {
+		t.Parallel()
+
+		elector := NewWeightedElector(messages.NewValidatorSet([]messages.Validator{
+			{ID: ids[0], VotingPower: 0},
+			{ID: ids[1], VotingPower: 2},
+		}))
+
+		for round := uint64(0); round < 10; round++ {
+			assert.Equal(t, ids[1], elector.Proposer(0, round))
+		}
+	})
+
+	t.Run("large coprime voting powers", func(t *testing.T) {
+		t.Parallel()
+
+		elector := NewWeightedElector(messages.NewValidatorSet([]messages.Validator{
+			{ID: ids[0], VotingPower: 1_000_000_007},
+			{ID: ids[1], VotingPower: 1_000_000_009},
+			{ID: ids[2], VotingPower: 2_000_000_000},
+		}))
+
+		// The schedule is scaled down to a bounded resolution
+		assert.LessOrEqual(t, len(elector.schedule), maxScheduleLength)
+
+		counts := countProposers(elector, 0, len(elector.schedule), nextHeight)
+
+		assert.InDelta(t, len(elector.schedule)/4, counts[string(ids[0])], 1)
+		assert.InDelta(t, len(elector.schedule)/4, counts[string(ids[1])], 1)
+		assert.InDelta(t, len(elector.schedule)/2, counts[string(ids[2])], 1)
+	})
+
+	t.Run("voting powers above MaxInt64", func(t *testing.T) {
+		t.Parallel()
+
+		elector := NewWeightedElector(messages.NewValidatorSet([]messages.Validator{
+			{ID: ids[0], VotingPower: math.MaxUint64 - 1},
+			{ID: ids[1], VotingPower: 1},
+		}))
+
+		assert.LessOrEqual(t, len(elector.schedule), maxScheduleLength)
+
+		counts := countProposers(elector, 0, len(elector.schedule), nextHeight)
+
+		// The validator with a negligible share still gets a slot
+		assert.Equal(t, 1, counts[string(ids[1])])
+		assert.Equal(t, len(elector.schedule)-1, counts[string(ids[0])])
+	})
+
+	t.Run("no voting power", func(t *testing.T) {
+		t.Parallel()
+
+		elector := NewWeightedElector(messages.NewValidatorSet([]messages.Validator{
+			{ID: ids[0], VotingPower: 0},
+		}))
+
+		assert.Nil(t, elector.Proposer(0, 0))
+	})
+}
+
+// TestElectors_Fairness makes sure every validator is chosen
+// fairly, over consecutive heights and over consecutive rounds
+func TestElectors_Fairness(t *testing.T) {
+	t.Parallel()
+
+	const periods = 3
+
+	nextViews := []func(uint64, uint64) (uint64, uint64){nextHeight, nextRound}
+
+	for setIndex, set := range generateValidatorSets(50) {
+		var (
+			validators = set.Validators()
+			size       = len(validators)
+			start      = uint64(setIndex * 1000)
+		)
+
+		// Make sure each validator is chosen equally
+		// often by the round-robin elector
+		roundRobin := NewRoundRobinElector(set)
+
+		for _, next := range nextViews {
+			counts := countProposers(roundRobin, start, periods*size, next)
+
+			for _, validator := range validators {
+				assert.Equal(t, periods, counts[string(validator.ID)], "round robin set %d", setIndex)
+			}
+		}
+
+		// Make sure each validator is chosen equally often
+		// by the sticky elector, as the rounds change
+		sticky := NewStickyElector(set, validators[setIndex%size].ID)
+		counts := countProposers(sticky, start, periods*size, nextRound)
+
+		for _, validator := range validators {
+			assert.Equal(t, periods, counts[string(validator.ID)], "sticky set %d", setIndex)
+		}
+
+		// Make sure each validator is chosen in proportion
+		// to its voting power by the weighted elector, regardless
+		// of where the window starts
+		var divisor uint64
+		for _, validator := range validators {
+			divisor = gcd(divisor, validator.VotingPower)
+		}
+
+		period := int(set.TotalVotingPower() / divisor)
+		elector := NewWeightedElector(set)
+
+		for _, next := range nextViews {
+			counts := countProposers(elector, start, periods*period, next)
+
+			for _, validator := range validators {
+				assert.Equal(
+					t,
+					periods*int(validator.VotingPower/divisor),
+					counts[string(validator.ID)],
+					"weighted set %d",
+					setIndex,
+				)
+			}
+		}
+	}
+}
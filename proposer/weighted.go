@@ -0,0 +1,165 @@
+package proposer
+
+import (
+	"math/big"
+
+	"github.com/nubank/go-ibft/messages"
+)
+
+// maxScheduleLength is the upper bound on the length of a single
+// period of the weighted schedule. Voting powers whose period would
+// exceed it are scaled down to this resolution
+const maxScheduleLength = 1 << 16
+
+// WeightedElector selects the proposers proportionally to the validator
+// voting power, using the smooth weighted round-robin schedule: with each
+// step, every validator's priority grows by its voting power, the validator
+// with the highest priority is selected, and its priority is reduced by the
+// total voting power. Ties are broken in favor of the validator that comes first
+// in the set. The schedule repeats every (total voting power / GCD of the
+// voting powers) steps, in which each validator is selected exactly in
+// proportion to its voting power, and interleaved with the others.
+// The schedule is precomputed. If the period would be longer than
+// maxScheduleLength steps, the voting powers are first scaled down
+// proportionally to that resolution (every validator with voting power
+// keeps at least a single slot), so the selection frequencies
+// approximate the voting power shares
+type WeightedElector struct {
+	validators []messages.Validator
+	schedule   []int
+}
+
+// NewWeightedElector creates a new stake-weighted elector
+// over the validators in the set
+func NewWeightedElector(set messages.ValidatorSet) *WeightedElector {
+	validators := set.Validators()
+
+	return &WeightedElector{
+		validators: validators,
+		schedule:   buildSchedule(validators),
+	}
+}
+
+// Proposer returns the validator at the position (height + round)
+// of the weighted schedule
+func (e *WeightedElector) Proposer(height, round uint64) []byte {
+	if len(e.schedule) == 0 {
+		return nil
+	}
+
+	return e.validators[e.schedule[offsetIndex(height, round, len(e.schedule))]].ID
+}
+
+// buildSchedule builds a single period of the smooth
+// weighted round-robin schedule for the validators
+func buildSchedule(validators []messages.Validator) []int {
+	weights, total := scheduleWeights(validators)
+	if total == 0 {
+		// No validator has any voting power
+		return nil
+	}
+
+	var (
+		schedule   = make([]int, 0, total)
+		priorities = make([]int64, len(validators))
+	)
+
+	for step := int64(0); step < total; step++ {
+		selected := -1
+
+		for index, weight := range weights {
+			if weight == 0 {
+				continue
+			}
+
+			priorities[index] += weight
+
+			if selected == -1 || priorities[index] > priorities[selected] {
+				selected = index
+			}
+		}
+
+		priorities[selected] -= total
+		schedule = append(schedule, selected)
+	}
+
+	return schedule
+}
+
+// scheduleWeights returns the per-validator weights of the schedule, along
+// with their sum (the period length). The voting powers are reduced by their
+// GCD, and scaled down to maxScheduleLength if the period would still be longer
+func scheduleWeights(validators []messages.Validator) ([]int64, int64) {
+	var (
+		divisor    uint64
+		totalPower = new(big.Int)
+		weights    = make([]int64, len(validators))
+	)
+
+	for _, validator := range validators {
+		divisor = gcd(divisor, validator.VotingPower)
+		totalPower.Add(totalPower, new(big.Int).SetUint64(validator.VotingPower))
+	}
+
+	if divisor == 0 {
+		return weights, 0
+	}
+
+	period := new(big.Int).Div(totalPower, new(big.Int).SetUint64(divisor))
+
+	if period.Cmp(big.NewInt(maxScheduleLength)) <= 0 {
+		// The exact schedule is small enough
+		var total int64
+
+		for index, validator := range validators {
+			weights[index] = int64(validator.VotingPower / divisor)
+			total += weights[index]
+		}
+
+		return weights, total
+	}
+
+	// Scale the voting powers down to the schedule resolution,
+	// making sure every validator with voting power keeps a slot
+	var (
+		resolution = big.NewInt(maxScheduleLength)
+		scaled     = new(big.Int)
+		divisors   uint64
+	)
+
+	for index, validator := range validators {
+		if validator.VotingPower == 0 {
+			continue
+		}
+
+		scaled.SetUint64(validator.VotingPower)
+		scaled.Mul(scaled, resolution)
+		scaled.Div(scaled, totalPower)
+
+		weight := scaled.Uint64()
+		if weight == 0 {
+			weight = 1
+		}
+
+		weights[index] = int64(weight)
+		divisors = gcd(divisors, weight)
+	}
+
+	var total int64
+
+	for index := range weights {
+		weights[index] /= int64(divisors)
+		total += weights[index]
+	}
+
+	return weights, total
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
@@ -0,0 +1,151 @@
+// Package signing provides the message signers and signature
+// verifiers the IBFT core can use instead of backend specific code.
+// The signer ID is used as the message sender (proto.Message.From)
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+const (
+	// ecdsaSignatureSize is the size of the (r || s) signature
+	ecdsaSignatureSize = 64
+
+	// compressedKeySize is the size of the compressed public key
+	compressedKeySize = 33
+)
+
+var (
+	errNotSecp256k1   = errors.New("private key is not a secp256k1 key")
+	errInvalidEd25519 = errors.New("invalid ed25519 private key")
+)
+
+// Ed25519Signer signs the messages with an Ed25519 private key.
+// Its ID is the Ed25519 public key
+type Ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewEd25519Signer creates a new Ed25519 signer
+func NewEd25519Signer(key ed25519.PrivateKey) (*Ed25519Signer, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errInvalidEd25519
+	}
+
+	return &Ed25519Signer{
+		key: key,
+	}, nil
+}
+
+// ID returns the public key of the signer
+func (s *Ed25519Signer) ID() []byte {
+	publicKey, _ := s.key.Public().(ed25519.PublicKey)
+
+	return []byte(publicKey)
+}
+
+// Sign signs the payload
+func (s *Ed25519Signer) Sign(payload []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, payload), nil
+}
+
+// Ed25519Verifier verifies the Ed25519 signatures,
+// where the signer is the Ed25519 public key
+type Ed25519Verifier struct{}
+
+// Verify checks if the signature of the payload is from the signer
+func (Ed25519Verifier) Verify(signer, payload, signature []byte) bool {
+	if len(signer) != ed25519.PublicKeySize {
+		return false
+	}
+
+	return ed25519.Verify(signer, payload, signature)
+}
+
+// ECDSASigner signs the SHA-256 hash of the messages with a secp256k1
+// private key. Its ID is the compressed secp256k1 public key.
+// The signatures are 64 bytes (r || s), with s in the lower half of the curve
+// order, so they can't be altered into another valid signature.
+// The curve arithmetic is delegated to the constant time decred
+// secp256k1 implementation, and the nonces are derived with RFC 6979
+type ECDSASigner struct {
+	key *secp256k1.PrivateKey
+}
+
+// GenerateECDSAKey generates a new secp256k1 private key
+func GenerateECDSAKey(random io.Reader) (*secp256k1.PrivateKey, error) {
+	return secp256k1.GeneratePrivateKeyFromRand(random)
+}
+
+// NewECDSASigner creates a new secp256k1 ECDSA signer
+func NewECDSASigner(key *secp256k1.PrivateKey) (*ECDSASigner, error) {
+	if key == nil || key.Key.IsZero() {
+		return nil, errNotSecp256k1
+	}
+
+	return &ECDSASigner{
+		key: key,
+	}, nil
+}
+
+// ID returns the compressed public key of the signer
+func (s *ECDSASigner) ID() []byte {
+	return s.key.PubKey().SerializeCompressed()
+}
+
+// Sign signs the SHA-256 hash of the payload
+func (s *ECDSASigner) Sign(payload []byte) ([]byte, error) {
+	hash := sha256.Sum256(payload)
+
+	// The produced signatures are already in the lower s form
+	var (
+		sig       = ecdsa.Sign(s.key, hash[:])
+		r         = sig.R()
+		sigS      = sig.S()
+		signature = make([]byte, ecdsaSignatureSize)
+	)
+
+	r.PutBytesUnchecked(signature[:ecdsaSignatureSize/2])
+	sigS.PutBytesUnchecked(signature[ecdsaSignatureSize/2:])
+
+	return signature, nil
+}
+
+// ECDSAVerifier verifies the secp256k1 ECDSA signatures,
+// where the signer is the compressed secp256k1 public key
+type ECDSAVerifier struct{}
+
+// Verify checks if the signature of the payload is from the signer
+func (ECDSAVerifier) Verify(signer, payload, signature []byte) bool {
+	if len(signature) != ecdsaSignatureSize || len(signer) != compressedKeySize {
+		return false
+	}
+
+	publicKey, err := secp256k1.ParsePubKey(signer)
+	if err != nil {
+		return false
+	}
+
+	var r, s secp256k1.ModNScalar
+
+	// Reject the values that are not reduced modulo the curve order
+	if r.SetByteSlice(signature[:ecdsaSignatureSize/2]) ||
+		s.SetByteSlice(signature[ecdsaSignatureSize/2:]) {
+		return false
+	}
+
+	// Reject the malleable (high s) signatures
+	if r.IsZero() || s.IsZero() || s.IsOverHalfOrder() {
+		return false
+	}
+
+	hash := sha256.Sum256(payload)
+
+	return ecdsa.NewSignature(&r, &s).Verify(hash[:], publicKey)
+}
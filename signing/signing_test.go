@@ -0,0 +1,141 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECDSASigner_ID(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name       string
+		key        int64
+		expectedID string
+	}{
+		{
+			"generator",
+			1,
+			"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		},
+		{
+			"double generator",
+			2,
+			"02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+		},
+		{
+			"triple generator",
+			3,
+			"02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			signer, err := NewECDSASigner(
+				secp256k1.PrivKeyFromBytes(big.NewInt(testCase.key).Bytes()),
+			)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.expectedID, hex.EncodeToString(signer.ID()))
+		})
+	}
+}
+
+func TestECDSA_SignVerify(t *testing.T) {
+	t.Parallel()
+
+	key, err := GenerateECDSAKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := NewECDSASigner(key)
+	require.NoError(t, err)
+
+	var (
+		verifier = ECDSAVerifier{}
+		payload  = []byte("payload")
+	)
+
+	signature, err := signer.Sign(payload)
+	require.NoError(t, err)
+	require.Len(t, signature, ecdsaSignatureSize)
+
+	// Make sure the signature is verified
+	assert.True(t, verifier.Verify(signer.ID(), payload, signature))
+
+	// Make sure the signature doesn't verify a different payload
+	assert.False(t, verifier.Verify(signer.ID(), []byte("other payload"), signature))
+
+	// Make sure the signature isn't from a different signer
+	otherKey, err := GenerateECDSAKey(rand.Reader)
+	require.NoError(t, err)
+
+	assert.False(t, verifier.Verify(otherKey.PubKey().SerializeCompressed(), payload, signature))
+
+	// Make sure the malleable (high s) form of the signature is rejected
+	var (
+		s         secp256k1.ModNScalar
+		malleable = make([]byte, ecdsaSignatureSize)
+	)
+
+	s.SetByteSlice(signature[32:])
+	s.Negate()
+
+	copy(malleable, signature[:32])
+	s.PutBytesUnchecked(malleable[32:])
+
+	assert.False(t, verifier.Verify(signer.ID(), payload, malleable))
+
+	// Make sure malformed input is rejected
+	assert.False(t, verifier.Verify(signer.ID(), payload, signature[1:]))
+	assert.False(t, verifier.Verify(signer.ID(), payload, make([]byte, ecdsaSignatureSize)))
+	assert.False(t, verifier.Verify([]byte("signer"), payload, signature))
+	assert.False(t, verifier.Verify(make([]byte, compressedKeySize), payload, signature))
+	assert.False(t, verifier.Verify(otherKey.PubKey().SerializeUncompressed(), payload, signature))
+}
+
+func TestECDSA_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewECDSASigner(nil)
+	assert.ErrorIs(t, err, errNotSecp256k1)
+
+	_, err = NewECDSASigner(&secp256k1.PrivateKey{})
+	assert.ErrorIs(t, err, errNotSecp256k1)
+}
+
+func TestEd25519_SignVerify(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := NewEd25519Signer(key)
+	require.NoError(t, err)
+
+	var (
+		verifier = Ed25519Verifier{}
+		payload  = []byte("payload")
+	)
+
+	signature, err := signer.Sign(payload)
+	require.NoError(t, err)
+
+	assert.True(t, verifier.Verify(signer.ID(), payload, signature))
+	assert.False(t, verifier.Verify(signer.ID(), []byte("other payload"), signature))
+	assert.False(t, verifier.Verify([]byte("signer"), payload, signature))
+
+	_, err = NewEd25519Signer(key[1:])
+	assert.ErrorIs(t, err, errInvalidEd25519)
+}
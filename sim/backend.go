@@ -0,0 +1,436 @@
+package sim
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/nubank/go-ibft/signing"
+)
+
+// Block is a finalized block of the simulated chain
+type Block struct {
+	// Proposal is the finalized proposal
+	Proposal []byte
+
+	// CommittedSeals are the seals finalizing the proposal
+	CommittedSeals []*messages.CommittedSeal
+}
+
+// Backend is the core.Backend of a simulated node. The node ID is its
+// Ed25519 public key, and the committed seal is its signature of the
+// proposal hash, which is the SHA-256 hash of the proposal. The validators
+// take turns proposing. The proposals start with the height ("block <height> ..."),
+// and a proposal is valid if it is for the height following the node's chain.
+//
+// The backend is also the height source of the node's consensus driver,
+// and syncs the finalized blocks from its peers when the node falls behind
+type Backend struct {
+	index      int
+	signer     *signing.Ed25519Signer
+	validators messages.ValidatorSet
+
+	// chain are the inserted blocks, by height (starting from 1)
+	chain     []Block
+	chainLock sync.RWMutex
+
+	// heightUpdates notifies the driver of the synced heights
+	heightUpdates chan uint64
+
+	// sync fetches the blocks up to the height from the peers
+	sync func(height uint64)
+}
+
+// newBackend creates a new backend of the validator
+func newBackend(index int, signer *signing.Ed25519Signer, validators messages.ValidatorSet) *Backend {
+	return &Backend{
+		index:         index,
+		signer:        signer,
+		validators:    validators,
+		heightUpdates: make(chan uint64, 1),
+	}
+}
+
+// nodeKey returns the private key of the node, derived from its index
+func nodeKey(index int) ed25519.PrivateKey {
+	seed := sha256.Sum256([]byte(fmt.Sprintf("node %d", index)))
+
+	return ed25519.NewKeyFromSeed(seed[:])
+}
+
+// ProposalHash returns the hash of the proposal
+func ProposalHash(proposal []byte) []byte {
+	hash := sha256.Sum256(proposal)
+
+	return hash[:]
+}
+
+// Proposer returns the index of the proposer for the view
+func Proposer(validators messages.ValidatorSet, height, round uint64) int {
+	return int((height + round) % uint64(len(validators.Validators())))
+}
+
+// BlockHeight returns the height of the proposal
+func BlockHeight(proposal []byte) (uint64, bool) {
+	var height uint64
+
+	if _, err := fmt.Sscanf(string(proposal), "block %d", &height); err != nil {
+		return 0, false
+	}
+
+	return height, true
+}
+
+// Chain returns the inserted blocks, by height
+func (b *Backend) Chain() []Block {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return append([]Block{}, b.chain...)
+}
+
+// LatestHeight returns the height of the latest inserted block
+func (b *Backend) LatestHeight() uint64 {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return uint64(len(b.chain))
+}
+
+// HeightUpdates returns the channel notified of the synced heights
+func (b *Backend) HeightUpdates() <-chan uint64 {
+	return b.heightUpdates
+}
+
+// Sync fetches the blocks up to the height from the peers
+func (b *Backend) Sync(height uint64, _ []*proto.Message) {
+	if b.sync != nil {
+		b.sync(height)
+	}
+}
+
+// appendBlocks appends the synced blocks following the node's chain,
+// as long as they are finalized by a quorum of valid committed seals
+func (b *Backend) appendBlocks(blocks []Block) {
+	b.chainLock.Lock()
+
+	synced := len(b.chain)
+
+	for _, block := range blocks {
+		height, ok := BlockHeight(block.Proposal)
+		if !ok || height != uint64(len(b.chain))+1 || !b.isFinalized(block) {
+			continue
+		}
+
+		b.chain = append(b.chain, block)
+	}
+
+	height := uint64(len(b.chain))
+
+	b.chainLock.Unlock()
+
+	if int(height) == synced {
+		return
+	}
+
+	// Keep only the latest height update
+	select {
+	case <-b.heightUpdates:
+	default:
+	}
+
+	b.heightUpdates <- height
+}
+
+// isFinalized checks if the block is sealed by a quorum of the validators
+func (b *Backend) isFinalized(block Block) bool {
+	var (
+		power   uint64
+		hash    = ProposalHash(block.Proposal)
+		signers = make(map[string]struct{})
+	)
+
+	for _, seal := range block.CommittedSeals {
+		if _, exists := signers[string(seal.Signer)]; exists || !b.IsValidCommittedSeal(hash, seal) {
+			continue
+		}
+
+		signers[string(seal.Signer)] = struct{}{}
+		power += b.validators.VotingPower(seal.Signer)
+	}
+
+	return power >= messages.QuorumVotingPower(b.validators)
+}
+
+// committedSeal returns the node's seal of the proposal hash
+func (b *Backend) committedSeal(proposalHash []byte) []byte {
+	seal, _ := b.signer.Sign(proposalHash)
+
+	return seal
+}
+
+// SignMessage signs the message with the node's key, replacing its signature
+func (b *Backend) SignMessage(message *proto.Message) {
+	payload, err := message.PayloadNoSig()
+	if err != nil {
+		return
+	}
+
+	message.Signature, _ = b.signer.Sign(payload)
+}
+
+func (b *Backend) ID() []byte {
+	return b.signer.ID()
+}
+
+func (b *Backend) ValidatorSet(_ uint64) messages.ValidatorSet {
+	return b.validators
+}
+
+func (b *Backend) Quorum(_ uint64) uint64 {
+	return messages.QuorumVotingPower(b.validators)
+}
+
+func (b *Backend) MaximumFaultyNodes() uint64 {
+	return messages.MaximumFaultyVotingPower(b.validators)
+}
+
+func (b *Backend) BuildProposal(height uint64) []byte {
+	return []byte(fmt.Sprintf("block %d proposed by node %d", height, b.index))
+}
+
+func (b *Backend) InsertBlock(proposal []byte, committedSeals []*messages.CommittedSeal) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	// The block may have been synced in the meantime
+	if height, _ := BlockHeight(proposal); height != uint64(len(b.chain))+1 {
+		return
+	}
+
+	b.chain = append(b.chain, Block{
+		Proposal:       proposal,
+		CommittedSeals: committedSeals,
+	})
+}
+
+func (b *Backend) IsValidBlock(block []byte) bool {
+	height, ok := BlockHeight(block)
+
+	return ok && height == b.LatestHeight()+1
+}
+
+func (b *Backend) IsValidSender(message *proto.Message) bool {
+	_, ok := b.validators.Index(message.From)
+
+	return ok
+}
+
+func (b *Backend) IsProposer(id []byte, height, round uint64) bool {
+	proposer := b.validators.Validators()[Proposer(b.validators, height, round)]
+
+	return bytes.Equal(id, proposer.ID)
+}
+
+func (b *Backend) IsValidProposalHash(proposal, hash []byte) bool {
+	return bytes.Equal(ProposalHash(proposal), hash)
+}
+
+func (b *Backend) IsValidCommittedSeal(proposalHash []byte, seal *messages.CommittedSeal) bool {
+	if _, ok := b.validators.Index(seal.Signer); !ok {
+		return false
+	}
+
+	return signing.Ed25519Verifier{}.Verify(seal.Signer, proposalHash, seal.Signature)
+}
+
+func (b *Backend) BuildPrePrepareMessage(
+	proposal []byte,
+	certificate *proto.RoundChangeCertificate,
+	view *proto.View,
+) *proto.Message {
+	return &proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_PREPREPARE,
+		Payload: &proto.Message_PreprepareData{
+			PreprepareData: &proto.PrePrepareMessage{
+				Proposal:     proposal,
+				ProposalHash: ProposalHash(proposal),
+				Certificate:  certificate,
+			},
+		},
+	}
+}
+
+func (b *Backend) BuildPrepareMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	return &proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_PREPARE,
+		Payload: &proto.Message_PrepareData{
+			PrepareData: &proto.PrepareMessage{
+				ProposalHash: proposalHash,
+			},
+		},
+	}
+}
+
+func (b *Backend) BuildCommitMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	return &proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_COMMIT,
+		Payload: &proto.Message_CommitData{
+			CommitData: &proto.CommitMessage{
+				ProposalHash:  proposalHash,
+				CommittedSeal: b.committedSeal(proposalHash),
+			},
+		},
+	}
+}
+
+func (b *Backend) BuildRoundChangeMessage(
+	proposal []byte,
+	certificate *proto.PreparedCertificate,
+	view *proto.View,
+) *proto.Message {
+	return &proto.Message{
+		View: view,
+		From: b.ID(),
+		Type: proto.MessageType_ROUND_CHANGE,
+		Payload: &proto.Message_RoundChangeData{
+			RoundChangeData: &proto.RoundChangeMessage{
+				LastPreparedProposedBlock: proposal,
+				LatestPreparedCertificate: certificate,
+			},
+		},
+	}
+}
+
+// sortRoundChangeCertificate returns a copy of the certificate with
+// the messages sorted by sender. The node collects them from its message
+// store in no particular order, which would make the proposals differ
+// between runs with the same seed
+func sortRoundChangeCertificate(certificate *proto.RoundChangeCertificate) *proto.RoundChangeCertificate {
+	if certificate == nil {
+		return nil
+	}
+
+	return &proto.RoundChangeCertificate{
+		RoundChangeMessages: sortBySender(certificate.RoundChangeMessages),
+	}
+}
+
+// sortPreparedCertificate returns a copy of the
+// certificate with the PREPARE messages sorted by sender
+func sortPreparedCertificate(certificate *proto.PreparedCertificate) *proto.PreparedCertificate {
+	if certificate == nil {
+		return nil
+	}
+
+	return &proto.PreparedCertificate{
+		ProposalMessage: certificate.ProposalMessage,
+		PrepareMessages: sortBySender(certificate.PrepareMessages),
+	}
+}
+
+// sortBySender returns a copy of the messages, sorted by sender
+func sortBySender(messages []*proto.Message) []*proto.Message {
+	sorted := append([]*proto.Message{}, messages...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].From, sorted[j].From) < 0
+	})
+
+	return sorted
+}
+
+// trackedBackend is the Backend handed to the node's IBFT instance. It records
+// the calls of the node, so the simulation can tell when the node stops reacting
+// to an event, and sorts the certificates the node builds its messages from.
+// The other methods (height source, sync...) are passed through
+type trackedBackend struct {
+	*Backend
+
+	activity *activity
+}
+
+func (b trackedBackend) BuildProposal(height uint64) []byte {
+	b.activity.touch()
+
+	return b.Backend.BuildProposal(height)
+}
+
+func (b trackedBackend) InsertBlock(proposal []byte, committedSeals []*messages.CommittedSeal) {
+	b.activity.touch()
+
+	b.Backend.InsertBlock(proposal, committedSeals)
+}
+
+func (b trackedBackend) IsValidBlock(block []byte) bool {
+	b.activity.touch()
+
+	return b.Backend.IsValidBlock(block)
+}
+
+func (b trackedBackend) IsValidSender(message *proto.Message) bool {
+	b.activity.touch()
+
+	return b.Backend.IsValidSender(message)
+}
+
+func (b trackedBackend) IsProposer(id []byte, height, round uint64) bool {
+	b.activity.touch()
+
+	return b.Backend.IsProposer(id, height, round)
+}
+
+func (b trackedBackend) IsValidProposalHash(proposal, hash []byte) bool {
+	b.activity.touch()
+
+	return b.Backend.IsValidProposalHash(proposal, hash)
+}
+
+func (b trackedBackend) IsValidCommittedSeal(proposalHash []byte, seal *messages.CommittedSeal) bool {
+	b.activity.touch()
+
+	return b.Backend.IsValidCommittedSeal(proposalHash, seal)
+}
+
+func (b trackedBackend) BuildPrePrepareMessage(
+	proposal []byte,
+	certificate *proto.RoundChangeCertificate,
+	view *proto.View,
+) *proto.Message {
+	b.activity.touch()
+
+	return b.Backend.BuildPrePrepareMessage(proposal, sortRoundChangeCertificate(certificate), view)
+}
+
+func (b trackedBackend) BuildPrepareMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	b.activity.touch()
+
+	return b.Backend.BuildPrepareMessage(proposalHash, view)
+}
+
+func (b trackedBackend) BuildCommitMessage(proposalHash []byte, view *proto.View) *proto.Message {
+	b.activity.touch()
+
+	return b.Backend.BuildCommitMessage(proposalHash, view)
+}
+
+func (b trackedBackend) BuildRoundChangeMessage(
+	proposal []byte,
+	certificate *proto.PreparedCertificate,
+	view *proto.View,
+) *proto.Message {
+	b.activity.touch()
+
+	return b.Backend.BuildRoundChangeMessage(proposal, sortPreparedCertificate(certificate), view)
+}
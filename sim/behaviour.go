@@ -0,0 +1,49 @@
+package sim
+
+import (
+	"github.com/nubank/go-ibft/messages/proto"
+)
+
+// Behaviour is the behaviour of a node on the network. It decides what is
+// sent out, and to whom, for each (signed) message the node multicasts.
+// The nodes without a behaviour are honest, and multicast the messages as is.
+//
+// The behaviour of a node may be called from multiple goroutines,
+// and a stateful behaviour should not be shared between nodes
+type Behaviour interface {
+	// Multicast sends out the message multicast by the node
+	Multicast(out *Outbox, message *proto.Message)
+}
+
+// Outbox sends the messages of a node over the simulated network
+type Outbox struct {
+	network *network
+	node    *Node
+	nodes   int
+}
+
+// Node returns the sending node
+func (o *Outbox) Node() *Node {
+	return o.node
+}
+
+// Nodes returns the number of nodes on the network
+func (o *Outbox) Nodes() int {
+	return o.nodes
+}
+
+// Send sends the message to the node with the index
+func (o *Outbox) Send(to int, message *proto.Message) {
+	if to < 0 || to >= o.nodes {
+		return
+	}
+
+	o.network.send(o.node.Index, to, message)
+}
+
+// Multicast sends the message to all the nodes, including the sending one
+func (o *Outbox) Multicast(message *proto.Message) {
+	for to := 0; to < o.nodes; to++ {
+		o.network.send(o.node.Index, to, message)
+	}
+}
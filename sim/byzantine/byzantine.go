@@ -0,0 +1,416 @@
+// Package byzantine provides malicious node behaviours for the simulated
+// network. The byzantine nodes run the honest consensus, and their behaviour
+// tampers with the messages they send out: it can equivocate, withhold, replay,
+// or forge the messages of other validators. A byzantine node signs with its
+// own key only, so the messages it forges on behalf of others are not validly signed.
+// The certificates built from the node's own messages, and the honest messages it
+// holds, are validly signed, and are invalid by their structure only
+package byzantine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/nubank/go-ibft/sim"
+)
+
+// ForgedTag is the tag of the forged proposals, which have a
+// forged certificate, and should never be finalized by honest nodes
+const ForgedTag = "forged"
+
+// EquivocatingProposer multicasts a second, conflicting
+// proposal along with each of the node's proposals
+type EquivocatingProposer struct{}
+
+func (EquivocatingProposer) Multicast(out *sim.Outbox, message *proto.Message) {
+	out.Multicast(message)
+
+	if message.Type != proto.MessageType_PREPREPARE {
+		return
+	}
+
+	out.Multicast(conflictingProposal(out.Node(), message, "equivocation"))
+}
+
+// SplitProposer sends a different proposal to each of the peers.
+// The node itself keeps the original proposal
+type SplitProposer struct{}
+
+func (SplitProposer) Multicast(out *sim.Outbox, message *proto.Message) {
+	if message.Type != proto.MessageType_PREPREPARE {
+		out.Multicast(message)
+
+		return
+	}
+
+	for to := 0; to < out.Nodes(); to++ {
+		if to == out.Node().Index {
+			out.Send(to, message)
+
+			continue
+		}
+
+		out.Send(to, conflictingProposal(out.Node(), message, fmt.Sprintf("for node %d", to)))
+	}
+}
+
+// InvalidPreparedCertificate replaces the prepared certificate of the node's
+// round changes with a forged one, which prepares a conflicting proposal of the
+// previous round. The proposal and the prepares claim to be from the other validators
+type InvalidPreparedCertificate struct{}
+
+func (InvalidPreparedCertificate) Multicast(out *sim.Outbox, message *proto.Message) {
+	if message.Type != proto.MessageType_ROUND_CHANGE || message.View.Round == 0 {
+		out.Multicast(message)
+
+		return
+	}
+
+	var (
+		node = out.Node()
+		view = &proto.View{
+			Height: message.View.Height,
+			Round:  message.View.Round - 1,
+		}
+
+		proposal = forgedProposal(node, view.Height)
+		hash     = sim.ProposalHash(proposal)
+	)
+
+	proposalMessage := node.Backend.BuildPrePrepareMessage(proposal, nil, view)
+	forge(node, proposalMessage, proposer(node, view))
+
+	certificate := &proto.PreparedCertificate{
+		ProposalMessage: proposalMessage,
+	}
+
+	for _, validator := range node.Backend.ValidatorSet(view.Height).Validators() {
+		if string(validator.ID) == string(proposalMessage.From) {
+			continue
+		}
+
+		prepare := node.Backend.BuildPrepareMessage(hash, view)
+		forge(node, prepare, validator.ID)
+
+		certificate.PrepareMessages = append(certificate.PrepareMessages, prepare)
+	}
+
+	roundChange := node.Backend.BuildRoundChangeMessage(proposal, certificate, message.View)
+	node.Backend.SignMessage(roundChange)
+
+	out.Multicast(roundChange)
+}
+
+// ForgedRoundChangeCertificate replaces the node's proposals for
+// rounds above 0 with a conflicting proposal, justified by a round change
+// certificate with the forged round changes of all the validators
+type ForgedRoundChangeCertificate struct{}
+
+func (ForgedRoundChangeCertificate) Multicast(out *sim.Outbox, message *proto.Message) {
+	if message.Type != proto.MessageType_PREPREPARE || message.View.Round == 0 {
+		out.Multicast(message)
+
+		return
+	}
+
+	var (
+		node        = out.Node()
+		certificate = &proto.RoundChangeCertificate{}
+	)
+
+	for _, validator := range node.Backend.ValidatorSet(message.View.Height).Validators() {
+		roundChange := node.Backend.BuildRoundChangeMessage(nil, nil, message.View)
+		forge(node, roundChange, validator.ID)
+
+		certificate.RoundChangeMessages = append(certificate.RoundChangeMessages, roundChange)
+	}
+
+	proposal := node.Backend.BuildPrePrepareMessage(
+		forgedProposal(node, message.View.Height),
+		certificate,
+		message.View,
+	)
+	node.Backend.SignMessage(proposal)
+
+	out.Multicast(proposal)
+}
+
+// SelfPreparedCertificate replaces the prepared certificate of the node's
+// round changes with one built from the node's own messages only: a conflicting
+// proposal of the previous round, and a quorum of prepares for it
+type SelfPreparedCertificate struct{}
+
+func (SelfPreparedCertificate) Multicast(out *sim.Outbox, message *proto.Message) {
+	if message.Type != proto.MessageType_ROUND_CHANGE || message.View.Round == 0 {
+		out.Multicast(message)
+
+		return
+	}
+
+	out.Multicast(selfPreparedRoundChange(out.Node(), message))
+}
+
+// MismatchedPreparedCertificate replaces the prepared certificate of the
+// node's round changes with the node's own conflicting proposal of the previous
+// round, along with the honest prepares the node holds for other proposals
+type MismatchedPreparedCertificate struct{}
+
+func (MismatchedPreparedCertificate) Multicast(out *sim.Outbox, message *proto.Message) {
+	if message.Type != proto.MessageType_ROUND_CHANGE || message.View.Round == 0 {
+		out.Multicast(message)
+
+		return
+	}
+
+	node := out.Node()
+
+	roundChange, ok := mismatchedRoundChange(node, message, node.IBFT.HeldMessages(message.View.Height))
+	if !ok {
+		out.Multicast(message)
+
+		return
+	}
+
+	out.Multicast(roundChange)
+}
+
+// ReusedRoundChangeCertificate replaces the node's proposals for rounds above 0
+// with a conflicting proposal, justified by the honest round changes the node
+// holds for the lower rounds, along with the node's own round change
+type ReusedRoundChangeCertificate struct{}
+
+func (ReusedRoundChangeCertificate) Multicast(out *sim.Outbox, message *proto.Message) {
+	if message.Type != proto.MessageType_PREPREPARE || message.View.Round == 0 {
+		out.Multicast(message)
+
+		return
+	}
+
+	node := out.Node()
+
+	proposal, ok := reusedProposal(node, message, node.IBFT.HeldMessages(message.View.Height))
+	if !ok {
+		out.Multicast(message)
+
+		return
+	}
+
+	out.Multicast(proposal)
+}
+
+// Replay re-multicasts all the messages the node sent
+// for the previous heights, each time it moves to a new height
+type Replay struct {
+	sent   []*proto.Message
+	height uint64
+	lock   sync.Mutex
+}
+
+func (r *Replay) Multicast(out *sim.Outbox, message *proto.Message) {
+	r.lock.Lock()
+
+	var replayed []*proto.Message
+	if message.View.Height > r.height {
+		r.height = message.View.Height
+		replayed = append(replayed, r.sent...)
+	}
+
+	r.sent = append(r.sent, message)
+
+	r.lock.Unlock()
+
+	for _, old := range replayed {
+		out.Multicast(old)
+	}
+
+	out.Multicast(message)
+}
+
+// WithholdCommits keeps the node's commits to itself
+type WithholdCommits struct{}
+
+func (WithholdCommits) Multicast(out *sim.Outbox, message *proto.Message) {
+	if message.Type != proto.MessageType_COMMIT {
+		out.Multicast(message)
+
+		return
+	}
+
+	out.Send(out.Node().Index, message)
+}
+
+// conflictingProposal returns the signed proposal message of the node,
+// for a proposal conflicting with the one of the message
+func conflictingProposal(node *sim.Node, message *proto.Message, tag string) *proto.Message {
+	proposal := node.Backend.BuildPrePrepareMessage(
+		[]byte(fmt.Sprintf("%s (%s)", messages.ExtractProposal(message), tag)),
+		messages.ExtractRoundChangeCertificate(message),
+		message.View,
+	)
+	node.Backend.SignMessage(proposal)
+
+	return proposal
+}
+
+// forgedProposal returns the proposal of the node with a forged certificate
+func forgedProposal(node *sim.Node, height uint64) []byte {
+	return []byte(fmt.Sprintf("%s (%s)", node.Backend.BuildProposal(height), ForgedTag))
+}
+
+// forge sets the sender of the message. The node signs it
+// with its own key, as it cannot sign on behalf of the sender
+func forge(node *sim.Node, message *proto.Message, from []byte) {
+	message.From = from
+	node.Backend.SignMessage(message)
+}
+
+// proposer returns the ID of the proposer for the view
+func proposer(node *sim.Node, view *proto.View) []byte {
+	validators := node.Backend.ValidatorSet(view.Height)
+
+	return validators.Validators()[sim.Proposer(validators, view.Height, view.Round)].ID
+}
+
+// selfPreparedRoundChange returns the signed round change of the node for the
+// round change message view, with a conflicting proposal of the previous round
+// prepared by a quorum of the node's own prepares
+func selfPreparedRoundChange(node *sim.Node, message *proto.Message) *proto.Message {
+	var (
+		view = &proto.View{
+			Height: message.View.Height,
+			Round:  message.View.Round - 1,
+		}
+
+		proposal = forgedProposal(node, view.Height)
+		hash     = sim.ProposalHash(proposal)
+	)
+
+	proposalMessage := node.Backend.BuildPrePrepareMessage(proposal, nil, view)
+	node.Backend.SignMessage(proposalMessage)
+
+	certificate := &proto.PreparedCertificate{
+		ProposalMessage: proposalMessage,
+	}
+
+	for count := uint64(1); count < node.Backend.Quorum(view.Height); count++ {
+		prepare := node.Backend.BuildPrepareMessage(hash, view)
+		node.Backend.SignMessage(prepare)
+
+		certificate.PrepareMessages = append(certificate.PrepareMessages, prepare)
+	}
+
+	roundChange := node.Backend.BuildRoundChangeMessage(proposal, certificate, message.View)
+	node.Backend.SignMessage(roundChange)
+
+	return roundChange
+}
+
+// mismatchedRoundChange returns the signed round change of the node for the
+// round change message view, with a conflicting proposal of the previous round
+// prepared by the held prepares of the other nodes. It fails if there are none
+func mismatchedRoundChange(
+	node *sim.Node,
+	message *proto.Message,
+	held []*proto.Message,
+) (*proto.Message, bool) {
+	prepares := latestBySender(node, held, proto.MessageType_PREPARE, message.View.Round)
+	if len(prepares) == 0 {
+		return nil, false
+	}
+
+	view := &proto.View{
+		Height: message.View.Height,
+		Round:  message.View.Round - 1,
+	}
+
+	proposal := forgedProposal(node, view.Height)
+
+	proposalMessage := node.Backend.BuildPrePrepareMessage(proposal, nil, view)
+	node.Backend.SignMessage(proposalMessage)
+
+	certificate := &proto.PreparedCertificate{
+		ProposalMessage: proposalMessage,
+		PrepareMessages: prepares,
+	}
+
+	roundChange := node.Backend.BuildRoundChangeMessage(proposal, certificate, message.View)
+	node.Backend.SignMessage(roundChange)
+
+	return roundChange, true
+}
+
+// reusedProposal returns the signed conflicting proposal of the node for the
+// proposal message view, justified by the held round changes of the other
+// nodes for the lower rounds. It fails if they don't make up a quorum
+func reusedProposal(
+	node *sim.Node,
+	message *proto.Message,
+	held []*proto.Message,
+) (*proto.Message, bool) {
+	roundChange := node.Backend.BuildRoundChangeMessage(nil, nil, message.View)
+	node.Backend.SignMessage(roundChange)
+
+	certificate := &proto.RoundChangeCertificate{
+		RoundChangeMessages: append(
+			latestBySender(node, held, proto.MessageType_ROUND_CHANGE, message.View.Round),
+			roundChange,
+		),
+	}
+
+	if uint64(len(certificate.RoundChangeMessages)) < node.Backend.Quorum(message.View.Height) {
+		return nil, false
+	}
+
+	proposal := node.Backend.BuildPrePrepareMessage(
+		forgedProposal(node, message.View.Height),
+		certificate,
+		message.View,
+	)
+	node.Backend.SignMessage(proposal)
+
+	return proposal, true
+}
+
+// latestBySender returns the latest held message of the type
+// from each of the other nodes, for the rounds below the round
+func latestBySender(
+	node *sim.Node,
+	held []*proto.Message,
+	messageType proto.MessageType,
+	round uint64,
+) []*proto.Message {
+	var (
+		latest  = make(map[string]*proto.Message)
+		senders = make([]string, 0)
+	)
+
+	for _, message := range held {
+		if message.Type != messageType || message.View.Round >= round {
+			continue
+		}
+
+		sender := string(message.From)
+		if sender == string(node.ID) {
+			continue
+		}
+
+		previous, exists := latest[sender]
+		if !exists {
+			senders = append(senders, sender)
+		}
+
+		if !exists || message.View.Round > previous.View.Round {
+			latest[sender] = message
+		}
+	}
+
+	// Keep the order of the held messages, so the certificates are reproducible
+	found := make([]*proto.Message, 0, len(senders))
+	for _, sender := range senders {
+		found = append(found, latest[sender])
+	}
+
+	return found
+}
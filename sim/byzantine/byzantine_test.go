@@ -0,0 +1,327 @@
+package byzantine
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/nubank/go-ibft/signing"
+	"github.com/nubank/go-ibft/sim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertSafety checks that the nodes finalized the same
+// blocks, and none of the forged proposals
+func assertSafety(t *testing.T, s *sim.Simulation) {
+	t.Helper()
+
+	require.NoError(t, s.CheckAgreement())
+
+	for _, node := range s.Nodes() {
+		for _, block := range node.Backend.Chain() {
+			assert.NotContains(t, string(block.Proposal), ForgedTag)
+		}
+	}
+}
+
+func TestBehaviours_SingleByzantineNode(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name      string
+		behaviour func() sim.Behaviour
+	}{
+		{
+			"equivocating proposer",
+			func() sim.Behaviour { return EquivocatingProposer{} },
+		},
+		{
+			"split proposer",
+			func() sim.Behaviour { return SplitProposer{} },
+		},
+		{
+			"invalid prepared certificate",
+			func() sim.Behaviour { return InvalidPreparedCertificate{} },
+		},
+		{
+			"forged round change certificate",
+			func() sim.Behaviour { return ForgedRoundChangeCertificate{} },
+		},
+		{
+			"self prepared certificate",
+			func() sim.Behaviour { return SelfPreparedCertificate{} },
+		},
+		{
+			"mismatched prepared certificate",
+			func() sim.Behaviour { return MismatchedPreparedCertificate{} },
+		},
+		{
+			"reused round change certificate",
+			func() sim.Behaviour { return ReusedRoundChangeCertificate{} },
+		},
+		{
+			"replay",
+			func() sim.Behaviour { return &Replay{} },
+		},
+		{
+			"withhold commits",
+			func() sim.Behaviour { return WithholdCommits{} },
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The byzantine node is the proposer for the round 1 of the first height,
+			// and the proposer for the round 0 is isolated for a while,
+			// so the nodes go through a round change
+			s := sim.New(sim.Config{
+				Nodes:        4,
+				Link:         sim.LinkConfig{Latency: 10 * time.Millisecond},
+				RoundTimeout: time.Second,
+				Behaviours: map[int]sim.Behaviour{
+					2: testCase.behaviour(),
+				},
+			})
+
+			require.Equal(t, 2, s.Proposer(1, 1).Index)
+
+			s.Partition([]int{0, 2, 3})
+			s.Start()
+
+			defer s.Stop()
+
+			s.RunFor(10 * time.Second)
+			assertSafety(t, s)
+
+			s.Heal()
+
+			require.NoError(t, s.RunUntilHeight(5))
+			assertSafety(t, s)
+		})
+	}
+}
+
+func TestBehaviours_UnreliableNetwork(t *testing.T) {
+	t.Parallel()
+
+	for _, seed := range []int64{1, 2, 3} {
+		seed := seed
+
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			t.Parallel()
+
+			// Two byzantine nodes out of seven, splitting their proposals and
+			// forging the round change certificates, over a network that
+			// delays, duplicates and reorders the messages
+			s := sim.New(sim.Config{
+				Nodes: 7,
+				Seed:  seed,
+				Link: sim.LinkConfig{
+					Latency:       20 * time.Millisecond,
+					Jitter:        80 * time.Millisecond,
+					DuplicateRate: 0.1,
+					ReorderRate:   0.2,
+					ReorderDelay:  time.Second,
+				},
+				RoundTimeout: time.Second,
+				Behaviours: map[int]sim.Behaviour{
+					1: SplitProposer{},
+					4: ForgedRoundChangeCertificate{},
+				},
+			})
+
+			s.Start()
+
+			defer s.Stop()
+
+			require.NoError(t, s.RunUntilHeight(5))
+			assertSafety(t, s)
+		})
+	}
+}
+
+// TestBehaviours_ReusedRoundChanges makes sure the round changes of the lower
+// rounds can't justify a conflicting proposal. The proposers for the rounds 0
+// and 1 are isolated, so the byzantine proposer for the round 2 holds the
+// honest round changes for the round 1
+func TestBehaviours_ReusedRoundChanges(t *testing.T) {
+	t.Parallel()
+
+	s := sim.New(sim.Config{
+		Nodes:        7,
+		Link:         sim.LinkConfig{Latency: 10 * time.Millisecond},
+		RoundTimeout: time.Second,
+		Behaviours: map[int]sim.Behaviour{
+			3: ReusedRoundChangeCertificate{},
+		},
+	})
+
+	require.Equal(t, 3, s.Proposer(1, 2).Index)
+
+	s.Partition([]int{0, 3, 4, 5, 6})
+	s.Start()
+
+	defer s.Stop()
+
+	// Make sure the rest of the network finalized
+	// the first height, without the forged proposal
+	s.RunFor(10 * time.Second)
+	require.NotZero(t, s.Nodes()[0].Backend.LatestHeight())
+	assertSafety(t, s)
+
+	s.Heal()
+
+	require.NoError(t, s.RunUntilHeight(s.Nodes()[0].Backend.LatestHeight()+1))
+	assertSafety(t, s)
+}
+
+// TestValidlySignedCertificates makes sure the certificates built from the
+// node's own messages, and the honest messages it holds, are validly signed,
+// so they can only be rejected by their structure
+func TestValidlySignedCertificates(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s         = sim.New(sim.Config{Nodes: 4})
+		byzantine = s.Nodes()[0]
+		honest    = s.Nodes()[1:]
+		view      = &proto.View{Height: 3, Round: 2}
+		lower     = &proto.View{Height: 3, Round: 1}
+	)
+
+	// isSigned checks if the message is signed by its sender
+	isSigned := func(message *proto.Message) bool {
+		payload, err := message.PayloadNoSig()
+		require.NoError(t, err)
+
+		return signing.Ed25519Verifier{}.Verify(message.From, payload, message.Signature)
+	}
+
+	// assertSigned makes sure all the messages are signed by their senders
+	assertSigned := func(t *testing.T, msgs ...*proto.Message) {
+		t.Helper()
+
+		for _, message := range msgs {
+			assert.True(t, isSigned(message))
+		}
+	}
+
+	// The honest messages the byzantine node holds for the lower round
+	var held []*proto.Message
+
+	for _, node := range honest {
+		prepare := node.Backend.BuildPrepareMessage([]byte("honest hash"), lower)
+		node.Backend.SignMessage(prepare)
+
+		roundChange := node.Backend.BuildRoundChangeMessage(nil, nil, lower)
+		node.Backend.SignMessage(roundChange)
+
+		held = append(held, prepare, roundChange)
+	}
+
+	ownRoundChange := byzantine.Backend.BuildRoundChangeMessage(nil, nil, view)
+	byzantine.Backend.SignMessage(ownRoundChange)
+
+	t.Run("self prepared certificate", func(t *testing.T) {
+		t.Parallel()
+
+		roundChange := selfPreparedRoundChange(byzantine, ownRoundChange)
+		certificate := messages.ExtractLatestPC(roundChange)
+
+		assertSigned(t, append([]*proto.Message{roundChange, certificate.ProposalMessage}, certificate.PrepareMessages...)...)
+
+		// Make sure the certificate is from the node only
+		assert.Len(t, certificate.PrepareMessages, int(byzantine.Backend.Quorum(view.Height))-1)
+		assert.False(t, messages.HasUniqueSenders(
+			append([]*proto.Message{certificate.ProposalMessage}, certificate.PrepareMessages...),
+		))
+	})
+
+	t.Run("mismatched prepared certificate", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := mismatchedRoundChange(byzantine, ownRoundChange, nil)
+		assert.False(t, ok)
+
+		roundChange, ok := mismatchedRoundChange(byzantine, ownRoundChange, held)
+		require.True(t, ok)
+
+		certificate := messages.ExtractLatestPC(roundChange)
+		allMessages := append([]*proto.Message{certificate.ProposalMessage}, certificate.PrepareMessages...)
+
+		assertSigned(t, append(allMessages, roundChange)...)
+
+		// Make sure the prepares are the honest ones, for another proposal
+		assert.Len(t, certificate.PrepareMessages, len(honest))
+		assert.True(t, messages.HasUniqueSenders(allMessages))
+		assert.False(t, messages.HaveSameProposalHash(allMessages))
+	})
+
+	t.Run("reused round change certificate", func(t *testing.T) {
+		t.Parallel()
+
+		original := byzantine.Backend.BuildPrePrepareMessage(byzantine.Backend.BuildProposal(3), nil, view)
+
+		_, ok := reusedProposal(byzantine, original, held[:2])
+		assert.False(t, ok)
+
+		proposal, ok := reusedProposal(byzantine, original, held)
+		require.True(t, ok)
+
+		assert.Contains(t, string(messages.ExtractProposal(proposal)), ForgedTag)
+
+		roundChanges := messages.ExtractRoundChangeCertificate(proposal).RoundChangeMessages
+
+		assertSigned(t, append(roundChanges, proposal)...)
+
+		// Make sure the honest round changes are reused from the lower round
+		assert.Len(t, roundChanges, len(honest)+1)
+		assert.True(t, messages.HasUniqueSenders(roundChanges))
+		assert.Equal(t, lower, roundChanges[0].View)
+	})
+}
+
+func TestForge(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s         = sim.New(sim.Config{Nodes: 4})
+		byzantine = s.Nodes()[0]
+		honest    = s.Nodes()[1]
+		view      = &proto.View{Height: 3, Round: 1}
+	)
+
+	// isSigned checks if the message is signed by its sender
+	isSigned := func(message *proto.Message) bool {
+		payload, err := message.PayloadNoSig()
+		require.NoError(t, err)
+
+		return signing.Ed25519Verifier{}.Verify(message.From, payload, message.Signature)
+	}
+
+	// Make sure the conflicting proposals are
+	// signed by the node, and valid blocks for the height
+	original := byzantine.Backend.BuildPrePrepareMessage(byzantine.Backend.BuildProposal(3), nil, view)
+	conflicting := conflictingProposal(byzantine, original, "equivocation")
+
+	assert.True(t, isSigned(conflicting))
+	assert.NotEqual(t, messages.ExtractProposal(original), messages.ExtractProposal(conflicting))
+
+	height, ok := sim.BlockHeight(messages.ExtractProposal(conflicting))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), height)
+
+	// Make sure the messages forged on behalf of others are not validly signed
+	prepare := byzantine.Backend.BuildPrepareMessage([]byte("hash"), view)
+	forge(byzantine, prepare, honest.ID)
+
+	assert.Equal(t, honest.ID, prepare.From)
+	assert.False(t, isSigned(prepare))
+}
@@ -0,0 +1,280 @@
+package sim
+
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nubank/go-ibft/core"
+)
+
+// epoch is the virtual time the simulation starts at
+var epoch = time.Unix(0, 0).UTC()
+
+// event is a scheduled action of the simulation
+type event struct {
+	// at is the virtual time of the event
+	at time.Time
+
+	// key orders the events scheduled for the same time
+	key uint64
+
+	// seq orders the events with the same time and key
+	seq uint64
+
+	// timer is set if the event fires a timer
+	timer bool
+
+	fire  func()
+	index int
+}
+
+// eventQueue is the priority queue of the events, by time
+type eventQueue []*event
+
+func (q eventQueue) Len() int {
+	return len(q)
+}
+
+func (q eventQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+
+	if q[i].key != q[j].key {
+		return q[i].key < q[j].key
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q eventQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *eventQueue) Push(x interface{}) {
+	e, _ := x.(*event)
+	e.index = len(*q)
+
+	*q = append(*q, e)
+}
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+
+	old[len(old)-1] = nil
+	e.index = -1
+	*q = old[:len(old)-1]
+
+	return e
+}
+
+// activity counts the calls of the nodes into the simulation (clock,
+// transport and backend), to detect when they stop reacting to an event
+type activity struct {
+	count uint64
+}
+
+// touch records a call of a node
+func (a *activity) touch() {
+	atomic.AddUint64(&a.count, 1)
+}
+
+// load returns the number of calls recorded so far
+func (a *activity) load() uint64 {
+	return atomic.LoadUint64(&a.count)
+}
+
+// Clock is the virtual clock of the simulation, shared by all nodes.
+// The time only moves forward when the simulation runs the next event
+type Clock struct {
+	now   time.Time
+	queue eventQueue
+	seq   uint64
+
+	// activity records the timers created and stopped by the nodes
+	activity *activity
+
+	lock sync.Mutex
+}
+
+// newClock creates a new virtual clock at the epoch
+func newClock() *Clock {
+	return &Clock{
+		now:      epoch,
+		activity: &activity{},
+	}
+}
+
+// Now returns the current virtual time
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+// NewTimer creates a new timer that fires once
+// the virtual time advances by the duration
+func (c *Clock) NewTimer(d time.Duration) core.Timer {
+	return c.newTimer(d, 0)
+}
+
+// After waits for the virtual time to advance by the duration,
+// and then sends the current virtual time on the returned channel
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// newTimer creates a new timer, ordered by the key among
+// the events scheduled for the same time
+func (c *Clock) newTimer(d time.Duration, key uint64) *timer {
+	timer := &timer{
+		clock: c,
+		ch:    make(chan time.Time, 1),
+	}
+
+	c.activity.touch()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if d <= 0 {
+		timer.ch <- c.now
+
+		return timer
+	}
+
+	timer.event = c.scheduleLocked(c.now.Add(d), key, true, func() {
+		timer.ch <- c.Now()
+	})
+
+	return timer
+}
+
+// schedule schedules the action after the delay
+func (c *Clock) schedule(delay time.Duration, key uint64, fire func()) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.scheduleLocked(c.now.Add(delay), key, false, fire)
+}
+
+// scheduleLocked schedules the action at the time.
+// It should be called with the lock held
+func (c *Clock) scheduleLocked(at time.Time, key uint64, timer bool, fire func()) *event {
+	c.seq++
+
+	e := &event{
+		at:    at,
+		key:   key,
+		seq:   c.seq,
+		timer: timer,
+		fire:  fire,
+	}
+
+	heap.Push(&c.queue, e)
+
+	return e
+}
+
+// cancel removes the event from the queue, if it is still scheduled
+func (c *Clock) cancel(e *event) bool {
+	c.activity.touch()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e.index < 0 {
+		return false
+	}
+
+	heap.Remove(&c.queue, e.index)
+
+	return true
+}
+
+// peek returns the next event, if any
+func (c *Clock) peek() (*event, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.queue) == 0 {
+		return nil, false
+	}
+
+	return c.queue[0], true
+}
+
+// advance moves the virtual time forward to the time, if it is later
+func (c *Clock) advance(to time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if to.After(c.now) {
+		c.now = to
+	}
+}
+
+// next moves the virtual time to the next event, and removes it from
+// the queue. It returns false if there is no event up to the deadline
+func (c *Clock) next(deadline time.Time) (*event, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.queue) == 0 || c.queue[0].at.After(deadline) {
+		return nil, false
+	}
+
+	e, _ := heap.Pop(&c.queue).(*event)
+	c.now = e.at
+
+	return e, true
+}
+
+// nodeClock is the core.Clock of a node. The timers of the node are
+// ordered by its index among the events scheduled for the same time,
+// so the order doesn't depend on when the nodes created them
+type nodeClock struct {
+	*Clock
+
+	index int
+}
+
+func (c nodeClock) Now() time.Time {
+	c.activity.touch()
+
+	return c.Clock.Now()
+}
+
+func (c nodeClock) NewTimer(d time.Duration) core.Timer {
+	return c.newTimer(d, uint64(c.index))
+}
+
+func (c nodeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// timer is the timer of the virtual clock
+type timer struct {
+	clock *Clock
+	event *event
+	ch    chan time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *timer) Stop() bool {
+	if t.event == nil {
+		// Timers with no duration fire on creation
+		return false
+	}
+
+	return t.clock.cancel(t.event)
+}
@@ -0,0 +1,195 @@
+package sim
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// LinkConfig is the behaviour of the directed link between two nodes
+type LinkConfig struct {
+	// Latency is the base delivery delay of the messages
+	Latency time.Duration
+
+	// Jitter is the maximum random delay added to the latency.
+	// Messages sent close together may be delivered out of order
+	Jitter time.Duration
+
+	// DropRate is the probability a message is lost
+	DropRate float64
+
+	// DuplicateRate is the probability a message is delivered twice
+	DuplicateRate float64
+
+	// ReorderRate is the probability a message is held
+	// back for an additional delay of up to ReorderDelay
+	ReorderRate  float64
+	ReorderDelay time.Duration
+}
+
+// link is the directed link between two nodes
+type link struct {
+	from, to int
+}
+
+// network routes the messages between the nodes, over the simulated links
+type network struct {
+	seed  int64
+	clock *Clock
+
+	// deliver hands the message to the node
+	deliver func(to int, message *proto.Message)
+
+	defaultLink LinkConfig
+	links       map[link]LinkConfig
+
+	// partition maps the nodes to their partition group.
+	// Nodes in different groups cannot communicate
+	partition map[int]int
+
+	// sent and dropped are the message counters
+	sent, dropped, duplicated uint64
+
+	lock sync.RWMutex
+}
+
+// linkConfig returns the configuration of the link, and
+// whether the nodes can communicate over it
+func (n *network) linkConfig(from, to int) (LinkConfig, bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if n.partition != nil && n.partition[from] != n.partition[to] {
+		return LinkConfig{}, false
+	}
+
+	if config, ok := n.links[link{from, to}]; ok {
+		return config, true
+	}
+
+	return n.defaultLink, true
+}
+
+// send sends the message from the node to the recipient
+func (n *network) send(from, to int, message *proto.Message) {
+	n.countSent()
+
+	if from == to {
+		// Loopback messages are delivered instantly
+		n.schedule(0, to, message)
+
+		return
+	}
+
+	config, connected := n.linkConfig(from, to)
+	if !connected {
+		n.countDropped()
+
+		return
+	}
+
+	// The decisions are derived from the message and the link (rather than
+	// drawn in order), so they don't depend on the order in which
+	// the nodes happen to send their messages
+	rng := rand.New(rand.NewSource(n.messageSeed(from, to, message)))
+
+	if rng.Float64() < config.DropRate {
+		n.countDropped()
+
+		return
+	}
+
+	n.schedule(config.delay(rng), to, message)
+
+	if rng.Float64() < config.DuplicateRate {
+		n.countDuplicated()
+		n.schedule(config.delay(rng), to, message)
+	}
+}
+
+// delay returns the random delivery delay of a message over the link
+func (c LinkConfig) delay(rng *rand.Rand) time.Duration {
+	delay := c.Latency
+
+	if c.Jitter > 0 {
+		delay += time.Duration(rng.Int63n(int64(c.Jitter) + 1))
+	}
+
+	if c.ReorderDelay > 0 && rng.Float64() < c.ReorderRate {
+		delay += time.Duration(rng.Int63n(int64(c.ReorderDelay) + 1))
+	}
+
+	return delay
+}
+
+// schedule schedules the delivery of the message after the delay
+func (n *network) schedule(delay time.Duration, to int, message *proto.Message) {
+	// Each recipient gets its own copy, so it can't affect the others
+	message, _ = protobuf.Clone(message).(*proto.Message)
+
+	n.clock.schedule(delay, n.messageKey(to, message), func() {
+		n.deliver(to, message)
+	})
+}
+
+// messageSeed returns the seed of the random decisions for the message sent over the link
+func (n *network) messageSeed(from, to int, message *proto.Message) int64 {
+	hash := fnv.New64a()
+
+	var buf [8]byte
+
+	for _, value := range []uint64{uint64(n.seed), uint64(from), uint64(to)} {
+		binary.BigEndian.PutUint64(buf[:], value)
+		_, _ = hash.Write(buf[:])
+	}
+
+	_, _ = hash.Write(encodeMessage(message))
+
+	return int64(hash.Sum64())
+}
+
+// messageKey returns the key ordering the deliveries scheduled for the same time
+func (n *network) messageKey(to int, message *proto.Message) uint64 {
+	hash := fnv.New64a()
+
+	var buf [8]byte
+
+	binary.BigEndian.PutUint64(buf[:], uint64(to))
+	_, _ = hash.Write(buf[:])
+	_, _ = hash.Write(encodeMessage(message))
+
+	return hash.Sum64()
+}
+
+// encodeMessage returns the deterministic encoding of the message
+func encodeMessage(message *proto.Message) []byte {
+	encoded, _ := protobuf.MarshalOptions{Deterministic: true}.Marshal(message)
+
+	return encoded
+}
+
+func (n *network) countSent() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.sent++
+}
+
+func (n *network) countDropped() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.dropped++
+}
+
+func (n *network) countDuplicated() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.duplicated++
+}
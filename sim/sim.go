@@ -0,0 +1,471 @@
+// Package sim runs IBFT nodes in process, against a simulated network with
+// virtual time. The links between the nodes can delay, drop, duplicate and
+// reorder the messages, and the network can be partitioned. The nodes run
+// the consensus driver, and sync the finalized blocks from their peers
+// when they fall behind. The nodes sign their messages, and can be given
+// a (malicious) Behaviour deciding what they send out.
+//
+// The events (deliveries, timers...) are run one at a time, and the next one
+// is only run once the nodes appear to be done reacting to the previous one,
+// which is when they stop calling into the simulation (clock, transport and
+// backend) for a short real time window. Round timeouts of minutes are
+// simulated in milliseconds. The network decisions (drops, delays...) are
+// derived from the seed and the message itself, and the events scheduled
+// for the same time are run in an order derived from their content rather
+// than from when they were scheduled.
+//
+// The nodes don't report when they are idle, so the settle window is a
+// heuristic: a node that reacts slower than the window (ex. on a loaded
+// machine) does so after the next event. Runs with the same seed then
+// diverge, so the traces of runs are not guaranteed to be identical
+package sim
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/nubank/go-ibft/core"
+	"github.com/nubank/go-ibft/messages"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/nubank/go-ibft/signing"
+)
+
+var (
+	// ErrTimeLimit is returned when the nodes do not reach
+	// the height within the virtual time limit
+	ErrTimeLimit = errors.New("height not reached within the time limit")
+
+	// ErrConflictingBlocks is returned when the nodes
+	// finalized different blocks at the same height
+	ErrConflictingBlocks = errors.New("conflicting blocks finalized")
+)
+
+const (
+	// DefaultTimeLimit is the default virtual time limit of reaching a height
+	DefaultTimeLimit = 10 * time.Minute
+
+	// settleDelay is the real time the nodes are given to react to an event.
+	// A node that takes longer to react than this is not waited for
+	settleDelay = 200 * time.Microsecond
+
+	// timerSettleDelay is the real time the nodes are given to react,
+	// before the virtual time jumps ahead to the next timer
+	timerSettleDelay = 2 * time.Millisecond
+)
+
+// Config is the configuration of the simulation
+type Config struct {
+	// Nodes is the number of nodes, all of them validators
+	Nodes int
+
+	// Seed is the seed of the random network decisions
+	Seed int64
+
+	// Link is the default configuration of the links between the nodes
+	Link LinkConfig
+
+	// RoundTimeout is the base round timeout
+	// of the nodes (the core default if not set)
+	RoundTimeout time.Duration
+
+	// TimeLimit is the virtual time limit of reaching
+	// a height (DefaultTimeLimit if not set)
+	TimeLimit time.Duration
+
+	// Options are the additional options of the nodes
+	Options []core.Option
+
+	// Behaviours are the behaviours of the nodes on the network, by
+	// node index. The nodes without a behaviour are honest
+	Behaviours map[int]Behaviour
+}
+
+// Stats are the network statistics of the simulation
+type Stats struct {
+	// Sent is the number of messages sent, including the loopback ones
+	Sent uint64
+
+	// Dropped is the number of messages lost,
+	// either by the link or by a partition
+	Dropped uint64
+
+	// Duplicated is the number of messages delivered twice
+	Duplicated uint64
+}
+
+// Node is a simulated IBFT node
+type Node struct {
+	Index   int
+	ID      []byte
+	IBFT    *core.IBFT
+	Backend *Backend
+}
+
+// Simulation is the set of nodes connected by the simulated network.
+// The nodes run the consensus driver, one height after the other,
+// and sync the finalized blocks from their peers when they fall behind
+type Simulation struct {
+	config     Config
+	clock      *Clock
+	network    *network
+	validators messages.ValidatorSet
+	nodes      []*Node
+
+	// cancelFn stops the running nodes
+	cancelFn context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// New creates a new simulation of the configured nodes
+func New(config Config) *Simulation {
+	if config.TimeLimit <= 0 {
+		config.TimeLimit = DefaultTimeLimit
+	}
+
+	var (
+		ids     = make([][]byte, config.Nodes)
+		signers = make([]*signing.Ed25519Signer, config.Nodes)
+	)
+
+	for index := range ids {
+		signers[index], _ = signing.NewEd25519Signer(nodeKey(index))
+		ids[index] = signers[index].ID()
+	}
+
+	s := &Simulation{
+		config:     config,
+		clock:      newClock(),
+		validators: messages.NewEqualValidatorSet(ids),
+		nodes:      make([]*Node, config.Nodes),
+	}
+
+	s.network = &network{
+		seed:        config.Seed,
+		clock:       s.clock,
+		deliver:     s.deliver,
+		defaultLink: config.Link,
+		links:       make(map[link]LinkConfig),
+	}
+
+	for index, id := range ids {
+		index := index
+
+		node := &Node{
+			Index:   index,
+			ID:      id,
+			Backend: newBackend(index, signers[index], s.validators),
+		}
+
+		node.Backend.sync = func(height uint64) {
+			s.sync(index, height)
+		}
+
+		opts := []core.Option{
+			core.WithClock(nodeClock{Clock: s.clock, index: index}),
+			core.WithSigner(signers[index]),
+			core.WithSignatureVerifier(signing.Ed25519Verifier{}),
+		}
+
+		if config.RoundTimeout > 0 {
+			opts = append(opts, core.WithRoundTimeoutPolicy(
+				core.NewExponentialRoundTimeout(config.RoundTimeout, 0),
+			))
+		}
+
+		node.IBFT = core.NewIBFT(
+			nopLogger{},
+			trackedBackend{Backend: node.Backend, activity: s.clock.activity},
+			&transport{
+				activity:  s.clock.activity,
+				behaviour: config.Behaviours[index],
+				outbox:    &Outbox{network: s.network, node: node, nodes: config.Nodes},
+			},
+			append(opts, config.Options...)...,
+		)
+
+		s.nodes[index] = node
+	}
+
+	return s
+}
+
+// Nodes returns the simulated nodes
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+// Proposer returns the proposer for the view
+func (s *Simulation) Proposer(height, round uint64) *Node {
+	return s.nodes[Proposer(s.validators, height, round)]
+}
+
+// Validators returns the validator set of the nodes
+func (s *Simulation) Validators() messages.ValidatorSet {
+	return s.validators
+}
+
+// Now returns the current virtual time
+func (s *Simulation) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Elapsed returns the virtual time elapsed since the start of the simulation
+func (s *Simulation) Elapsed() time.Duration {
+	return s.clock.Now().Sub(epoch)
+}
+
+// Stats returns the network statistics
+func (s *Simulation) Stats() Stats {
+	s.network.lock.RLock()
+	defer s.network.lock.RUnlock()
+
+	return Stats{
+		Sent:       s.network.sent,
+		Dropped:    s.network.dropped,
+		Duplicated: s.network.duplicated,
+	}
+}
+
+// SetLink sets the configuration of the directed link between the nodes
+func (s *Simulation) SetLink(from, to int, config LinkConfig) {
+	s.network.lock.Lock()
+	defer s.network.lock.Unlock()
+
+	s.network.links[link{from, to}] = config
+}
+
+// Partition splits the network into the groups of nodes. Only the nodes in
+// the same group can communicate. Each node not listed is isolated
+func (s *Simulation) Partition(groups ...[]int) {
+	partition := make(map[int]int, len(s.nodes))
+
+	for index := range s.nodes {
+		// Isolated nodes get a group of their own
+		partition[index] = len(groups) + index
+	}
+
+	for group, nodes := range groups {
+		for _, index := range nodes {
+			partition[index] = group
+		}
+	}
+
+	s.network.lock.Lock()
+	defer s.network.lock.Unlock()
+
+	s.network.partition = partition
+}
+
+// Heal removes the network partition
+func (s *Simulation) Heal() {
+	s.network.lock.Lock()
+	defer s.network.lock.Unlock()
+
+	s.network.partition = nil
+}
+
+// Start starts the consensus drivers of the nodes. The
+// consensus only progresses while the simulation is run
+func (s *Simulation) Start() {
+	ctx, cancelFn := context.WithCancel(context.Background())
+	s.cancelFn = cancelFn
+
+	for _, node := range s.nodes {
+		s.wg.Add(1)
+
+		go func(node *Node) {
+			defer s.wg.Done()
+
+			_ = node.IBFT.Run(ctx, node.Backend)
+		}(node)
+	}
+}
+
+// Stop stops the consensus drivers of the nodes, and waits for them to return
+func (s *Simulation) Stop() {
+	if s.cancelFn == nil {
+		return
+	}
+
+	s.cancelFn()
+	s.wg.Wait()
+
+	s.cancelFn = nil
+}
+
+// RunFor runs the simulation for the virtual duration
+func (s *Simulation) RunFor(d time.Duration) {
+	s.run(s.clock.Now().Add(d), func() bool {
+		return false
+	})
+}
+
+// RunUntilHeight runs the simulation until all nodes have the blocks up
+// to the height. If the height is not reached within the time limit,
+// ErrTimeLimit is returned
+func (s *Simulation) RunUntilHeight(height uint64) error {
+	reached := s.run(s.clock.Now().Add(s.config.TimeLimit), func() bool {
+		for _, node := range s.nodes {
+			if node.Backend.LatestHeight() < height {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	if !reached {
+		return ErrTimeLimit
+	}
+
+	return nil
+}
+
+// CheckAgreement checks that the nodes finalized the same blocks
+func (s *Simulation) CheckAgreement() error {
+	finalized := make(map[int]Block)
+
+	for _, node := range s.nodes {
+		for index, block := range node.Backend.Chain() {
+			first, exists := finalized[index]
+			if !exists {
+				finalized[index] = block
+
+				continue
+			}
+
+			if !bytes.Equal(first.Proposal, block.Proposal) {
+				return fmt.Errorf(
+					"%w at height %d: %q and %q",
+					ErrConflictingBlocks,
+					index+1,
+					first.Proposal,
+					block.Proposal,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// run runs the events in order, until the condition is met, or the
+// next event is past the deadline. It returns whether the condition was met
+func (s *Simulation) run(deadline time.Time, condition func() bool) bool {
+	for {
+		s.settle(settleDelay)
+
+		if condition() {
+			return true
+		}
+
+		next, ok := s.clock.peek()
+		if ok && next.timer {
+			// Make sure the nodes are idle before jumping ahead to
+			// the timer, as it may be preempted by their messages
+			s.settle(timerSettleDelay)
+
+			if condition() {
+				return true
+			}
+		}
+
+		e, ok := s.clock.next(deadline)
+		if !ok {
+			s.clock.advance(deadline)
+
+			return false
+		}
+
+		e.fire()
+	}
+}
+
+// settle waits for the nodes to stop calling into the simulation for the
+// quiet (real time) period. It is a heuristic: a node busy for longer than
+// that without calling the clock, transport or backend is considered idle
+func (s *Simulation) settle(quiet time.Duration) {
+	for {
+		activity := s.clock.activity.load()
+
+		runtime.Gosched()
+		time.Sleep(quiet)
+
+		if s.clock.activity.load() == activity {
+			return
+		}
+	}
+}
+
+// sync fetches the blocks for the node from the connected peer with the
+// longest chain. The peers may not have inserted the blocks up to the height
+// yet, so the node syncs what is available. The blocks arrive after the link latency
+func (s *Simulation) sync(index int, _ uint64) {
+	var (
+		best   *Node
+		config LinkConfig
+	)
+
+	for _, peer := range s.nodes {
+		if peer.Index == index {
+			continue
+		}
+
+		peerConfig, connected := s.network.linkConfig(peer.Index, index)
+		if !connected {
+			continue
+		}
+
+		if best == nil || peer.Backend.LatestHeight() > best.Backend.LatestHeight() {
+			best, config = peer, peerConfig
+		}
+	}
+
+	if best == nil || best.Backend.LatestHeight() <= s.nodes[index].Backend.LatestHeight() {
+		return
+	}
+
+	blocks := best.Backend.Chain()
+
+	s.clock.schedule(config.Latency, uint64(index), func() {
+		s.nodes[index].Backend.appendBlocks(blocks)
+	})
+}
+
+// deliver hands the message to the node
+func (s *Simulation) deliver(to int, message *proto.Message) {
+	_ = s.nodes[to].IBFT.AddMessage(message)
+}
+
+// transport is the core.Transport of a node, over the simulated network
+type transport struct {
+	activity  *activity
+	behaviour Behaviour
+	outbox    *Outbox
+}
+
+func (t *transport) Multicast(message *proto.Message) {
+	t.activity.touch()
+
+	if t.behaviour == nil {
+		t.outbox.Multicast(message)
+
+		return
+	}
+
+	t.behaviour.Multicast(t.outbox, message)
+}
+
+// nopLogger is the core.Logger that discards the logs
+type nopLogger struct{}
+
+func (nopLogger) Info(_ string, _ ...interface{}) {}
+
+func (nopLogger) Debug(_ string, _ ...interface{}) {}
+
+func (nopLogger) Error(_ string, _ ...interface{}) {}
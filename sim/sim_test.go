@@ -0,0 +1,239 @@
+package sim
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// farFuture is the deadline past all the scheduled events
+var farFuture = epoch.Add(24 * time.Hour)
+
+// newSimulation creates and starts a new simulation,
+// which is stopped once the test is done
+func newSimulation(t *testing.T, config Config) *Simulation {
+	t.Helper()
+
+	s := New(config)
+	s.Start()
+
+	t.Cleanup(s.Stop)
+
+	return s
+}
+
+func TestSimulation_ReliableNetwork(t *testing.T) {
+	t.Parallel()
+
+	s := newSimulation(t, Config{
+		Nodes: 4,
+		Link: LinkConfig{
+			Latency: 50 * time.Millisecond,
+			Jitter:  20 * time.Millisecond,
+		},
+	})
+
+	require.NoError(t, s.RunUntilHeight(3))
+	require.NoError(t, s.CheckAgreement())
+
+	// Make sure the proposers took turns, and their blocks were finalized
+	for _, node := range s.Nodes() {
+		chain := node.Backend.Chain()
+		require.GreaterOrEqual(t, len(chain), 3)
+
+		for index, block := range chain[:3] {
+			height := uint64(index + 1)
+
+			assert.Equal(
+				t,
+				fmt.Sprintf("block %d proposed by node %d", height, s.Proposer(height, 0).Index),
+				string(block.Proposal),
+			)
+		}
+	}
+
+	// Make sure the time is virtual: three heights take a few message delays
+	assert.Less(t, s.Elapsed(), time.Second)
+	assert.Zero(t, s.Stats().Dropped)
+}
+
+func TestSimulation_Partition(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no quorum without healing", func(t *testing.T) {
+		t.Parallel()
+
+		s := New(Config{
+			Nodes:     4,
+			Link:      LinkConfig{Latency: 10 * time.Millisecond},
+			TimeLimit: time.Minute,
+		})
+
+		s.Partition([]int{0, 1}, []int{2, 3})
+		s.Start()
+
+		defer s.Stop()
+
+		assert.ErrorIs(t, s.RunUntilHeight(1), ErrTimeLimit)
+
+		for _, node := range s.Nodes() {
+			assert.Zero(t, node.Backend.LatestHeight())
+		}
+
+		assert.Equal(t, time.Minute, s.Elapsed())
+	})
+
+	t.Run("finalized after healing", func(t *testing.T) {
+		t.Parallel()
+
+		s := New(Config{
+			Nodes:        4,
+			Link:         LinkConfig{Latency: 10 * time.Millisecond},
+			RoundTimeout: time.Second,
+		})
+
+		// Split the network, so no quorum can be formed until healed
+		s.Partition([]int{0, 1}, []int{2, 3})
+		s.Start()
+
+		defer s.Stop()
+
+		s.RunFor(time.Minute)
+		s.Heal()
+
+		require.NoError(t, s.RunUntilHeight(2))
+		assert.NoError(t, s.CheckAgreement())
+	})
+
+	t.Run("isolated node catches up", func(t *testing.T) {
+		t.Parallel()
+
+		s := New(Config{
+			Nodes: 4,
+			Link:  LinkConfig{Latency: 10 * time.Millisecond},
+		})
+
+		// The rest of the network has a quorum, and moves on without the node
+		s.Partition([]int{1, 2, 3})
+		s.Start()
+
+		defer s.Stop()
+
+		s.RunFor(time.Minute)
+		assert.Zero(t, s.Nodes()[0].Backend.LatestHeight())
+
+		s.Heal()
+
+		require.NoError(t, s.RunUntilHeight(s.Nodes()[1].Backend.LatestHeight()+1))
+		assert.NoError(t, s.CheckAgreement())
+	})
+}
+
+func TestSimulation_UnreliableNetwork(t *testing.T) {
+	t.Parallel()
+
+	for _, seed := range []int64{1, 2, 3} {
+		seed := seed
+
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			t.Parallel()
+
+			s := newSimulation(t, Config{
+				Nodes: 7,
+				Seed:  seed,
+				Link: LinkConfig{
+					Latency:       20 * time.Millisecond,
+					Jitter:        80 * time.Millisecond,
+					DropRate:      0.05,
+					DuplicateRate: 0.1,
+					ReorderRate:   0.2,
+					ReorderDelay:  time.Second,
+				},
+			})
+
+			require.NoError(t, s.RunUntilHeight(3))
+			assert.NoError(t, s.CheckAgreement())
+
+			stats := s.Stats()
+			assert.NotZero(t, stats.Dropped)
+			assert.NotZero(t, stats.Duplicated)
+		})
+	}
+}
+
+// TestNetwork_Reproducible makes sure the network
+// decisions for the same messages are reproducible
+func TestNetwork_Reproducible(t *testing.T) {
+	t.Parallel()
+
+	// deliveries returns the delivery times of the
+	// messages sent over the network with the seed
+	deliveries := func(seed int64) []time.Duration {
+		var (
+			clock     = newClock()
+			delivered = make([]time.Duration, 0)
+		)
+
+		n := &network{
+			seed:  seed,
+			clock: clock,
+			deliver: func(_ int, _ *proto.Message) {
+				delivered = append(delivered, clock.Now().Sub(epoch))
+			},
+			defaultLink: LinkConfig{
+				Latency:       10 * time.Millisecond,
+				Jitter:        100 * time.Millisecond,
+				DropRate:      0.2,
+				DuplicateRate: 0.2,
+			},
+		}
+
+		for round := uint64(0); round < 50; round++ {
+			n.send(0, 1, &proto.Message{
+				View: &proto.View{Height: 1, Round: round},
+				Type: proto.MessageType_ROUND_CHANGE,
+			})
+		}
+
+		for e, ok := clock.next(farFuture); ok; e, ok = clock.next(farFuture) {
+			e.fire()
+		}
+
+		return delivered
+	}
+
+	// Make sure the same seed yields the same drops, duplicates and delays
+	assert.Equal(t, deliveries(1), deliveries(1))
+	assert.NotEqual(t, deliveries(1), deliveries(2))
+}
+
+func TestClock_Timers(t *testing.T) {
+	t.Parallel()
+
+	clock := newClock()
+
+	var (
+		stopped = clock.NewTimer(time.Second)
+		fired   = clock.NewTimer(2 * time.Second)
+		instant = clock.NewTimer(0)
+	)
+
+	// Make sure the timers without a duration fire instantly
+	assert.Equal(t, epoch, <-instant.C())
+	assert.False(t, instant.Stop())
+
+	assert.True(t, stopped.Stop())
+
+	for e, ok := clock.next(farFuture); ok; e, ok = clock.next(farFuture) {
+		e.fire()
+	}
+
+	// Make sure only the running timer fires, at its virtual time
+	assert.Equal(t, epoch.Add(2*time.Second), <-fired.C())
+	assert.Empty(t, stopped.C())
+	assert.False(t, fired.Stop())
+}
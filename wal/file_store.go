@@ -0,0 +1,355 @@
+// Package wal implements a file-backed write-ahead log
+// for persisting the IBFT consensus state
+package wal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nubank/go-ibft/core"
+	"github.com/nubank/go-ibft/messages/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+const (
+	// headerSize is the size of the record header (length + checksum)
+	headerSize = 8
+
+	// filePermissions are the permissions of the log file
+	filePermissions = 0o600
+)
+
+var (
+	errCorruptedRecord = errors.New("corrupted record")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// FileStore is a core.StateStore that appends state snapshots to a log file,
+// syncing each write to disk before returning.
+//
+// Each record is framed as [length (4B)][crc32c (4B)][payload].
+// A record that was only partially written (a crash in the middle of a write)
+// is detected on open, and the log is truncated back to the last intact record.
+// Once a snapshot for a new height is saved, the log is compacted,
+// as the state of previous heights is no longer needed
+type FileStore struct {
+	mux sync.Mutex
+
+	// path is the location of the log file
+	path string
+
+	// file is the open log file
+	file *os.File
+
+	// latest is the most recently persisted snapshot
+	latest *core.StateSnapshot
+}
+
+// NewFileStore opens (or creates) the log file at the specified path,
+// recovering the latest intact snapshot from it
+func NewFileStore(path string) (*FileStore, error) {
+	path = filepath.Clean(path)
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, filePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open log file: %w", err)
+	}
+
+	s := &FileStore{
+		path: path,
+		file: file,
+	}
+
+	if err := s.recover(); err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Save appends the snapshot to the log, and syncs it to disk
+func (s *FileStore) Save(snapshot *core.StateSnapshot) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	record, err := encodeRecord(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if s.latest != nil && s.latest.View.Height != snapshot.View.Height {
+		// The sequence moved on, older records are obsolete
+		if err := s.compact(record); err != nil {
+			return err
+		}
+	} else if err := s.append(record); err != nil {
+		return err
+	}
+
+	s.latest = snapshot
+
+	return nil
+}
+
+// Load returns the latest persisted snapshot
+func (s *FileStore) Load() (*core.StateSnapshot, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.latest == nil {
+		return nil, core.ErrSnapshotNotFound
+	}
+
+	return s.latest, nil
+}
+
+// Close closes the underlying log file
+func (s *FileStore) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.file.Close()
+}
+
+// append writes the record to the end of the log
+func (s *FileStore) append(record []byte) error {
+	if _, err := s.file.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("unable to seek log file: %w", err)
+	}
+
+	if _, err := s.file.Write(record); err != nil {
+		return fmt.Errorf("unable to write record: %w", err)
+	}
+
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("unable to sync log file: %w", err)
+	}
+
+	return nil
+}
+
+// compact atomically replaces the log with one
+// that only contains the specified record
+func (s *FileStore) compact(record []byte) error {
+	tmpPath := s.path + ".tmp"
+
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, filePermissions)
+	if err != nil {
+		return fmt.Errorf("unable to create log file: %w", err)
+	}
+
+	if _, err := tmp.Write(record); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("unable to write record: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("unable to sync log file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("unable to replace log file: %w", err)
+	}
+
+	// Make sure the rename itself is durable
+	if err := syncDir(filepath.Dir(s.path)); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	_ = s.file.Close()
+	s.file = tmp
+
+	return nil
+}
+
+// recover reads the log, keeping the latest intact snapshot,
+// and truncates any partially written records at the end
+func (s *FileStore) recover() error {
+	data, err := io.ReadAll(s.file)
+	if err != nil {
+		return fmt.Errorf("unable to read log file: %w", err)
+	}
+
+	var (
+		offset int
+		reader = bytes.NewReader(data)
+	)
+
+	for reader.Len() > 0 {
+		snapshot, err := decodeRecord(reader)
+		if err != nil {
+			// Everything from this point on is
+			// the result of an interrupted write
+			break
+		}
+
+		s.latest = snapshot
+		offset = len(data) - reader.Len()
+	}
+
+	if offset == len(data) {
+		return nil
+	}
+
+	if err := s.file.Truncate(int64(offset)); err != nil {
+		return fmt.Errorf("unable to truncate log file: %w", err)
+	}
+
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("unable to sync log file: %w", err)
+	}
+
+	return nil
+}
+
+// encodeRecord encodes the snapshot into a log record
+func encodeRecord(snapshot *core.StateSnapshot) ([]byte, error) {
+	var payload []byte
+
+	for _, message := range []protobuf.Message{
+		snapshot.View,
+		snapshot.LatestPC,
+		snapshot.ProposalMessage,
+	} {
+		raw, err := protobuf.Marshal(message)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode snapshot: %w", err)
+		}
+
+		payload = appendField(payload, raw)
+	}
+
+	payload = appendField(payload, snapshot.LatestPreparedProposedBlock)
+
+	record := make([]byte, headerSize, headerSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(payload, crcTable))
+
+	return append(record, payload...), nil
+}
+
+// decodeRecord decodes the next log record from the reader
+func decodeRecord(reader *bytes.Reader) (*core.StateSnapshot, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, errCorruptedRecord
+	}
+
+	var (
+		length   = binary.BigEndian.Uint32(header[0:4])
+		checksum = binary.BigEndian.Uint32(header[4:8])
+	)
+
+	if uint64(length) > uint64(reader.Len()) {
+		return nil, errCorruptedRecord
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, errCorruptedRecord
+	}
+
+	if crc32.Checksum(payload, crcTable) != checksum {
+		return nil, errCorruptedRecord
+	}
+
+	fields := make([][]byte, 0, 4)
+
+	for len(payload) > 0 {
+		field, rest, err := readField(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+		payload = rest
+	}
+
+	if len(fields) != 4 {
+		return nil, errCorruptedRecord
+	}
+
+	snapshot := &core.StateSnapshot{
+		View: &proto.View{},
+	}
+
+	if len(fields[3]) > 0 {
+		snapshot.LatestPreparedProposedBlock = fields[3]
+	}
+
+	if err := protobuf.Unmarshal(fields[0], snapshot.View); err != nil {
+		return nil, errCorruptedRecord
+	}
+
+	if len(fields[1]) > 0 {
+		snapshot.LatestPC = &proto.PreparedCertificate{}
+		if err := protobuf.Unmarshal(fields[1], snapshot.LatestPC); err != nil {
+			return nil, errCorruptedRecord
+		}
+	}
+
+	if len(fields[2]) > 0 {
+		snapshot.ProposalMessage = &proto.Message{}
+		if err := protobuf.Unmarshal(fields[2], snapshot.ProposalMessage); err != nil {
+			return nil, errCorruptedRecord
+		}
+	}
+
+	return snapshot, nil
+}
+
+// appendField appends the length prefixed field to the payload
+func appendField(payload, field []byte) []byte {
+	var length [binary.MaxVarintLen64]byte
+
+	n := binary.PutUvarint(length[:], uint64(len(field)))
+
+	return append(append(payload, length[:n]...), field...)
+}
+
+// readField reads a length prefixed field from the payload
+func readField(payload []byte) ([]byte, []byte, error) {
+	length, n := binary.Uvarint(payload)
+	if n <= 0 || length > uint64(len(payload)-n) {
+		return nil, nil, errCorruptedRecord
+	}
+
+	end := n + int(length)
+
+	return payload[n:end], payload[end:], nil
+}
+
+// syncDir syncs the directory entries to disk
+func syncDir(dir string) error {
+	d, err := os.Open(filepath.Clean(dir))
+	if err != nil {
+		return fmt.Errorf("unable to open log directory: %w", err)
+	}
+
+	defer func() {
+		_ = d.Close()
+	}()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("unable to sync log directory: %w", err)
+	}
+
+	return nil
+}
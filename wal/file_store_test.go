@@ -0,0 +1,274 @@
+package wal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nubank/go-ibft/core"
+	"github.com/nubank/go-ibft/messages/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// buildSnapshot builds a snapshot with all fields set
+func buildSnapshot(height, round uint64) *core.StateSnapshot {
+	proposalMessage := &proto.Message{
+		View: &proto.View{
+			Height: height,
+			Round:  round,
+		},
+		From: []byte("proposer"),
+		Type: proto.MessageType_PREPREPARE,
+		Payload: &proto.Message_PreprepareData{
+			PreprepareData: &proto.PrePrepareMessage{
+				Proposal:     []byte("proposal"),
+				ProposalHash: []byte("proposal hash"),
+			},
+		},
+	}
+
+	return &core.StateSnapshot{
+		View: &proto.View{
+			Height: height,
+			Round:  round,
+		},
+		LatestPC: &proto.PreparedCertificate{
+			ProposalMessage: proposalMessage,
+			PrepareMessages: []*proto.Message{
+				{
+					View: &proto.View{
+						Height: height,
+						Round:  round,
+					},
+					From: []byte("validator"),
+					Type: proto.MessageType_PREPARE,
+					Payload: &proto.Message_PrepareData{
+						PrepareData: &proto.PrepareMessage{
+							ProposalHash: []byte("proposal hash"),
+						},
+					},
+				},
+			},
+		},
+		LatestPreparedProposedBlock: []byte("proposal"),
+		ProposalMessage:             proposalMessage,
+	}
+}
+
+// assertSnapshotsEqual makes sure the snapshots have the same contents
+func assertSnapshotsEqual(t *testing.T, expected, actual *core.StateSnapshot) {
+	t.Helper()
+
+	assert.True(t, protobuf.Equal(expected.View, actual.View))
+	assert.True(t, protobuf.Equal(expected.LatestPC, actual.LatestPC))
+	assert.True(t, protobuf.Equal(expected.ProposalMessage, actual.ProposalMessage))
+	assert.Equal(t, expected.LatestPreparedProposedBlock, actual.LatestPreparedProposedBlock)
+}
+
+func TestFileStore_Load(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty log", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := NewFileStore(filepath.Join(t.TempDir(), "wal"))
+		require.NoError(t, err)
+
+		defer store.Close()
+
+		snapshot, err := store.Load()
+
+		assert.Nil(t, snapshot)
+		assert.True(t, errors.Is(err, core.ErrSnapshotNotFound))
+	})
+
+	t.Run("latest snapshot is returned", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := NewFileStore(filepath.Join(t.TempDir(), "wal"))
+		require.NoError(t, err)
+
+		defer store.Close()
+
+		require.NoError(t, store.Save(buildSnapshot(1, 0)))
+		require.NoError(t, store.Save(buildSnapshot(1, 1)))
+
+		snapshot, err := store.Load()
+		require.NoError(t, err)
+
+		assertSnapshotsEqual(t, buildSnapshot(1, 1), snapshot)
+	})
+
+	t.Run("partial snapshot is restored", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "wal")
+
+		store, err := NewFileStore(path)
+		require.NoError(t, err)
+
+		expected := &core.StateSnapshot{
+			View: &proto.View{
+				Height: 5,
+				Round:  2,
+			},
+		}
+
+		require.NoError(t, store.Save(expected))
+		require.NoError(t, store.Close())
+
+		store, err = NewFileStore(path)
+		require.NoError(t, err)
+
+		defer store.Close()
+
+		snapshot, err := store.Load()
+		require.NoError(t, err)
+
+		assertSnapshotsEqual(t, expected, snapshot)
+		assert.Nil(t, snapshot.LatestPC)
+		assert.Nil(t, snapshot.ProposalMessage)
+		assert.Nil(t, snapshot.LatestPreparedProposedBlock)
+	})
+}
+
+func TestFileStore_Recovery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("snapshot survives a restart", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "wal")
+
+		store, err := NewFileStore(path)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Save(buildSnapshot(3, 0)))
+		require.NoError(t, store.Save(buildSnapshot(3, 4)))
+		require.NoError(t, store.Close())
+
+		store, err = NewFileStore(path)
+		require.NoError(t, err)
+
+		defer store.Close()
+
+		snapshot, err := store.Load()
+		require.NoError(t, err)
+
+		assertSnapshotsEqual(t, buildSnapshot(3, 4), snapshot)
+	})
+
+	testTable := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+	}{
+		{
+			"torn record header",
+			func(data []byte) []byte {
+				return append(data, 0, 0, 1)
+			},
+		},
+		{
+			"torn record payload",
+			func(data []byte) []byte {
+				record, _ := encodeRecord(buildSnapshot(3, 2))
+
+				return append(data, record[:len(record)-5]...)
+			},
+		},
+		{
+			"invalid record checksum",
+			func(data []byte) []byte {
+				record, _ := encodeRecord(buildSnapshot(3, 2))
+				record[len(record)-1] ^= 0xff
+
+				return append(data, record...)
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "wal")
+
+			store, err := NewFileStore(path)
+			require.NoError(t, err)
+
+			require.NoError(t, store.Save(buildSnapshot(3, 1)))
+			require.NoError(t, store.Close())
+
+			intact, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			// Simulate a crash in the middle of a write
+			require.NoError(t, os.WriteFile(path, testCase.corrupt(intact), 0o600))
+
+			store, err = NewFileStore(path)
+			require.NoError(t, err)
+
+			defer store.Close()
+
+			// Make sure the last intact snapshot is loaded
+			snapshot, err := store.Load()
+			require.NoError(t, err)
+
+			assertSnapshotsEqual(t, buildSnapshot(3, 1), snapshot)
+
+			// Make sure the torn record is truncated
+			recovered, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			assert.Equal(t, intact, recovered)
+
+			// Make sure the log is still usable
+			require.NoError(t, store.Save(buildSnapshot(3, 2)))
+
+			snapshot, err = store.Load()
+			require.NoError(t, err)
+
+			assertSnapshotsEqual(t, buildSnapshot(3, 2), snapshot)
+		})
+	}
+}
+
+func TestFileStore_Compaction(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "wal")
+
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	for round := uint64(0); round < 5; round++ {
+		require.NoError(t, store.Save(buildSnapshot(1, round)))
+	}
+
+	require.NoError(t, store.Save(buildSnapshot(2, 0)))
+	require.NoError(t, store.Close())
+
+	// Make sure only the latest height record is kept
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	record, err := encodeRecord(buildSnapshot(2, 0))
+	require.NoError(t, err)
+
+	assert.Equal(t, record, data)
+
+	store, err = NewFileStore(path)
+	require.NoError(t, err)
+
+	defer store.Close()
+
+	snapshot, err := store.Load()
+	require.NoError(t, err)
+
+	assertSnapshotsEqual(t, buildSnapshot(2, 0), snapshot)
+}